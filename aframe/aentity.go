@@ -34,7 +34,9 @@ const (
 
 	PROPERTY__point = "point"
 
-	function__destroy = "destroy"
+	function__destroy          = "destroy"
+	function__getWorldPosition  = "getWorldPosition"
+	function__getWorldDirection = "getWorldDirection"
 )
 
 type AEntity struct {
@@ -61,6 +63,49 @@ func (e *AEntity) SetPosition(_x, _y, _z float64) error {
 	return nil
 }
 
+// GetPosition returns the position relative to the parent entity.
+func (e *AEntity) GetPosition() (float64, float64, float64, error) {
+	position, err := e.Element.GetProperty(PROPERTY__object3D, PROPERTY__position)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("[AEntity] %s [GetPosition] [error]: %v", e.Element, err)
+	}
+	return position.Get(PROPERTY__x).Float(), position.Get(PROPERTY__y).Float(), position.Get(PROPERTY__z).Float(), nil
+}
+
+// GetWorldPosition returns the position in scene coordinates, following every
+// parent transform.
+func (e *AEntity) GetWorldPosition() (float64, float64, float64, error) {
+	obj, err := e.Element.GetProperty(PROPERTY__object3D)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("[AEntity] %s [GetWorldPosition] [error]: %v", e.Element, err)
+	}
+	if e.scene == nil || e.scene.Three == nil {
+		return 0, 0, 0, fmt.Errorf("[AEntity] %s [GetWorldPosition] [error]: scene ref nil", e.Element)
+	}
+	if err = web.ValidJSValue(THREE__Vector3, e.scene.Three.Vector3); err != nil {
+		return 0, 0, 0, fmt.Errorf("[AEntity] %s [GetWorldPosition] [error]: %v", e.Element, err)
+	}
+	v := obj.Call(function__getWorldPosition, e.scene.Three.Vector3.New())
+	return v.Get(PROPERTY__x).Float(), v.Get(PROPERTY__y).Float(), v.Get(PROPERTY__z).Float(), nil
+}
+
+// GetWorldDirection returns the entity's +z axis in scene coordinates,
+// following every parent transform; a camera entity looks the opposite way.
+func (e *AEntity) GetWorldDirection() (float64, float64, float64, error) {
+	obj, err := e.Element.GetProperty(PROPERTY__object3D)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("[AEntity] %s [GetWorldDirection] [error]: %v", e.Element, err)
+	}
+	if e.scene == nil || e.scene.Three == nil {
+		return 0, 0, 0, fmt.Errorf("[AEntity] %s [GetWorldDirection] [error]: scene ref nil", e.Element)
+	}
+	if err = web.ValidJSValue(THREE__Vector3, e.scene.Three.Vector3); err != nil {
+		return 0, 0, 0, fmt.Errorf("[AEntity] %s [GetWorldDirection] [error]: %v", e.Element, err)
+	}
+	v := obj.Call(function__getWorldDirection, e.scene.Three.Vector3.New())
+	return v.Get(PROPERTY__x).Float(), v.Get(PROPERTY__y).Float(), v.Get(PROPERTY__z).Float(), nil
+}
+
 func (e *AEntity) SetRotation(_x, _y, _z float64) error {
 	rotation, err := e.Element.GetProperty(PROPERTY__object3D, PROPERTY__rotation)
 	if err != nil {
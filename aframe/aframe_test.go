@@ -0,0 +1,95 @@
+//+build tinygo wasm,js
+
+package aframe_test
+
+import (
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/aframe"
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+func newSkybox() *aframe.Skybox {
+	return &aframe.Skybox{
+		Name: "space",
+		Images: map[string]string{
+			aframe.Skybox__front:  "front.png",
+			aframe.Skybox__back:   "back.png",
+			aframe.Skybox__left:   "left.png",
+			aframe.Skybox__right:  "right.png",
+			aframe.Skybox__top:    "top.png",
+			aframe.Skybox__bottom: "bottom.png",
+		},
+		Length: 100,
+		Height: 50,
+		Depth:  25,
+	}
+}
+
+func TestSetSkybox(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	scene := h.AddElement("a-scene", "scene")
+	af := aframe.NewAframe(web.NewWindow())
+
+	if err := af.SetSkybox(newSkybox()); err != nil {
+		t.Fatal(err)
+	}
+
+	loads := h.CallsTo(webtest.TargetThree, "load")
+	want := []string{"front.png", "back.png", "top.png", "bottom.png", "right.png", "left.png"}
+	if len(loads) != len(want) {
+		t.Fatalf("TextureLoader.load called %d times, want %d", len(loads), len(want))
+	}
+	for i, c := range loads {
+		if got := c.Arg(0).String(); got != want[i] {
+			t.Errorf("face %d loaded %q, want %q", i, got, want[i])
+		}
+	}
+
+	geo := h.CallsTo(webtest.TargetThree, "BoxGeometry")
+	if len(geo) != 1 {
+		t.Fatalf("BoxGeometry called %d times, want 1", len(geo))
+	}
+	if got := geo[0].String(); got != "THREE.BoxGeometry(100, 50, 25)" {
+		t.Errorf("got %s", got)
+	}
+
+	mesh := h.CallsTo(webtest.TargetThree, "Mesh")
+	if len(mesh) != 1 {
+		t.Fatalf("Mesh called %d times, want 1", len(mesh))
+	}
+	materials := mesh[0].Arg(1)
+	if materials.Length() != 6 {
+		t.Fatalf("Mesh got %d materials, want 6", materials.Length())
+	}
+	for i := 0; i < 6; i++ {
+		if side := materials.Index(i).Get("side").Int(); side != 1 {
+			t.Errorf("material %d side = %d, want THREE.BackSide", i, side)
+		}
+	}
+
+	added := scene.Get("object3D").Get("children")
+	if added.Length() != 1 {
+		t.Fatalf("scene.object3D has %d children, want the skybox mesh", added.Length())
+	}
+}
+
+func TestSetSkyboxMissingFace(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	h.AddElement("a-scene", "scene")
+	af := aframe.NewAframe(web.NewWindow())
+
+	sky := newSkybox()
+	delete(sky.Images, aframe.Skybox__top)
+	if err := af.SetSkybox(sky); err == nil {
+		t.Error("SetSkybox with 5 images: want an error")
+	}
+	if n := len(h.CallsTo(webtest.TargetThree, "Mesh")); n != 0 {
+		t.Errorf("Mesh called %d times, want 0", n)
+	}
+}
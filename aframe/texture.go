@@ -0,0 +1,100 @@
+//+build tinygo wasm,js
+
+package aframe
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+const (
+	mesh = "mesh"
+
+	property__map         = "map"
+	property__needsUpdate = "needsUpdate"
+
+	function__getObject3D = "getObject3D"
+)
+
+// NewVideoTexture returns a THREE.VideoTexture following the frames of _video,
+// e.g. a camera preview from web.GetUserMedia. The video must be playing for
+// the texture to update; call its dispose() once it is not used.
+func (af *Aframe) NewVideoTexture(_video *web.MediaElement) (js.Value, error) {
+	tv := js.ValueOf(nil)
+	err := web.ValidJSValue(THREE__VideoTexture, af.Three.VideoTexture)
+	if err != nil {
+		return tv, fmt.Errorf("[aframe] [NewVideoTexture] [error]: %v", err)
+	}
+	if err = web.ValidJSValue(_video.String(), _video.Value); err != nil {
+		return tv, fmt.Errorf("[aframe] [NewVideoTexture] [error]: %v", err)
+	}
+	tv = af.Three.VideoTexture.New(_video.Value)
+	if err = web.ValidJSValue(texture, tv); err != nil {
+		return tv, fmt.Errorf("[aframe] [NewVideoTexture] [error]: %v", err)
+	}
+	return tv, nil
+}
+
+// NewCanvasTexture returns a THREE.CanvasTexture of _canvas, e.g. a label or
+// minimap drawn with web.Context2D. Call RefreshTexture after every redraw.
+func (af *Aframe) NewCanvasTexture(_canvas *web.Canvas) (js.Value, error) {
+	tv := js.ValueOf(nil)
+	err := web.ValidJSValue(THREE__CanvasTexture, af.Three.CanvasTexture)
+	if err != nil {
+		return tv, fmt.Errorf("[aframe] [NewCanvasTexture] [error]: %v", err)
+	}
+	if err = web.ValidJSValue(_canvas.String(), _canvas.Value); err != nil {
+		return tv, fmt.Errorf("[aframe] [NewCanvasTexture] [error]: %v", err)
+	}
+	tv = af.Three.CanvasTexture.New(_canvas.Value)
+	if err = web.ValidJSValue(texture, tv); err != nil {
+		return tv, fmt.Errorf("[aframe] [NewCanvasTexture] [error]: %v", err)
+	}
+	return tv, nil
+}
+
+// RefreshTexture uploads the new content of a canvas texture on the next
+// render.
+func RefreshTexture(_texture js.Value) {
+	if web.ValidJSValue(texture, _texture) == nil {
+		_texture.Set(property__needsUpdate, true)
+	}
+}
+
+// SetTexture replaces the map of the entity's mesh material with _texture, a
+// THREE texture such as one from NewVideoTexture. The entity needs geometry
+// and a material, and must be loaded.
+func (e *AEntity) SetTexture(_texture js.Value) error {
+	if err := web.ValidJSValue(texture, _texture); err != nil {
+		return fmt.Errorf("[AEntity] %s [SetTexture] [error]: %v", e.Element, err)
+	}
+	if err := web.ValidJSValue(e.Element.String(), e.Element.Value); err != nil {
+		return fmt.Errorf("[AEntity] %s [SetTexture] [error]: %v", e.Element, err)
+	}
+	if e.Element.Value.Get(function__getObject3D).Type() != js.TypeFunction {
+		return fmt.Errorf("[AEntity] %s [SetTexture] [error]: not an entity", e.Element)
+	}
+	m := e.Element.Value.Call(function__getObject3D, mesh)
+	if err := web.ValidJSValue(mesh, m); err != nil {
+		return fmt.Errorf("[AEntity] %s [SetTexture] [error]: %v", e.Element, err)
+	}
+	mat := m.Get(material)
+	if err := web.ValidJSValue(material, mat); err != nil {
+		return fmt.Errorf("[AEntity] %s [SetTexture] [error]: %v", e.Element, err)
+	}
+
+	mats := []js.Value{mat}
+	if js.Global().Get("Array").Call("isArray", mat).Bool() {
+		mats = mats[:0]
+		for i := 0; i < mat.Length(); i++ {
+			mats = append(mats, mat.Index(i))
+		}
+	}
+	for _, m := range mats {
+		m.Set(property__map, _texture)
+		m.Set(property__needsUpdate, true)
+	}
+	return nil
+}
@@ -16,6 +16,8 @@ const (
 	THREE__CircleGeometry       = "CircleGeometry"
 	THREE__RingGeometry         = "RingGeometry"
 	THREE__TextureLoader        = "TextureLoader"
+	THREE__VideoTexture         = "VideoTexture"
+	THREE__CanvasTexture        = "CanvasTexture"
 	THREE__Mesh                 = "Mesh"
 	THREE__MeshBasicMaterial    = "MeshBasicMaterial"
 	THREE__MeshStandardMAterial = "MeshStandardMaterial"
@@ -35,6 +37,8 @@ type Three struct {
 	BackSide             js.Value
 	BoxGeometry          js.Value
 	TextureLoader        js.Value
+	VideoTexture         js.Value
+	CanvasTexture        js.Value
 	Mesh                 js.Value
 	MeshBasicMaterial    js.Value
 	MeshStandardMaterial js.Value
@@ -51,6 +55,8 @@ func NewThree(_v js.Value) *Three {
 		BackSide:             _v.Get(THREE__BackSide),
 		BoxGeometry:          _v.Get(THREE__BoxGeometry),
 		TextureLoader:        _v.Get(THREE__TextureLoader),
+		VideoTexture:         _v.Get(THREE__VideoTexture),
+		CanvasTexture:        _v.Get(THREE__CanvasTexture),
 		Mesh:                 _v.Get(THREE__Mesh),
 		MeshBasicMaterial:    _v.Get(THREE__MeshBasicMaterial),
 		MeshStandardMaterial: _v.Get(THREE__MeshStandardMAterial),
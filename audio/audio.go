@@ -0,0 +1,188 @@
+//+build tinygo wasm,js
+
+// Package audio wraps the Web Audio API: an AudioContext, typed nodes wired
+// into graphs with Connect, buffers decoded from Go bytes, and panners that
+// follow aframe entities.
+//
+// Times passed to Start, Stop and the Param automation are AudioContext
+// seconds, as returned by Context.CurrentTime.
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+const (
+	audioContext__constructor       = "AudioContext"
+	audioContext__webkitConstructor = "webkitAudioContext"
+
+	context__state       = "state"
+	context__currentTime = "currentTime"
+	context__sampleRate  = "sampleRate"
+	context__destination = "destination"
+	context__listener    = "listener"
+
+	function__resume          = "resume"
+	function__suspend         = "suspend"
+	function__close           = "close"
+	function__decodeAudioData = "decodeAudioData"
+	function__getChannelData  = "getChannelData"
+
+	buffer__duration         = "duration"
+	buffer__length           = "length"
+	buffer__sampleRate       = "sampleRate"
+	buffer__numberOfChannels = "numberOfChannels"
+
+	STATE__suspended = "suspended"
+	STATE__running   = "running"
+	STATE__closed    = "closed"
+
+	uint8Array__name = "Uint8Array"
+	typed__buffer    = "buffer"
+	typed__offset    = "byteOffset"
+	typed__length    = "length"
+)
+
+// Context is an AudioContext, the clock and factory of every node. Browsers
+// create it suspended until a user gesture; call Resume from one.
+type Context struct {
+	Value js.Value
+}
+
+// NewContext returns an error wrapping web.GOWEB_ERROR_UNSUPPORTED when the
+// browser has no Web Audio.
+func NewContext() (*Context, error) {
+	ctor := js.Global().Get(audioContext__constructor)
+	if ctor.Type() != js.TypeFunction {
+		ctor = js.Global().Get(audioContext__webkitConstructor)
+	}
+	if ctor.Type() != js.TypeFunction {
+		return nil, fmt.Errorf("[audio] [NewContext] [error]: %w", web.GOWEB_ERROR_UNSUPPORTED)
+	}
+	v := ctor.New()
+	if err := web.ValidJSValue(audioContext__constructor, v); err != nil {
+		return nil, fmt.Errorf("[audio] [NewContext] [error]: %v", err)
+	}
+	return &Context{Value: v}, nil
+}
+
+// State is one of the STATE__ constants.
+func (c *Context) State() string {
+	return c.Value.Get(context__state).String()
+}
+
+// CurrentTime is the audio clock in seconds.
+func (c *Context) CurrentTime() float64 {
+	return c.Value.Get(context__currentTime).Float()
+}
+
+// SampleRate ...
+func (c *Context) SampleRate() float64 {
+	return c.Value.Get(context__sampleRate).Float()
+}
+
+// Destination is the speakers; a graph has to end there to be heard.
+func (c *Context) Destination() *Node {
+	return newNode(c.Value.Get(context__destination))
+}
+
+// Listener is the ear the Panners are heard from.
+func (c *Context) Listener() *Listener {
+	return &Listener{Value: c.Value.Get(context__listener), ctx: c}
+}
+
+// Resume starts a suspended context.
+func (c *Context) Resume(_ctx context.Context) error {
+	if _, err := web.Await(_ctx, c.Value.Call(function__resume)); err != nil {
+		return fmt.Errorf("[audio] [Resume] [error]: %v", err)
+	}
+	return nil
+}
+
+// Suspend pauses the audio clock and releases the audio hardware.
+func (c *Context) Suspend(_ctx context.Context) error {
+	if _, err := web.Await(_ctx, c.Value.Call(function__suspend)); err != nil {
+		return fmt.Errorf("[audio] [Suspend] [error]: %v", err)
+	}
+	return nil
+}
+
+// Close releases the context for good.
+func (c *Context) Close(_ctx context.Context) error {
+	if _, err := web.Await(_ctx, c.Value.Call(function__close)); err != nil {
+		return fmt.Errorf("[audio] [Close] [error]: %v", err)
+	}
+	return nil
+}
+
+// Decode decodes an encoded file (mp3, ogg, wav, ...) into a Buffer.
+func (c *Context) Decode(_ctx context.Context, _b []byte) (*Buffer, error) {
+	if len(_b) == 0 {
+		return nil, fmt.Errorf("[audio] [Decode] [error]: empty data")
+	}
+	// decodeAudioData detaches the buffer, so it gets its own copy
+	v, err := web.Await(_ctx, c.Value.Call(function__decodeAudioData, web.NewArrayBuffer(_b)))
+	if err != nil {
+		return nil, fmt.Errorf("[audio] [Decode] [error]: %v", err)
+	}
+	return &Buffer{Value: v}, nil
+}
+
+// Buffer is decoded PCM audio, playable by any number of BufferSources.
+type Buffer struct {
+	Value js.Value
+}
+
+// Duration in seconds.
+func (b *Buffer) Duration() float64 {
+	return b.Value.Get(buffer__duration).Float()
+}
+
+// Length in sample frames.
+func (b *Buffer) Length() int {
+	return b.Value.Get(buffer__length).Int()
+}
+
+// SampleRate ...
+func (b *Buffer) SampleRate() float64 {
+	return b.Value.Get(buffer__sampleRate).Float()
+}
+
+// Channels ...
+func (b *Buffer) Channels() int {
+	return b.Value.Get(buffer__numberOfChannels).Int()
+}
+
+// ChannelData copies the samples of channel _ch.
+func (b *Buffer) ChannelData(_ch int) []float32 {
+	if _ch < 0 || _ch >= b.Channels() {
+		return nil
+	}
+	arr := b.Value.Call(function__getChannelData, _ch)
+	r := make([]float32, arr.Get(typed__length).Int())
+	copyFloat32s(r, arr)
+	return r
+}
+
+// copyFloat32s copies a Float32Array into _dst and returns the count copied.
+func copyFloat32s(_dst []float32, _arr js.Value) int {
+	n := _arr.Get(typed__length).Int()
+	if n > len(_dst) {
+		n = len(_dst)
+	}
+	if n == 0 {
+		return 0
+	}
+	raw := make([]byte, n*4)
+	js.CopyBytesToGo(raw, js.Global().Get(uint8Array__name).New(_arr.Get(typed__buffer), _arr.Get(typed__offset), n*4))
+	for i := 0; i < n; i++ {
+		_dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+	return n
+}
@@ -0,0 +1,445 @@
+//+build tinygo wasm,js
+
+package audio
+
+import (
+	"fmt"
+	"sync"
+	"syscall/js"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+const (
+	function__connect    = "connect"
+	function__disconnect = "disconnect"
+	function__start      = "start"
+	function__stop       = "stop"
+
+	function__createGain               = "createGain"
+	function__createPanner             = "createPanner"
+	function__createOscillator         = "createOscillator"
+	function__createBiquadFilter       = "createBiquadFilter"
+	function__createAnalyser           = "createAnalyser"
+	function__createBufferSource       = "createBufferSource"
+	function__createMediaElementSource = "createMediaElementSource"
+	function__createMediaStreamSource  = "createMediaStreamSource"
+
+	function__setValueAtTime               = "setValueAtTime"
+	function__linearRampToValueAtTime      = "linearRampToValueAtTime"
+	function__exponentialRampToValueAtTime = "exponentialRampToValueAtTime"
+	function__setTargetAtTime              = "setTargetAtTime"
+	function__cancelScheduledValues        = "cancelScheduledValues"
+
+	function__getByteFrequencyData   = "getByteFrequencyData"
+	function__getFloatFrequencyData  = "getFloatFrequencyData"
+	function__getByteTimeDomainData  = "getByteTimeDomainData"
+	function__getFloatTimeDomainData = "getFloatTimeDomainData"
+
+	function__setPosition    = "setPosition"
+	function__setOrientation = "setOrientation"
+
+	float32Array__name = "Float32Array"
+
+	property__value     = "value"
+	property__type      = "type"
+	property__buffer    = "buffer"
+	property__loop      = "loop"
+	property__loopStart = "loopStart"
+	property__loopEnd   = "loopEnd"
+
+	property__fftSize               = "fftSize"
+	property__frequencyBinCount     = "frequencyBinCount"
+	property__smoothingTimeConstant = "smoothingTimeConstant"
+	property__minDecibels           = "minDecibels"
+	property__maxDecibels           = "maxDecibels"
+	property__onended               = "onended"
+
+	property__panningModel   = "panningModel"
+	property__distanceModel  = "distanceModel"
+	property__refDistance    = "refDistance"
+	property__maxDistance    = "maxDistance"
+	property__rolloffFactor  = "rolloffFactor"
+	property__coneInnerAngle = "coneInnerAngle"
+	property__coneOuterAngle = "coneOuterAngle"
+	property__coneOuterGain  = "coneOuterGain"
+
+	OSC__sine     = "sine"
+	OSC__square   = "square"
+	OSC__sawtooth = "sawtooth"
+	OSC__triangle = "triangle"
+
+	FILTER__lowpass   = "lowpass"
+	FILTER__highpass  = "highpass"
+	FILTER__bandpass  = "bandpass"
+	FILTER__lowshelf  = "lowshelf"
+	FILTER__highshelf = "highshelf"
+	FILTER__peaking   = "peaking"
+	FILTER__notch     = "notch"
+	FILTER__allpass   = "allpass"
+
+	PANNING__equalpower = "equalpower"
+	PANNING__HRTF       = "HRTF"
+
+	DISTANCE__linear      = "linear"
+	DISTANCE__inverse     = "inverse"
+	DISTANCE__exponential = "exponential"
+)
+
+// Node is any AudioNode. The typed nodes embed it, so they all Connect.
+type Node struct {
+	Value js.Value
+}
+
+func newNode(_v js.Value) *Node {
+	return &Node{Value: _v}
+}
+
+// Connect routes the output into _dst and returns _dst, so graphs chain:
+//
+//	src.Connect(filter.Node).Connect(gain.Node).Connect(ctx.Destination())
+func (n *Node) Connect(_dst *Node) *Node {
+	n.Value.Call(function__connect, _dst.Value)
+	return _dst
+}
+
+// ConnectParam modulates _p with the output, e.g. an LFO on a frequency.
+func (n *Node) ConnectParam(_p *Param) {
+	n.Value.Call(function__connect, _p.Value)
+}
+
+// Disconnect removes the connections to _dst, or every connection when none
+// is given.
+func (n *Node) Disconnect(_dst ...*Node) {
+	if len(_dst) == 0 {
+		n.Value.Call(function__disconnect)
+		return
+	}
+	for _, d := range _dst {
+		// disconnecting a node that is not connected throws
+		func() {
+			defer func() { recover() }()
+			n.Value.Call(function__disconnect, d.Value)
+		}()
+	}
+}
+
+// Param is an AudioParam: a value that can be set now or automated on the
+// audio clock.
+type Param struct {
+	Value js.Value
+}
+
+func newParam(_v js.Value) *Param {
+	return &Param{Value: _v}
+}
+
+// Set ...
+func (p *Param) Set(_v float64) {
+	p.Value.Set(property__value, _v)
+}
+
+// Get ...
+func (p *Param) Get() float64 {
+	return p.Value.Get(property__value).Float()
+}
+
+// SetAt ...
+func (p *Param) SetAt(_v, _t float64) *Param {
+	p.Value.Call(function__setValueAtTime, _v, _t)
+	return p
+}
+
+// LinearRampTo ramps from the previous event to _v, reached at _t.
+func (p *Param) LinearRampTo(_v, _t float64) *Param {
+	p.Value.Call(function__linearRampToValueAtTime, _v, _t)
+	return p
+}
+
+// ExponentialRampTo is LinearRampTo on a log scale; _v must not be 0.
+func (p *Param) ExponentialRampTo(_v, _t float64) *Param {
+	p.Value.Call(function__exponentialRampToValueAtTime, _v, _t)
+	return p
+}
+
+// SetTargetAt approaches _v from _t on with time constant _tc.
+func (p *Param) SetTargetAt(_v, _t, _tc float64) *Param {
+	p.Value.Call(function__setTargetAtTime, _v, _t, _tc)
+	return p
+}
+
+// Cancel drops the events scheduled from _t on.
+func (p *Param) Cancel(_t float64) *Param {
+	p.Value.Call(function__cancelScheduledValues, _t)
+	return p
+}
+
+// Gain ...
+type Gain struct {
+	*Node
+	Gain *Param
+}
+
+// NewGain ...
+func (c *Context) NewGain(_gain float64) *Gain {
+	v := c.Value.Call(function__createGain)
+	g := &Gain{Node: newNode(v), Gain: newParam(v.Get("gain"))}
+	g.Gain.Set(_gain)
+	return g
+}
+
+// Oscillator ...
+type Oscillator struct {
+	*Node
+	Frequency *Param
+	Detune    *Param
+
+	ended ended
+}
+
+// NewOscillator takes one of the OSC__ types and a frequency in Hz.
+func (c *Context) NewOscillator(_type string, _freq float64) *Oscillator {
+	v := c.Value.Call(function__createOscillator)
+	o := &Oscillator{Node: newNode(v), Frequency: newParam(v.Get("frequency")), Detune: newParam(v.Get("detune"))}
+	o.SetType(_type)
+	o.Frequency.Set(_freq)
+	return o
+}
+
+// SetType ...
+func (o *Oscillator) SetType(_type string) {
+	if _type != "" {
+		o.Value.Set(property__type, _type)
+	}
+}
+
+// Start plays at _when; a source can only be started once.
+func (o *Oscillator) Start(_when float64) {
+	o.Value.Call(function__start, _when)
+}
+
+// Stop ...
+func (o *Oscillator) Stop(_when float64) {
+	o.Value.Call(function__stop, _when)
+}
+
+// OnEnded calls _cb once the oscillator stops, replacing any earlier
+// callback; nil removes it, e.g. for an oscillator that will never start.
+func (o *Oscillator) OnEnded(_cb func()) {
+	o.ended.set(o.Value, _cb)
+}
+
+// BiquadFilter ...
+type BiquadFilter struct {
+	*Node
+	Frequency *Param
+	Q         *Param
+	Gain      *Param
+	Detune    *Param
+}
+
+// NewBiquadFilter takes one of the FILTER__ types and its frequency in Hz.
+func (c *Context) NewBiquadFilter(_type string, _freq float64) *BiquadFilter {
+	v := c.Value.Call(function__createBiquadFilter)
+	f := &BiquadFilter{
+		Node:      newNode(v),
+		Frequency: newParam(v.Get("frequency")),
+		Q:         newParam(v.Get("Q")),
+		Gain:      newParam(v.Get("gain")),
+		Detune:    newParam(v.Get("detune")),
+	}
+	f.SetType(_type)
+	f.Frequency.Set(_freq)
+	return f
+}
+
+// SetType ...
+func (f *BiquadFilter) SetType(_type string) {
+	if _type != "" {
+		f.Value.Set(property__type, _type)
+	}
+}
+
+// Analyser reads the spectrum and waveform of what passes through it.
+type Analyser struct {
+	*Node
+
+	bytes  js.Value
+	floats js.Value
+}
+
+// NewAnalyser takes the FFT size, a power of two from 32 to 32768.
+func (c *Context) NewAnalyser(_fftSize int) *Analyser {
+	a := &Analyser{Node: newNode(c.Value.Call(function__createAnalyser))}
+	if _fftSize > 0 {
+		a.Value.Set(property__fftSize, _fftSize)
+	}
+	return a
+}
+
+// FFTSize ...
+func (a *Analyser) FFTSize() int {
+	return a.Value.Get(property__fftSize).Int()
+}
+
+// Bins is the length of the frequency data, half the FFT size.
+func (a *Analyser) Bins() int {
+	return a.Value.Get(property__frequencyBinCount).Int()
+}
+
+// SetSmoothing takes 0 (none) to 1.
+func (a *Analyser) SetSmoothing(_s float64) {
+	a.Value.Set(property__smoothingTimeConstant, _s)
+}
+
+// SetDecibels sets the range mapped onto the byte frequency data.
+func (a *Analyser) SetDecibels(_min, _max float64) {
+	a.Value.Set(property__minDecibels, _min)
+	a.Value.Set(property__maxDecibels, _max)
+}
+
+// FrequencyData fills _dst with up to Bins magnitudes scaled to 0-255 and
+// returns the count. Reusing _dst every frame avoids allocating.
+func (a *Analyser) FrequencyData(_dst []byte) int {
+	arr := a.byteArray(a.Bins())
+	a.Value.Call(function__getByteFrequencyData, arr)
+	return js.CopyBytesToGo(_dst, arr)
+}
+
+// FloatFrequencyData fills _dst with up to Bins magnitudes in decibels.
+func (a *Analyser) FloatFrequencyData(_dst []float32) int {
+	arr := a.floatArray(a.Bins())
+	a.Value.Call(function__getFloatFrequencyData, arr)
+	return copyFloat32s(_dst, arr)
+}
+
+// TimeDomainData fills _dst with up to FFTSize waveform samples, 128 being
+// silence.
+func (a *Analyser) TimeDomainData(_dst []byte) int {
+	arr := a.byteArray(a.FFTSize())
+	a.Value.Call(function__getByteTimeDomainData, arr)
+	return js.CopyBytesToGo(_dst, arr)
+}
+
+// FloatTimeDomainData fills _dst with up to FFTSize samples from -1 to 1.
+func (a *Analyser) FloatTimeDomainData(_dst []float32) int {
+	arr := a.floatArray(a.FFTSize())
+	a.Value.Call(function__getFloatTimeDomainData, arr)
+	return copyFloat32s(_dst, arr)
+}
+
+// byteArray and floatArray keep one JS array per Analyser, grown when the FFT
+// size changes.
+func (a *Analyser) byteArray(_n int) js.Value {
+	if a.bytes.IsUndefined() || a.bytes.Get(typed__length).Int() < _n {
+		a.bytes = js.Global().Get(uint8Array__name).New(_n)
+	}
+	return a.bytes.Call("subarray", 0, _n)
+}
+
+func (a *Analyser) floatArray(_n int) js.Value {
+	if a.floats.IsUndefined() || a.floats.Get(typed__length).Int() < _n {
+		a.floats = js.Global().Get(float32Array__name).New(_n)
+	}
+	return a.floats.Call("subarray", 0, _n)
+}
+
+// BufferSource plays a Buffer once; make a new one for every play.
+type BufferSource struct {
+	*Node
+	PlaybackRate *Param
+	Detune       *Param
+
+	ended ended
+}
+
+// NewBufferSource ...
+func (c *Context) NewBufferSource(_b *Buffer) *BufferSource {
+	v := c.Value.Call(function__createBufferSource)
+	s := &BufferSource{Node: newNode(v), PlaybackRate: newParam(v.Get("playbackRate")), Detune: newParam(v.Get("detune"))}
+	if _b != nil {
+		v.Set(property__buffer, _b.Value)
+	}
+	return s
+}
+
+// SetLoop loops between _start and _end seconds of the buffer; 0, 0 loops the
+// whole buffer.
+func (s *BufferSource) SetLoop(_on bool, _start, _end float64) {
+	s.Value.Set(property__loop, _on)
+	s.Value.Set(property__loopStart, _start)
+	s.Value.Set(property__loopEnd, _end)
+}
+
+// Start plays from _offset seconds into the buffer at _when.
+func (s *BufferSource) Start(_when, _offset float64) {
+	s.Value.Call(function__start, _when, _offset)
+}
+
+// Stop ...
+func (s *BufferSource) Stop(_when float64) {
+	s.Value.Call(function__stop, _when)
+}
+
+// OnEnded calls _cb once the source stops, replacing any earlier callback;
+// nil removes it, e.g. for a source that will never start.
+func (s *BufferSource) OnEnded(_cb func()) {
+	s.ended.set(s.Value, _cb)
+}
+
+// NewMediaElementSource routes a <video> or <audio> through the graph; it is
+// then only heard through the graph.
+func (c *Context) NewMediaElementSource(_m *web.MediaElement) (*Node, error) {
+	if err := web.ValidJSValue(_m.String(), _m.Value); err != nil {
+		return nil, fmt.Errorf("[audio] [NewMediaElementSource] [error]: %v", err)
+	}
+	return newNode(c.Value.Call(function__createMediaElementSource, _m.Value)), nil
+}
+
+// NewMediaStreamSource routes captured audio, e.g. a microphone, into the
+// graph.
+func (c *Context) NewMediaStreamSource(_s *web.MediaStream) (*Node, error) {
+	if err := web.ValidJSValue("stream", _s.Value); err != nil {
+		return nil, fmt.Errorf("[audio] [NewMediaStreamSource] [error]: %v", err)
+	}
+	return newNode(c.Value.Call(function__createMediaStreamSource, _s.Value)), nil
+}
+
+// ended owns a source's onended handler. The js.Func is released when it is
+// replaced or removed, or when it fires, since a source only ends once.
+type ended struct {
+	mu     sync.Mutex
+	fn     js.Func
+	active bool
+}
+
+func (e *ended) set(_v js.Value, _cb func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.release(_v)
+	if _cb == nil {
+		return
+	}
+	var f js.Func
+	f = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		e.mu.Lock()
+		if e.active && e.fn.Equal(f.Value) {
+			e.release(_v)
+		}
+		e.mu.Unlock()
+		_cb()
+		return nil
+	})
+	e.fn, e.active = f, true
+	_v.Set(property__onended, f)
+}
+
+// release must be called with mu held.
+func (e *ended) release(_v js.Value) {
+	if !e.active {
+		return
+	}
+	_v.Set(property__onended, js.Null())
+	e.fn.Release()
+	e.fn, e.active = js.Func{}, false
+}
@@ -0,0 +1,218 @@
+//+build tinygo wasm,js
+
+package audio
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/zeptotenshi/wasmGo/aframe"
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+const (
+	param__positionX = "positionX"
+	param__positionY = "positionY"
+	param__positionZ = "positionZ"
+	param__forwardX  = "forwardX"
+	param__forwardY  = "forwardY"
+	param__forwardZ  = "forwardZ"
+	param__upX       = "upX"
+	param__upY       = "upY"
+	param__upZ       = "upZ"
+	param__orientX   = "orientationX"
+	param__orientY   = "orientationY"
+	param__orientZ   = "orientationZ"
+
+	property__matrixWorld = "matrixWorld"
+	property__elements    = "elements"
+
+	// smoothing is the time constant of the position updates, hiding the
+	// zipper noise of a value jumping once per frame.
+	smoothing = 0.02
+)
+
+// PannerOptions configure a Panner; zero values keep the browser defaults.
+type PannerOptions struct {
+	// PanningModel is PANNING__HRTF (default here) or PANNING__equalpower.
+	PanningModel string
+	// DistanceModel is one of the DISTANCE__ constants.
+	DistanceModel string
+	RefDistance   float64
+	MaxDistance   float64
+	// Rolloff keeps the browser default when nil; 0 turns distance
+	// attenuation off.
+	Rolloff *float64
+	// ConeInner and ConeOuter are in degrees; ConeOuterGain is the gain
+	// outside the outer cone, the browser default when nil.
+	ConeInner     float64
+	ConeOuter     float64
+	ConeOuterGain *float64
+}
+
+// Panner positions a sound in 3D relative to the Listener.
+type Panner struct {
+	*Node
+	ctx *Context
+}
+
+// NewPanner ...
+func (c *Context) NewPanner(_opts PannerOptions) *Panner {
+	v := c.Value.Call(function__createPanner)
+	if _opts.PanningModel == "" {
+		_opts.PanningModel = PANNING__HRTF
+	}
+	v.Set(property__panningModel, _opts.PanningModel)
+	if _opts.DistanceModel != "" {
+		v.Set(property__distanceModel, _opts.DistanceModel)
+	}
+	for k, f := range map[string]float64{
+		property__refDistance:    _opts.RefDistance,
+		property__maxDistance:    _opts.MaxDistance,
+		property__coneInnerAngle: _opts.ConeInner,
+		property__coneOuterAngle: _opts.ConeOuter,
+	} {
+		if f != 0 {
+			v.Set(k, f)
+		}
+	}
+	for k, f := range map[string]*float64{
+		property__rolloffFactor: _opts.Rolloff,
+		property__coneOuterGain: _opts.ConeOuterGain,
+	} {
+		if f != nil {
+			v.Set(k, *f)
+		}
+	}
+	return &Panner{Node: newNode(v), ctx: c}
+}
+
+// SetPosition moves the sound, in the scene's units.
+func (p *Panner) SetPosition(_x, _y, _z float64) {
+	setVector(p.ctx, p.Value, function__setPosition, [3]string{param__positionX, param__positionY, param__positionZ}, _x, _y, _z)
+}
+
+// SetOrientation points the cone of a directional sound.
+func (p *Panner) SetOrientation(_x, _y, _z float64) {
+	setVector(p.ctx, p.Value, function__setOrientation, [3]string{param__orientX, param__orientY, param__orientZ}, _x, _y, _z)
+}
+
+// Follow moves the panner to the world position of _e on every frame of _loop
+// and returns the func that stops following. Like any Loop tick it needs the
+// loop started.
+func (p *Panner) Follow(_loop *web.Loop, _e *aframe.AEntity) func() {
+	return _loop.Add(func(web.Frame) {
+		x, y, z, err := _e.GetWorldPosition()
+		if err != nil {
+			return
+		}
+		p.SetPosition(x, y, z)
+	})
+}
+
+// Attach builds source -> Panner -> destination for _src at the position of
+// _e, following it on _loop. The returned func stops following and
+// disconnects the panner.
+func (c *Context) Attach(_loop *web.Loop, _e *aframe.AEntity, _src *Node, _opts PannerOptions) (*Panner, func(), error) {
+	x, y, z, err := _e.GetWorldPosition()
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("[audio] [Attach] [error]: %v", err)
+	}
+	p := c.NewPanner(_opts)
+	// the first position is set outright so the sound does not glide in
+	// from the origin
+	setParams(p.Value, [3]string{param__positionX, param__positionY, param__positionZ}, x, y, z)
+	_src.Connect(p.Node).Connect(c.Destination())
+	stop := p.Follow(_loop, _e)
+	return p, func() {
+		stop()
+		_src.Disconnect(p.Node)
+		p.Disconnect()
+	}, nil
+}
+
+// Listener is the position and facing of the ear.
+type Listener struct {
+	Value js.Value
+	ctx   *Context
+}
+
+// SetPosition ...
+func (l *Listener) SetPosition(_x, _y, _z float64) {
+	setVector(l.ctx, l.Value, function__setPosition, [3]string{param__positionX, param__positionY, param__positionZ}, _x, _y, _z)
+}
+
+// SetOrientation sets the forward and up vectors.
+func (l *Listener) SetOrientation(_fx, _fy, _fz, _ux, _uy, _uz float64) {
+	if l.Value.Get(param__forwardX).Type() != js.TypeObject {
+		l.Value.Call(function__setOrientation, _fx, _fy, _fz, _ux, _uy, _uz)
+		return
+	}
+	setVector(l.ctx, l.Value, "", [3]string{param__forwardX, param__forwardY, param__forwardZ}, _fx, _fy, _fz)
+	setVector(l.ctx, l.Value, "", [3]string{param__upX, param__upY, param__upZ}, _ux, _uy, _uz)
+}
+
+// Follow keeps the listener at the world position of _e, usually the camera
+// entity, on every frame of _loop, facing the way the entity looks. Both
+// follow every parent transform, so turning a camera rig turns the ear.
+func (l *Listener) Follow(_loop *web.Loop, _e *aframe.AEntity) func() {
+	return _loop.Add(func(web.Frame) {
+		x, y, z, err := _e.GetWorldPosition()
+		if err != nil {
+			return
+		}
+		l.SetPosition(x, y, z)
+		// a camera looks down its world -z
+		fx, fy, fz, err := _e.GetWorldDirection()
+		if err != nil {
+			return
+		}
+		ux, uy, uz := worldUp(_e)
+		l.SetOrientation(-fx, -fy, -fz, ux, uy, uz)
+	})
+}
+
+// worldUp returns the +y axis of _e in scene coordinates, from the matrix
+// GetWorldDirection has just updated; straight up when it is unavailable.
+func worldUp(_e *aframe.AEntity) (float64, float64, float64) {
+	m, err := _e.GetProperty(aframe.PROPERTY__object3D, property__matrixWorld, property__elements)
+	if err != nil {
+		return 0, 1, 0
+	}
+	x, y, z := m.Index(4).Float(), m.Index(5).Float(), m.Index(6).Float()
+	n := math.Sqrt(x*x + y*y + z*z)
+	if n == 0 {
+		return 0, 1, 0
+	}
+	return x / n, y / n, z / n
+}
+
+// setVector sets three AudioParams smoothly, or calls the deprecated _method
+// on browsers without them.
+func setVector(_c *Context, _v js.Value, _method string, _params [3]string, _x, _y, _z float64) {
+	if _v.Get(_params[0]).Type() != js.TypeObject {
+		if _method != "" {
+			_v.Call(_method, _x, _y, _z)
+		}
+		return
+	}
+	if _c == nil {
+		setParams(_v, _params, _x, _y, _z)
+		return
+	}
+	t := _c.CurrentTime()
+	for i, f := range [3]float64{_x, _y, _z} {
+		_v.Get(_params[i]).Call(function__setTargetAtTime, f, t, smoothing)
+	}
+}
+
+func setParams(_v js.Value, _params [3]string, _x, _y, _z float64) {
+	if _v.Get(_params[0]).Type() != js.TypeObject {
+		_v.Call(function__setPosition, _x, _y, _z)
+		return
+	}
+	for i, f := range [3]float64{_x, _y, _z} {
+		_v.Get(_params[i]).Set(property__value, f)
+	}
+}
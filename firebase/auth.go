@@ -3,7 +3,10 @@
 package firebase
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"sync"
 	"syscall/js"
 
 	"github.com/zeptotenshi/wasmGo/web"
@@ -13,6 +16,7 @@ const (
 	auth = "auth"
 
 	AUTH__user             = "user"
+	AUTH__currentUser      = "currentUser"
 	AUTH__user_displayName = "displayName"
 	AUTH__user_idToken     = "id-token"
 
@@ -34,6 +38,94 @@ const (
 type Auth struct {
 	value js.Value
 	User  js.Value
+
+	mu          sync.Mutex
+	initialized bool
+	ready       chan struct{}
+	onInit      []func()
+}
+
+func newAuth(_v js.Value) *Auth {
+	a := &Auth{value: _v, User: js.ValueOf(nil), ready: make(chan struct{})}
+
+	// the first auth state change means the session has been restored
+	var fn js.Func
+	unsubscribe := js.Undefined()
+	subscribed, fired := false, false
+	stop := func() {
+		if unsubscribe.Type() == js.TypeFunction {
+			unsubscribe.Invoke()
+		}
+		fn.Release()
+	}
+	fn = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		if fired {
+			return nil
+		}
+		fired = true
+		a.initialize()
+		if subscribed {
+			stop()
+		}
+		return nil
+	})
+	unsubscribe = a.value.Call(function__onAuthStateChanged, fn)
+	subscribed = true
+	if fired {
+		stop()
+	}
+	return a
+}
+
+func (a *Auth) initialize() {
+	a.mu.Lock()
+	a.initialized = true
+	cbs := a.onInit
+	a.onInit = nil
+	a.mu.Unlock()
+
+	close(a.ready)
+	for _, cb := range cbs {
+		cb()
+	}
+}
+
+// Initialized reports whether firebase has restored the session of the last
+// page load, so CurrentUser can be trusted.
+func (a *Auth) Initialized() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.initialized
+}
+
+// OnInitialized calls _cb once the session has been restored, or right away
+// when it already is. Start routers that use RequireUser from here:
+//
+//	a.OnInitialized(func() {
+//		if err := router.Start(); err != nil {
+//			win.Error(err)
+//		}
+//	})
+func (a *Auth) OnInitialized(_cb func()) {
+	a.mu.Lock()
+	if !a.initialized {
+		a.onInit = append(a.onInit, _cb)
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+	_cb()
+}
+
+// WaitInitialized blocks until the session has been restored. It must be
+// called from a goroutine.
+func (a *Auth) WaitInitialized(_ctx context.Context) error {
+	select {
+	case <-a.ready:
+		return nil
+	case <-_ctx.Done():
+		return fmt.Errorf("[%s] [%s] [WaitInitialized] [error]: %v", firebase, auth, _ctx.Err())
+	}
 }
 
 func (a *Auth) SetAuthStateChangedCallback(_cb js.Func) error {
@@ -44,57 +136,114 @@ func (a *Auth) SetAuthStateChangedCallback(_cb js.Func) error {
 	return nil
 }
 
-func (a *Auth) CreateUser(_uname, _pword string, _errCB js.Func) error {
+// CreateUser returns the promise from createUserWithEmailAndPassword, which
+// resolves with the new UserCredential.
+func (a *Auth) CreateUser(_uname, _pword string) (*web.Promise, error) {
 	err := web.ValidJSValue(auth, a.value)
 	if err != nil {
-		return fmt.Errorf("[%s] [%s] [CreateUser] [error]: %v", firebase, auth, err)
+		return nil, fmt.Errorf("[%s] [%s] [CreateUser] [error]: %v", firebase, auth, err)
 	}
 	prom := a.value.Call(function__createUserWithEmailAndPassword, _uname, _pword)
 	if err = web.ValidJSValue(web.PROMISE, prom); err != nil {
-		return fmt.Errorf("[%s] [%s] [CreateUser] [error]: %v", firebase, auth, err)
+		return nil, fmt.Errorf("[%s] [%s] [CreateUser] [error]: %v", firebase, auth, err)
 	}
-	prom.Call(web.PROMISE__catch, _errCB)
-	return nil
+	return web.NewPromise(prom).Then(a.setUser), nil
 }
 
-func (a *Auth) SignIn(_uname, _pword string, _errCB js.Func) error {
+// SignIn returns the promise from signInWithEmailAndPassword, which resolves
+// with the signed in UserCredential.
+func (a *Auth) SignIn(_uname, _pword string) (*web.Promise, error) {
 	err := web.ValidJSValue(auth, a.value)
 	if err != nil {
-		return fmt.Errorf("[%s] [%s] [SignIn] [error]: %v", firebase, auth, err)
+		return nil, fmt.Errorf("[%s] [%s] [SignIn] [error]: %v", firebase, auth, err)
 	}
 	prom := a.value.Call(function__signInWithEmailAndPassword, _uname, _pword)
 	if err = web.ValidJSValue(web.PROMISE, prom); err != nil {
-		return fmt.Errorf("[%s] [%s] [SignIn] [error]: %v", firebase, auth, err)
+		return nil, fmt.Errorf("[%s] [%s] [SignIn] [error]: %v", firebase, auth, err)
 	}
-	prom.Call(web.PROMISE__catch, _errCB)
-	return nil
+	return web.NewPromise(prom).Then(a.setUser), nil
 }
 
-func (a *Auth) SignOut() error {
-	if err := web.ValidJSValue(auth, a.value); err != nil {
-		return fmt.Errorf("[%s] [%s] [SignOut] [error]: %v", firebase, auth, err)
+// SignOut signs the user out; User is cleared once the returned promise
+// resolves.
+func (a *Auth) SignOut() (*web.Promise, error) {
+	err := web.ValidJSValue(auth, a.value)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] [%s] [SignOut] [error]: %v", firebase, auth, err)
 	}
-	a.value.Call(function__signOut)
-	return nil
+	prom := a.value.Call(function__signOut)
+	if err = web.ValidJSValue(web.PROMISE, prom); err != nil {
+		return nil, fmt.Errorf("[%s] [%s] [SignOut] [error]: %v", firebase, auth, err)
+	}
+	return web.NewPromise(prom).Then(func(js.Value) {
+		a.User = js.ValueOf(nil)
+	}), nil
 }
 
-func (a *Auth) GetIdToken(_successCB, _errorCB js.Func) error {
+// GetIdToken returns the promise from user.getIdToken, which resolves with the
+// (force refreshed) ID token string.
+func (a *Auth) GetIdToken() (*web.Promise, error) {
 	err := web.ValidJSValue(AUTH__user, a.User)
 	if err != nil {
-		return fmt.Errorf("[%s] [%s] [GetIdToken] [error]: %v", firebase, auth, err)
-	}
-	if err = web.ValidJSValue(fmt.Sprintf("%s.%s.success-callback", AUTH__user, function__user_getIdToken), js.ValueOf(_successCB)); err != nil {
-		return fmt.Errorf("[%s] [%s] [GetIdToken] [error]: %v", firebase, auth, err)
+		return nil, fmt.Errorf("[%s] [%s] [GetIdToken] [error]: %v", firebase, auth, err)
 	}
-
 	prom := a.User.Call(function__user_getIdToken, true)
 	if err = web.ValidJSValue(fmt.Sprintf("%s.%s", function__user_getIdToken, web.PROMISE), prom); err != nil {
-		return fmt.Errorf("[%s] [%s] [GetIdToken] [error]: %v", firebase, auth, err)
+		return nil, fmt.Errorf("[%s] [%s] [GetIdToken] [error]: %v", firebase, auth, err)
+	}
+	return web.NewPromise(prom), nil
+}
+
+// IdToken blocks until the current user's ID token is available.
+func (a *Auth) IdToken(_ctx context.Context) (string, error) {
+	prom, err := a.GetIdToken()
+	if err != nil {
+		return "", err
 	}
-	prom.Call(web.PROMISE__then, _successCB)
-	if err = web.ValidJSValue(fmt.Sprintf("%s.%s.error-callback", AUTH__user, function__user_getIdToken), js.ValueOf(_errorCB)); err == nil {
-		prom.Call(web.PROMISE__catch, _errorCB)
+	tv, err := prom.Await(_ctx)
+	if err != nil {
+		return "", fmt.Errorf("[%s] [%s] [IdToken] [error]: %v", firebase, auth, err)
 	}
+	return tv.String(), nil
+}
 
-	return nil
+func (a *Auth) setUser(_cred js.Value) {
+	if err := web.ValidJSValue("credential", _cred); err != nil {
+		return
+	}
+	if u := _cred.Get(AUTH__user); web.ValidJSValue(AUTH__user, u) == nil {
+		a.User = u
+	}
+}
+
+// CurrentUser returns auth.currentUser, or null when nobody is signed in.
+// Right after load it stays null until firebase has restored the session, which
+// is signalled by the first auth state change.
+func (a *Auth) CurrentUser() js.Value {
+	if err := web.ValidJSValue(auth, a.value); err != nil {
+		return js.Null()
+	}
+	u := a.value.Get(AUTH__currentUser)
+	if web.ValidJSValue(AUTH__currentUser, u) != nil {
+		return js.Null()
+	}
+	return u
+}
+
+// SignedIn ...
+func (a *Auth) SignedIn() bool {
+	return !a.CurrentUser().IsNull()
+}
+
+// RequireUser is a router guard that sends signed out users to _loginPath,
+// passing the requested path and query in the "next" query parameter. Until
+// the session is restored every visitor looks signed out, so start the router
+// from OnInitialized.
+func (a *Auth) RequireUser(_loginPath string) web.Guard {
+	return func(_m *web.RouteMatch) string {
+		if a.SignedIn() || _m.Path == _loginPath {
+			return ""
+		}
+		return fmt.Sprintf("%s?next=%s", _loginPath, url.QueryEscape(_m.RequestURI()))
+	}
 }
@@ -0,0 +1,141 @@
+//+build tinygo wasm,js
+
+package firebase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/firebase"
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+func newAuth(t *testing.T) *firebase.Auth {
+	t.Helper()
+	fb, err := firebase.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := fb.Auth()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestAuthRequireUser(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	a := newAuth(t)
+
+	var got *web.RouteMatch
+	handle := func(_m *web.RouteMatch) { got = _m }
+	r := web.NewRouter(web.NewWindow(), web.HistoryMode).
+		Handle("/login", handle).
+		Handle("/orders/:id", handle, a.RequireUser("/login"))
+
+	if err := r.Navigate("/orders/7?tab=items&sort=new"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Pattern != "/login" || got.Query.Get("next") != "/orders/7?tab=items&sort=new" {
+		t.Errorf("signed out visit went to %q next=%q", got.Pattern, got.Query.Get("next"))
+	}
+
+	h.SetAuthUser("ada")
+	if err := r.Navigate("/orders/7"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Pattern != "/orders/:id" {
+		t.Errorf("signed in visit went to %q", got.Pattern)
+	}
+}
+
+func TestAuthSignIn(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	a := newAuth(t)
+	ctx := context.Background()
+
+	if !a.Initialized() {
+		t.Fatal("not initialized after the first auth state change")
+	}
+	if err := a.WaitInitialized(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if a.SignedIn() {
+		t.Fatal("signed in before SignIn")
+	}
+
+	prom, err := a.SignIn("ada@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = prom.Await(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !a.SignedIn() || a.User.Get("uid").String() != "ada@example.com" {
+		t.Errorf("after SignIn: signed in %v, User %v", a.SignedIn(), a.User)
+	}
+	calls := h.CallsTo(webtest.TargetAuth, "signInWithEmailAndPassword")
+	if len(calls) != 1 || calls[0].Arg(1).String() != "secret" {
+		t.Errorf("signInWithEmailAndPassword calls = %v", calls)
+	}
+
+	tok, err := a.IdToken(ctx)
+	if err != nil || tok != webtest.DefaultIdToken {
+		t.Errorf("IdToken = %q, %v", tok, err)
+	}
+
+	if prom, err = a.SignOut(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = prom.Await(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if a.SignedIn() || !a.User.IsNull() {
+		t.Errorf("after SignOut: signed in %v, User %v", a.SignedIn(), a.User)
+	}
+	if _, err = a.IdToken(ctx); err == nil {
+		t.Error("IdToken succeeded while signed out")
+	}
+}
+
+func TestAuthErrors(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	a := newAuth(t)
+
+	h.AuthError = "auth/email-already-in-use"
+	prom, err := a.CreateUser("ada@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = prom.Await(context.Background())
+	var perr *web.PromiseError
+	if !errors.As(err, &perr) || perr.Code != "auth/email-already-in-use" {
+		t.Errorf("CreateUser = %v, want a PromiseError with the firebase code", err)
+	}
+	if a.SignedIn() || !a.User.IsNull() {
+		t.Error("signed in after a rejected CreateUser")
+	}
+
+	h.AuthError = ""
+	if prom, err = a.SignIn("ada@example.com", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = prom.Await(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	h.AuthError = "auth/network-request-failed"
+	if prom, err = a.SignOut(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = prom.Await(context.Background()); !errors.As(err, &perr) || perr.Code != h.AuthError {
+		t.Errorf("SignOut = %v, want a PromiseError with the firebase code", err)
+	}
+	if !a.SignedIn() || a.User.IsNull() {
+		t.Error("User cleared by a rejected SignOut")
+	}
+}
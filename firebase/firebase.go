@@ -36,7 +36,7 @@ func (f *Firebase) Auth() (*Auth, error) {
 	if err := web.ValidJSValue(fmt.Sprintf("%s.%s", firebase, auth), authClient); err != nil {
 		return nil, err
 	}
-	return &Auth{value: authClient, User: js.ValueOf(nil)}, nil
+	return newAuth(authClient), nil
 }
 
 func (f *Firebase) Store() (*Firestore, error) {
@@ -47,7 +47,7 @@ func (f *Firebase) Store() (*Firestore, error) {
 	if err := web.ValidJSValue(fmt.Sprintf("%s.%s", firebase, firestore), storeClient); err != nil {
 		return nil, err
 	}
-	return &Firestore{value: storeClient}, nil
+	return &Firestore{value: storeClient, blob: f.value.Get(firestore).Get(blob__name)}, nil
 }
 
 func (f *Firebase) Storage() (*Storage, error) {
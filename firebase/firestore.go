@@ -3,6 +3,8 @@
 package firebase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"syscall/js"
 
@@ -12,11 +14,32 @@ import (
 const (
 	firestore = "firestore"
 
-	function__doc = "doc"
+	function__doc    = "doc"
+	function__get    = "get"
+	function__set    = "set"
+	function__delete = "delete"
+	function__data   = "data"
+
+	snapshot__exists = "exists"
+	option__merge    = "merge"
+
+	blob__name               = "Blob"
+	function__fromUint8Array = "fromUint8Array"
+
+	object__name          = "Object"
+	array__name           = "Array"
+	uint8Array__name      = "Uint8Array"
+	function__keys        = "keys"
+	property__constructor = "constructor"
+)
+
+var (
+	FIRESTORE_ERROR_DOC_NOT_FOUND = errors.New("firestore document does not exist")
 )
 
 type Firestore struct {
 	value js.Value
+	blob  js.Value
 }
 
 func (f *Firestore) GetDoc(_p string) (js.Value, error) {
@@ -32,3 +55,75 @@ func (f *Firestore) GetDoc(_p string) (js.Value, error) {
 
 	return doc, nil
 }
+
+// ReadDoc fetches the document at _p and unmarshals its data into _v (see
+// web.Unmarshal for the `js` struct tags). Timestamps fill time.Time fields
+// and Blobs fill []byte fields.
+func (f *Firestore) ReadDoc(_ctx context.Context, _p string, _v interface{}) error {
+	doc, err := f.GetDoc(_p)
+	if err != nil {
+		return err
+	}
+	snap, err := web.Await(_ctx, doc.Call(function__get))
+	if err != nil {
+		return fmt.Errorf("[firebase] [firestore] [ReadDoc] [%s] [error]: %v", _p, err)
+	}
+	if !snap.Get(snapshot__exists).Bool() {
+		return fmt.Errorf("[firebase] [firestore] [ReadDoc] [%s] [error]: %w", _p, FIRESTORE_ERROR_DOC_NOT_FOUND)
+	}
+	if err = web.Unmarshal(snap.Call(function__data), _v); err != nil {
+		return fmt.Errorf("[firebase] [firestore] [ReadDoc] [%s] [error]: %v", _p, err)
+	}
+	return nil
+}
+
+// WriteDoc marshals _v and sets it as the document at _p. With _merge the
+// fields are merged into an existing document instead of replacing it.
+// time.Time is stored as a Timestamp and []byte as a Blob.
+func (f *Firestore) WriteDoc(_ctx context.Context, _p string, _v interface{}, _merge bool) error {
+	doc, err := f.GetDoc(_p)
+	if err != nil {
+		return err
+	}
+	prom := doc.Call(function__set, f.storable(web.Marshal(_v)), map[string]interface{}{option__merge: _merge})
+	if _, err = web.Await(_ctx, prom); err != nil {
+		return fmt.Errorf("[firebase] [firestore] [WriteDoc] [%s] [error]: %v", _p, err)
+	}
+	return nil
+}
+
+// DeleteDoc ...
+func (f *Firestore) DeleteDoc(_ctx context.Context, _p string) error {
+	doc, err := f.GetDoc(_p)
+	if err != nil {
+		return err
+	}
+	if _, err = web.Await(_ctx, doc.Call(function__delete)); err != nil {
+		return fmt.Errorf("[firebase] [firestore] [DeleteDoc] [%s] [error]: %v", _p, err)
+	}
+	return nil
+}
+
+// storable replaces the Uint8Arrays Marshal makes of []byte with Blobs, the
+// only binary type the SDK accepts in a document.
+func (f *Firestore) storable(_v js.Value) js.Value {
+	if _v.Type() != js.TypeObject {
+		return _v
+	}
+	if _v.InstanceOf(js.Global().Get(uint8Array__name)) {
+		if f.blob.Type() != js.TypeFunction && f.blob.Type() != js.TypeObject {
+			return _v
+		}
+		return f.blob.Call(function__fromUint8Array, _v)
+	}
+	// only the plain objects and arrays Marshal builds are walked
+	if c := _v.Get(property__constructor); !c.Equal(js.Global().Get(object__name)) && !c.Equal(js.Global().Get(array__name)) {
+		return _v
+	}
+	keys := js.Global().Get(object__name).Call(function__keys, _v)
+	for i := 0; i < keys.Length(); i++ {
+		k := keys.Index(i).String()
+		_v.Set(k, f.storable(_v.Get(k)))
+	}
+	return _v
+}
@@ -0,0 +1,95 @@
+//+build tinygo wasm,js
+
+package firebase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zeptotenshi/wasmGo/firebase"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+func newStore(t *testing.T) *firebase.Firestore {
+	t.Helper()
+	fb, err := firebase.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := fb.Store()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func TestFirestoreRoundTrip(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	fs := newStore(t)
+	ctx := context.Background()
+
+	type note struct {
+		Title   string    `js:"title"`
+		Created time.Time `js:"created"`
+		Body    []byte    `js:"body"`
+		Tags    []string  `js:"tags"`
+	}
+	in := note{
+		Title:   "hello",
+		Created: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Body:    []byte("hi there"),
+		Tags:    []string{"a"},
+	}
+	if err := fs.WriteDoc(ctx, "notes/1", in, false); err != nil {
+		t.Fatal(err)
+	}
+	if d := h.Doc("notes/1"); d.Get("created").Get("toMillis").IsUndefined() || d.Get("body").Get("toUint8Array").IsUndefined() {
+		t.Fatalf("stored doc did not hold a Timestamp and a Blob")
+	}
+
+	var out note
+	if err := fs.ReadDoc(ctx, "notes/1", &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Title != in.Title || !out.Created.Equal(in.Created) || !bytes.Equal(out.Body, in.Body) || len(out.Tags) != 1 {
+		t.Errorf("ReadDoc = %+v, want %+v", out, in)
+	}
+
+	if err := fs.DeleteDoc(ctx, "notes/1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.ReadDoc(ctx, "notes/1", &out); !errors.Is(err, firebase.FIRESTORE_ERROR_DOC_NOT_FOUND) {
+		t.Errorf("ReadDoc after delete = %v, want FIRESTORE_ERROR_DOC_NOT_FOUND", err)
+	}
+}
+
+func TestFirestoreMerge(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	fs := newStore(t)
+
+	type profile struct {
+		Name string `js:"name"`
+		Age  int    `js:"age,omitempty"`
+	}
+	if err := fs.WriteDoc(context.Background(), "users/ada", profile{Name: "Ada"}, true); err != nil {
+		t.Fatal(err)
+	}
+	sets := h.CallsTo(webtest.TargetFirestore, "set")
+	if len(sets) != 1 || !sets[0].Arg(1).Get("merge").Bool() {
+		t.Fatalf("set calls = %v, want one with merge", sets)
+	}
+	if d := h.Doc("users/ada"); d.Get("name").String() != "Ada" || !d.Get("age").IsUndefined() {
+		t.Errorf("stored %v", sets[0].Arg(0))
+	}
+
+	h.SetDoc("users/bob", map[string]interface{}{"name": 5})
+	var p profile
+	if err := fs.ReadDoc(context.Background(), "users/bob", &p); err == nil {
+		t.Error("ReadDoc of a number into a string succeeded")
+	}
+}
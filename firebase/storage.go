@@ -3,13 +3,90 @@
 package firebase
 
 import (
+	"context"
+	"fmt"
 	"syscall/js"
+
+	"github.com/zeptotenshi/wasmGo/web"
 )
 
 const (
 	storage = "storage"
+
+	function__ref            = "ref"
+	function__put            = "put"
+	function__getDownloadURL = "getDownloadURL"
+
+	metadata__contentType = "contentType"
 )
 
 type Storage struct {
 	value js.Value
 }
+
+// Ref returns the storage reference for _p.
+func (s *Storage) Ref(_p string) (js.Value, error) {
+	err := web.ValidJSValue(storage, s.value)
+	if err != nil {
+		return js.ValueOf(nil), fmt.Errorf("[firebase] [storage] [Ref] [error]: %v", err)
+	}
+
+	ref := s.value.Call(function__ref, _p)
+	if err = web.ValidJSValue(fmt.Sprintf("%s.%s", function__ref, _p), ref); err != nil {
+		return js.ValueOf(nil), fmt.Errorf("[firebase] [storage] [Ref] [error]: %v", err)
+	}
+
+	return ref, nil
+}
+
+// Upload puts _f (e.g. from web.Element.DropZone or OnFiles) at _p and
+// returns its download URL.
+func (s *Storage) Upload(_ctx context.Context, _p string, _f *web.File) (string, error) {
+	u, err := s.put(_ctx, _p, _f.Value, _f.Type)
+	if err != nil {
+		return "", fmt.Errorf("[firebase] [storage] [Upload] [%s] [error]: %v", _p, err)
+	}
+	return u, nil
+}
+
+// UploadBytes puts _b at _p as _mime and returns its download URL.
+func (s *Storage) UploadBytes(_ctx context.Context, _p string, _b []byte, _mime string) (string, error) {
+	u, err := s.put(_ctx, _p, web.NewBlob(_b, _mime), _mime)
+	if err != nil {
+		return "", fmt.Errorf("[firebase] [storage] [UploadBytes] [%s] [error]: %v", _p, err)
+	}
+	return u, nil
+}
+
+// DownloadURL ...
+func (s *Storage) DownloadURL(_ctx context.Context, _p string) (string, error) {
+	ref, err := s.Ref(_p)
+	if err != nil {
+		return "", err
+	}
+	u, err := web.Await(_ctx, ref.Call(function__getDownloadURL))
+	if err != nil {
+		return "", fmt.Errorf("[firebase] [storage] [DownloadURL] [%s] [error]: %v", _p, err)
+	}
+	return u.String(), nil
+}
+
+func (s *Storage) put(_ctx context.Context, _p string, _blob js.Value, _mime string) (string, error) {
+	ref, err := s.Ref(_p)
+	if err != nil {
+		return "", err
+	}
+	meta := map[string]interface{}{}
+	if _mime != "" {
+		meta[metadata__contentType] = _mime
+	}
+	// the upload task is thenable and resolves once the upload completes
+	if _, err = web.Await(_ctx, ref.Call(function__put, _blob, meta)); err != nil {
+		return "", err
+	}
+	u, err := web.Await(_ctx, ref.Call(function__getDownloadURL))
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
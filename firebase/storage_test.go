@@ -0,0 +1,62 @@
+//+build tinygo wasm,js
+
+package firebase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/firebase"
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+func newStorage(t *testing.T) *firebase.Storage {
+	t.Helper()
+	fb, err := firebase.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := fb.Storage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStorageUpload(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	s := newStorage(t)
+	ctx := context.Background()
+
+	u, err := s.UploadBytes(ctx, "avatars/ada.png", []byte{1, 2, 3}, "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "https://storage.webtest/avatars/ada.png" {
+		t.Errorf("UploadBytes URL = %q", u)
+	}
+	puts := h.CallsTo(webtest.TargetStorage, "put")
+	if len(puts) != 1 {
+		t.Fatalf("put called %d times, want 1", len(puts))
+	}
+	if blob := puts[0].Arg(0); blob.Get("size").Int() != 3 || blob.Get("type").String() != "image/png" {
+		t.Errorf("put blob size %v type %v", blob.Get("size"), blob.Get("type"))
+	}
+	if ct := puts[0].Arg(1).Get("contentType").String(); ct != "image/png" {
+		t.Errorf("put contentType = %q", ct)
+	}
+
+	f := web.NewFile(web.NewBlob([]byte("hello"), "text/plain"))
+	if u, err = s.Upload(ctx, "notes/a.txt", f); err != nil || u != "https://storage.webtest/notes/a.txt" {
+		t.Errorf("Upload = %q, %v", u, err)
+	}
+
+	if u, err = s.DownloadURL(ctx, "notes/a.txt"); err != nil || u != "https://storage.webtest/notes/a.txt" {
+		t.Errorf("DownloadURL = %q, %v", u, err)
+	}
+	if refs := h.CallsTo(webtest.TargetStorage, "ref"); len(refs) != 3 || refs[2].Arg(0).String() != "notes/a.txt" {
+		t.Errorf("ref calls = %v", refs)
+	}
+}
@@ -3,6 +3,7 @@
 package metamask
 
 import (
+	"context"
 	"fmt"
 	"syscall/js"
 
@@ -34,10 +35,8 @@ const (
 	function__request     = "request"
 
 	request__method         = "method"
+	request__params         = "params"
 	method__requestAccounts = "eth_requestAccounts"
-
-	error__message = "message"
-	error__code    = "code"
 )
 
 var (
@@ -125,8 +124,37 @@ func (mm *MetaMask) EnableEthereum() {
 	mm.win.Debug("[MetaMask] [EnableEthereum] request:")
 	mm.win.LogValue(prom)
 
-	prom.Call(web.PROMISE__then, js.FuncOf(mm.accountsChanged))
-	prom.Call(web.PROMISE__catch, js.FuncOf(mm.handleError))
+	web.NewPromise(prom).
+		Then(func(_accounts js.Value) {
+			mm.accountsChanged(js.Undefined(), []js.Value{_accounts})
+		}).
+		Catch(mm.handleError)
+}
+
+// Request sends an EIP-1193 RPC request and unmarshals the result into
+// _result (which may be nil). _params is marshalled with web.Marshal, so Go
+// structs with `js` tags can be used for both.
+func (mm *MetaMask) Request(_ctx context.Context, _method string, _params interface{}, _result interface{}) error {
+	if err := web.ValidJSValue(ethereum, mm.provider); err != nil {
+		return fmt.Errorf("[MetaMask] [Request] [%s] [error]: %v", _method, err)
+	}
+
+	args := map[string]interface{}{request__method: _method}
+	if _params != nil {
+		args[request__params] = web.Marshal(_params)
+	}
+
+	tv, err := web.Await(_ctx, mm.provider.Call(function__request, args))
+	if err != nil {
+		return fmt.Errorf("[MetaMask] [Request] [%s] [error]: %v", _method, err)
+	}
+	if _result == nil {
+		return nil
+	}
+	if err = web.Unmarshal(tv, _result); err != nil {
+		return fmt.Errorf("[MetaMask] [Request] [%s] [error]: %v", _method, err)
+	}
+	return nil
 }
 
 ///////////////////////////////////// DEFAULT EVENT HANDLERS /////////////////////////////////////
@@ -179,10 +207,10 @@ func (mm *MetaMask) accountsChanged(_this js.Value, _args []js.Value) interface{
 		mm.AddressEl.SetAttribute("text", map[string]interface{}{"value": mm.address})
 
 		if mm.address == "" {
-			mm.ConnectEl.SetClass("trigger")
+			mm.ConnectEl.ClassList().Add("trigger")
 			mm.ConnectEl.SetAttribute("visible", map[string]interface{}{"var": true})
 		} else {
-			mm.ConnectEl.SetClass("")
+			mm.ConnectEl.ClassList().Remove("trigger")
 			mm.ConnectEl.SetAttribute("visible", map[string]interface{}{"var": false})
 		}
 	}
@@ -207,32 +235,26 @@ func (mm *MetaMask) message(_this js.Value, _args []js.Value) interface{} {
 	return nil
 }
 
-func (mm *MetaMask) handleError(_this js.Value, _args []js.Value) interface{} {
-	tv := _args[0].Get(error__message)
-	if err := web.ValidJSValue(fmt.Sprintf("error.%s", error__message), tv); err != nil {
-		mm.win.Error(fmt.Errorf("[MetaMask] [handleError] [error]: %v", err))
-		return nil
-	}
-	message := tv.String()
-
-	tv = _args[0].Get(error__code)
-	if err := web.ValidJSValue(fmt.Sprintf("error.%s", error__code), tv); err != nil {
-		mm.win.Error(fmt.Errorf("[MetaMask] [handleError] [error]: %v", err))
-		return nil
+func (mm *MetaMask) handleError(_err error) {
+	perr, ok := _err.(*web.PromiseError)
+	if !ok {
+		mm.win.Error(fmt.Errorf("[MetaMask] [handleError] [error]: %v", _err))
+		return
 	}
-	code := tv.Int()
 
-	switch code {
-	case 4001: // The request was rejected by the user
-		mm.ConnectEl.SetClass("trigger")
+	reason := "request failed"
+	switch perr.Code {
+	case "4001": // The request was rejected by the user
+		reason = "rejected by user"
+		mm.ConnectEl.ClassList().Add("trigger")
 		mm.ConnectEl.Emit("genRelease", map[string]interface{}{}, false)
-	case 32602: // The parameters were invalid
-	case 32603: // Internal error
+	case "-32602": // The parameters were invalid
+		reason = "invalid params"
+	case "-32603": // Internal error
+		reason = "internal error"
 	}
 
-	mm.win.Error(fmt.Errorf("[MetaMask] [handleError] - {%d} %s", code, message))
-
-	return nil
+	mm.win.Error(fmt.Errorf("[MetaMask] [handleError] %s - {%s} %s", reason, perr.Code, perr.Message))
 }
 
 // func (mm *MetaMask) SetCallback(_pe ProviderEvent, _f js.Func) error {
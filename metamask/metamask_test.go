@@ -0,0 +1,120 @@
+//+build tinygo wasm,js
+
+package metamask_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeptotenshi/wasmGo/metamask"
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+func newMetaMask(_h *webtest.Harness) *metamask.MetaMask {
+	win := web.NewWindow()
+	mm := metamask.NewMetaMask(win)
+	mm.ConnectEl = web.NewElement(_h.AddElement("a-entity", "connect"))
+	mm.AddressEl = web.NewElement(_h.AddElement("a-entity", "address"))
+	return mm
+}
+
+func TestMetaMaskInit(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	newMetaMask(h).Init()
+
+	events := []string{}
+	for _, c := range h.CallsTo(webtest.TargetEthereum, "on") {
+		events = append(events, c.Arg(0).String())
+	}
+	sort.Strings(events)
+	want := []string{"accountsChanged", "chainChanged", "connect", "disconnect", "message"}
+	if len(events) != len(want) {
+		t.Fatalf("ethereum.on registered %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("ethereum.on registered %v, want %v", events, want)
+			break
+		}
+	}
+}
+
+func TestMetaMaskAccountsChanged(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	mm := newMetaMask(h)
+	mm.Init()
+
+	h.EmitEthereum("accountsChanged", []interface{}{"0xabc"})
+	if v, _ := mm.AddressEl.GetAttribute("text"); v.Get("value").String() != "0xabc" {
+		t.Errorf("address text = %v, want 0xabc", v)
+	}
+	if v, _ := mm.ConnectEl.GetAttribute("visible"); v.Bool() {
+		t.Error("connect button visible while connected")
+	}
+
+	h.EmitEthereum("accountsChanged", []interface{}{})
+	if v, _ := mm.ConnectEl.GetAttribute("visible"); !v.Bool() {
+		t.Error("connect button hidden after the account was removed")
+	}
+	if !mm.ConnectEl.ClassList().Contains("trigger") {
+		t.Error("connect button lost its trigger class")
+	}
+}
+
+func TestMetaMaskRequest(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	mm := newMetaMask(h)
+
+	var accounts []string
+	if err := mm.Request(context.Background(), "eth_accounts", nil, &accounts); err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0] != h.Accounts[0] {
+		t.Errorf("eth_accounts = %v, want %v", accounts, h.Accounts)
+	}
+
+	calls := h.CallsTo(webtest.TargetEthereum, "request")
+	if len(calls) != 1 || calls[0].Arg(0).Get("method").String() != "eth_accounts" {
+		t.Errorf("ethereum.request calls = %v", calls)
+	}
+}
+
+func TestMetaMaskHandleError(t *testing.T) {
+	for _, c := range []struct {
+		code int
+		want string
+	}{
+		{4001, "[MetaMask] [handleError] rejected by user - {4001} webtest: 4001"},
+		{-32602, "[MetaMask] [handleError] invalid params - {-32602} webtest: -32602"},
+		{-32603, "[MetaMask] [handleError] internal error - {-32603} webtest: -32603"},
+		{-32000, "[MetaMask] [handleError] request failed - {-32000} webtest: -32000"},
+	} {
+		h := webtest.Install()
+		h.RequestError = c.code
+
+		mm := newMetaMask(h)
+		mm.EnableEthereum()
+
+		// the rejection is handled on a later microtask
+		var errs []string
+		for i := 0; i < 100 && len(errs) == 0; i++ {
+			time.Sleep(time.Millisecond)
+			errs = h.Logged("error")
+		}
+		h.Uninstall()
+
+		if len(errs) != 1 || !strings.HasSuffix(errs[0], c.want) {
+			t.Errorf("code %d: logged %q, want %q", c.code, errs, c.want)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	element__dataset = "dataset"
+
+	// element__cacheID is the hidden property linking a DOM node to its Go
+	// side cache, so every *Element wrapping the node shares it.
+	element__cacheID = "__gowebCache"
+
+	weakRef__constructor              = "WeakRef"
+	finalizationRegistry__constructor = "FinalizationRegistry"
+
+	function__deref      = "deref"
+	function__register   = "register"
+	function__unregister = "unregister"
+
+	// CACHE__any skips the type check.
+	CACHE__any = "any"
+)
+
+var (
+	GOWEB_ERROR_CACHE_MISS = errors.New("no cached value")
+	GOWEB_ERROR_CACHE_TYPE = errors.New("cached value type mismatch")
+
+	elementCaches  = map[int]*ElementCache{}
+	elementCacheMu sync.Mutex
+	elementCacheID int
+
+	// elementCacheRegistry drops the cache of a node once the node is
+	// garbage collected; see ElementCache.
+	elementCacheRegistry     js.Value
+	elementCacheRegistryOnce sync.Once
+)
+
+// CacheOptions tune one cached value.
+type CacheOptions struct {
+	// TTL drops the value after this long; zero keeps it until deleted.
+	TTL time.Duration
+	// Mirror also writes the value to the element's data-* attribute
+	// (strings as is, everything else as JSON), so CSS selectors and
+	// A-Frame components can see it.
+	Mirror bool
+}
+
+// CacheChange is passed to OnChange funcs. New is nil when the value was
+// deleted or expired.
+type CacheChange struct {
+	Name    string
+	Old     interface{}
+	New     interface{}
+	Expired bool
+}
+
+type cacheEntry struct {
+	typ     string
+	value   interface{}
+	expires time.Time
+	mirror  bool
+	cancel  func()
+}
+
+// ElementCache is a typed per-element store kept on the Go side. Each name
+// is bound to a type on first store: a type name as printed by %T ("int",
+// "[]string", "*web.Element"), a kind ("struct", "map", "slice", ...) or
+// CACHE__any. Later stores under the name must match it.
+//
+//	el.StoreCacheValue("hp", "int", 100)
+//	el.Cache().OnChange("hp", func(_c web.CacheChange) { bar.SetStyle("width", fmt.Sprintf("%d%%", _c.New)) })
+//	var hp int
+//	el.Cache().Load("hp", &hp)
+//
+// The cache only holds a weak reference to its node and is dropped, without
+// OnChange calls, once the node is garbage collected, however it left the
+// document (Element.Remove, vdom, innerHTML or A-Frame). Where WeakRef or
+// FinalizationRegistry is missing it keeps the node alive instead: call
+// Clear when the node is discarded other than through Element.Remove.
+type ElementCache struct {
+	id    int
+	name  string
+	node  js.Value
+	weak  bool
+	mu    sync.Mutex
+	vals  map[string]*cacheEntry
+	subs  map[string]map[int]func(CacheChange)
+	subID int
+}
+
+// Cache returns the element's cache, creating it on first use.
+func (elem *Element) Cache() *ElementCache {
+	if c := cacheOf(elem.Value); c != nil {
+		return c
+	}
+
+	elementCacheMu.Lock()
+	defer elementCacheMu.Unlock()
+	elementCacheID++
+	c := &ElementCache{
+		id:   elementCacheID,
+		name: elem.String(),
+		node: elem.Value,
+		vals: map[string]*cacheEntry{},
+		subs: map[string]map[int]func(CacheChange){},
+	}
+	if ValidJSValue(c.name, elem.Value) == nil {
+		elem.Value.Set(element__cacheID, c.id)
+		elementCaches[c.id] = c
+		if reg := cacheRegistry(); reg.Truthy() {
+			if ref, err := newJS(js.Global().Get(weakRef__constructor), elem.Value); err == nil {
+				c.node, c.weak = ref, true
+				reg.Call(function__register, elem.Value, c.id, ref)
+			}
+		}
+	}
+	return c
+}
+
+// cacheRegistry returns the FinalizationRegistry for element caches, or
+// undefined when the runtime has no WeakRef or FinalizationRegistry.
+func cacheRegistry() js.Value {
+	elementCacheRegistryOnce.Do(func() {
+		elementCacheRegistry = js.Undefined()
+		ctor := js.Global().Get(finalizationRegistry__constructor)
+		if ValidJSValue(finalizationRegistry__constructor, ctor) != nil ||
+			ValidJSValue(weakRef__constructor, js.Global().Get(weakRef__constructor)) != nil {
+			return
+		}
+		// lives as long as the program, like the registry
+		collected := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+			if id := firstArg(_args); id.Type() == js.TypeNumber {
+				go collectCache(id.Int())
+			}
+			return nil
+		})
+		if reg, err := newJS(ctor, collected); err == nil {
+			elementCacheRegistry = reg
+		}
+	})
+	return elementCacheRegistry
+}
+
+// collectCache forgets the cache of a collected node and stops its timers.
+func collectCache(_id int) {
+	elementCacheMu.Lock()
+	c := elementCaches[_id]
+	delete(elementCaches, _id)
+	elementCacheMu.Unlock()
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	for _, e := range c.vals {
+		if e.cancel != nil {
+			e.cancel()
+		}
+	}
+	c.vals = map[string]*cacheEntry{}
+	c.subs = map[string]map[int]func(CacheChange){}
+	c.mu.Unlock()
+}
+
+// cacheOf returns the cache linked to _v, or nil without creating one.
+func cacheOf(_v js.Value) *ElementCache {
+	if ValidJSValue("element", _v) != nil {
+		return nil
+	}
+	id := _v.Get(element__cacheID)
+	if id.Type() != js.TypeNumber {
+		return nil
+	}
+	elementCacheMu.Lock()
+	defer elementCacheMu.Unlock()
+	return elementCaches[id.Int()]
+}
+
+// StoreCacheValue stores _val under _name, checked against _type (see
+// ElementCache).
+func (elem *Element) StoreCacheValue(_name, _type string, _val interface{}) error {
+	if err := elem.Cache().Set(_name, _type, _val); err != nil {
+		return fmt.Errorf("%s [StoreCacheValue] [error]: %v", elem, err)
+	}
+	return nil
+}
+
+// GetCacheValue returns the value under _name, or GOWEB_ERROR_CACHE_MISS
+// when there is none or it expired.
+func (elem *Element) GetCacheValue(_name string) (interface{}, error) {
+	v, err := elem.Cache().Get(_name)
+	if err != nil {
+		return nil, fmt.Errorf("%s [GetCacheValue] [error]: %v", elem, err)
+	}
+	return v, nil
+}
+
+// Set stores _val under _name.
+func (c *ElementCache) Set(_name, _type string, _val interface{}, _opts ...CacheOptions) error {
+	var o CacheOptions
+	if len(_opts) > 0 {
+		o = _opts[0]
+	}
+	if _type == "" {
+		_type = CACHE__any
+	}
+	if !typeMatches(_type, _val) {
+		return fmt.Errorf("[cache] [%s] [error]: %v: %T is not %s", _name, GOWEB_ERROR_CACHE_TYPE, _val, _type)
+	}
+
+	c.mu.Lock()
+	e, ok := c.vals[_name]
+	var old interface{}
+	stale := ok && e.mirror && !o.Mirror
+	if ok && !e.expired() {
+		if e.typ != _type {
+			c.mu.Unlock()
+			return fmt.Errorf("[cache] [%s] [error]: %v: stored as %s, not %s", _name, GOWEB_ERROR_CACHE_TYPE, e.typ, _type)
+		}
+		old = e.value
+	}
+	if ok && e.cancel != nil {
+		e.cancel()
+	}
+	e = &cacheEntry{typ: _type, value: _val, mirror: o.Mirror}
+	if o.TTL > 0 {
+		e.expires = time.Now().Add(o.TTL)
+		e.cancel = SetTimeout(o.TTL, func() { c.expire(_name, e) })
+	}
+	c.vals[_name] = e
+	subs := c.subscribers(_name)
+	c.mu.Unlock()
+
+	if o.Mirror {
+		c.mirror(_name, _val)
+	} else if stale {
+		c.unmirror(_name)
+	}
+	notify(subs, CacheChange{Name: _name, Old: old, New: _val})
+	return nil
+}
+
+// Get ...
+func (c *ElementCache) Get(_name string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.vals[_name]
+	if !ok || e.expired() {
+		return nil, fmt.Errorf("[cache] [%s] [error]: %v", _name, GOWEB_ERROR_CACHE_MISS)
+	}
+	return e.value, nil
+}
+
+// Load copies the value under _name into the variable _dst points to,
+// failing when the value is not assignable to it.
+func (c *ElementCache) Load(_name string, _dst interface{}) error {
+	v, err := c.Get(_name)
+	if err != nil {
+		return err
+	}
+	dv := reflect.ValueOf(_dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("[cache] [%s] [error]: %v", _name, GOWEB_ERROR_UNMARSHAL_TARGET)
+	}
+	if v == nil {
+		dv.Elem().Set(reflect.Zero(dv.Elem().Type()))
+		return nil
+	}
+	vv := reflect.ValueOf(v)
+	if !vv.Type().AssignableTo(dv.Elem().Type()) {
+		return fmt.Errorf("[cache] [%s] [error]: %v: %T into %s", _name, GOWEB_ERROR_CACHE_TYPE, v, dv.Elem().Type())
+	}
+	dv.Elem().Set(vv)
+	return nil
+}
+
+// Has ...
+func (c *ElementCache) Has(_name string) bool {
+	_, err := c.Get(_name)
+	return err == nil
+}
+
+// Keys returns the live names, sorted.
+func (c *ElementCache) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := []string{}
+	for k, e := range c.vals {
+		if !e.expired() {
+			r = append(r, k)
+		}
+	}
+	sort.Strings(r)
+	return r
+}
+
+// Delete removes _name (and its data-* mirror).
+func (c *ElementCache) Delete(_name string) {
+	c.mu.Lock()
+	e, ok := c.vals[_name]
+	if !ok {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.vals, _name)
+	if e.cancel != nil {
+		e.cancel()
+	}
+	subs := c.subscribers(_name)
+	c.mu.Unlock()
+
+	if e.mirror {
+		c.unmirror(_name)
+	}
+	notify(subs, CacheChange{Name: _name, Old: e.value})
+}
+
+// Clear deletes every value and callback and unlinks the cache from the
+// element right away; Element.Remove calls it.
+func (c *ElementCache) Clear() {
+	c.mu.Lock()
+	names := make([]string, 0, len(c.vals))
+	for k := range c.vals {
+		names = append(names, k)
+	}
+	c.mu.Unlock()
+	for _, k := range names {
+		c.Delete(k)
+	}
+	c.mu.Lock()
+	c.subs = map[string]map[int]func(CacheChange){}
+	c.mu.Unlock()
+
+	elementCacheMu.Lock()
+	delete(elementCaches, c.id)
+	elementCacheMu.Unlock()
+	if c.weak {
+		cacheRegistry().Call(function__unregister, c.node)
+	}
+	if v := c.value(); ValidJSValue(c.name, v) == nil {
+		v.Delete(element__cacheID)
+	}
+}
+
+// OnChange calls _fn after _name is stored, deleted or expires. The returned
+// func removes it.
+func (c *ElementCache) OnChange(_name string, _fn func(CacheChange)) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subID++
+	id := c.subID
+	if c.subs[_name] == nil {
+		c.subs[_name] = map[int]func(CacheChange){}
+	}
+	c.subs[_name][id] = _fn
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subs[_name], id)
+	}
+}
+
+func (c *ElementCache) expire(_name string, _e *cacheEntry) {
+	c.mu.Lock()
+	if c.vals[_name] != _e {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.vals, _name)
+	subs := c.subscribers(_name)
+	c.mu.Unlock()
+
+	if _e.mirror {
+		c.unmirror(_name)
+	}
+	notify(subs, CacheChange{Name: _name, Old: _e.value, Expired: true})
+}
+
+// subscribers must be called with mu held.
+func (c *ElementCache) subscribers(_name string) []func(CacheChange) {
+	ids := make([]int, 0, len(c.subs[_name]))
+	for id := range c.subs[_name] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	r := make([]func(CacheChange), len(ids))
+	for i, id := range ids {
+		r[i] = c.subs[_name][id]
+	}
+	return r
+}
+
+func (c *ElementCache) mirror(_name string, _val interface{}) {
+	ds := c.dataset()
+	if ValidJSValue(element__dataset, ds) != nil {
+		return
+	}
+	s, ok := _val.(string)
+	if !ok {
+		b, err := json.Marshal(_val)
+		if err != nil {
+			return
+		}
+		s = string(b)
+	}
+	ds.Set(datasetKey(_name), s)
+}
+
+func (c *ElementCache) unmirror(_name string) {
+	if ds := c.dataset(); ValidJSValue(element__dataset, ds) == nil {
+		ds.Delete(datasetKey(_name))
+	}
+}
+
+func (c *ElementCache) dataset() js.Value {
+	v := c.value()
+	if ValidJSValue(c.name, v) != nil {
+		return js.Undefined()
+	}
+	return v.Get(element__dataset)
+}
+
+// value returns the node, or undefined once it was collected.
+func (c *ElementCache) value() js.Value {
+	if c.weak {
+		return c.node.Call(function__deref)
+	}
+	return c.node
+}
+
+func (e *cacheEntry) expired() bool {
+	return !e.expires.IsZero() && !time.Now().Before(e.expires)
+}
+
+func notify(_subs []func(CacheChange), _c CacheChange) {
+	for _, fn := range _subs {
+		fn(_c)
+	}
+}
+
+func typeMatches(_type string, _val interface{}) bool {
+	if _type == CACHE__any {
+		return true
+	}
+	if _val == nil {
+		switch _type {
+		case "ptr", "map", "slice", "interface", "func", "chan":
+			return true
+		}
+		return strings.HasPrefix(_type, "*") || strings.HasPrefix(_type, "[]") || strings.HasPrefix(_type, "map[")
+	}
+	t := reflect.TypeOf(_val)
+	return t.String() == _type || t.Kind().String() == _type
+}
+
+// datasetKey converts a data-* attribute style name ("last-hit") to its
+// dataset property ("lastHit").
+func datasetKey(_name string) string {
+	parts := strings.Split(_name, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,252 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+)
+
+const (
+	tag__canvas = "canvas"
+
+	offscreenCanvas__constructor = "OffscreenCanvas"
+	image__constructor           = "Image"
+
+	canvas__width  = "width"
+	canvas__height = "height"
+
+	function__getContext                 = "getContext"
+	function__toDataURL                  = "toDataURL"
+	function__toBlob                     = "toBlob"
+	function__convertToBlob              = "convertToBlob"
+	function__transferControlToOffscreen = "transferControlToOffscreen"
+	function__decode                     = "decode"
+
+	context__2d = "2d"
+
+	image__src         = "src"
+	image__crossOrigin = "crossOrigin"
+
+	MIME__png  = "image/png"
+	MIME__jpeg = "image/jpeg"
+	MIME__webp = "image/webp"
+)
+
+// Canvas is a <canvas> element or, in a worker, an OffscreenCanvas. Value
+// works as an image source for Context2D.DrawImage and as a texture source
+// (see aframe.NewCanvasTexture).
+type Canvas struct {
+	Value     js.Value
+	Offscreen bool
+}
+
+// NewCanvas creates a detached <canvas>; append Element() to show it.
+func (w *Window) NewCanvas(_width, _height int) *Canvas {
+	c := &Canvas{Value: w.NewElementWithTag(tag__canvas).Value}
+	if ValidJSValue(tag__canvas, c.Value) == nil {
+		c.SetSize(_width, _height)
+	}
+	return c
+}
+
+// NewCanvasFrom wraps an existing <canvas> or OffscreenCanvas.
+func NewCanvasFrom(_v js.Value) *Canvas {
+	off := js.Global().Get(offscreenCanvas__constructor)
+	return &Canvas{
+		Value:     _v,
+		Offscreen: off.Type() == js.TypeFunction && _v.InstanceOf(off),
+	}
+}
+
+// NewOffscreenCanvas creates a canvas that is never shown, usable inside a
+// worker. It returns an error wrapping GOWEB_ERROR_UNSUPPORTED where
+// OffscreenCanvas is missing.
+func NewOffscreenCanvas(_width, _height int) (*Canvas, error) {
+	ctor := js.Global().Get(offscreenCanvas__constructor)
+	if ctor.Type() != js.TypeFunction {
+		return nil, fmt.Errorf("[canvas] [NewOffscreenCanvas] [error]: %w", GOWEB_ERROR_UNSUPPORTED)
+	}
+	v, err := newJS(ctor, _width, _height)
+	if err != nil {
+		return nil, fmt.Errorf("[canvas] [NewOffscreenCanvas] [error]: %v", err)
+	}
+	return &Canvas{Value: v, Offscreen: true}, nil
+}
+
+// String ...
+func (c *Canvas) String() string {
+	if c.Offscreen {
+		return fmt.Sprintf("[offscreen]canvas[%dx%d]", c.Width(), c.Height())
+	}
+	id := ""
+	if v := c.Value.Get(ELEMENT__id); v.Type() == js.TypeString {
+		id = v.String()
+	}
+	return fmt.Sprintf("[%s]canvas[%dx%d]", id, c.Width(), c.Height())
+}
+
+// Element is nil for an OffscreenCanvas.
+func (c *Canvas) Element() *Element {
+	if c.Offscreen {
+		return nil
+	}
+	return NewElement(c.Value)
+}
+
+// Width ...
+func (c *Canvas) Width() int {
+	if ValidJSValue(tag__canvas, c.Value) != nil {
+		return 0
+	}
+	return c.Value.Get(canvas__width).Int()
+}
+
+// Height ...
+func (c *Canvas) Height() int {
+	if ValidJSValue(tag__canvas, c.Value) != nil {
+		return 0
+	}
+	return c.Value.Get(canvas__height).Int()
+}
+
+// SetSize resizes the drawing buffer, which clears it. Textures work best
+// with power of two sizes.
+func (c *Canvas) SetSize(_width, _height int) {
+	c.Value.Set(canvas__width, _width)
+	c.Value.Set(canvas__height, _height)
+}
+
+// Context2DOptions are the getContext("2d") options.
+type Context2DOptions struct {
+	// Opaque drops the alpha channel, which is faster to composite.
+	Opaque bool
+	// WillReadFrequently keeps the canvas on the CPU for frequent
+	// GetImageData.
+	WillReadFrequently bool
+}
+
+// Context2D returns the canvas' 2D context; a canvas has only one context
+// and the same one is returned every time.
+func (c *Canvas) Context2D(_opts ...Context2DOptions) (*Context2D, error) {
+	if err := ValidJSValue(tag__canvas, c.Value); err != nil {
+		return nil, fmt.Errorf("%s [Context2D] [error]: %v", c, err)
+	}
+	var o Context2DOptions
+	if len(_opts) > 0 {
+		o = _opts[0]
+	}
+	v, err := callJS(c.Value, function__getContext, context__2d, map[string]interface{}{
+		"alpha":              !o.Opaque,
+		"willReadFrequently": o.WillReadFrequently,
+	})
+	if err == nil {
+		err = ValidJSValue(context__2d, v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s [Context2D] [error]: %v", c, err)
+	}
+	return &Context2D{Value: v, Canvas: c}, nil
+}
+
+// ToDataURL encodes the canvas as a data: URL. _quality (0 to 1) applies to
+// MIME__jpeg and MIME__webp. An OffscreenCanvas has no data URLs; use ToBlob.
+func (c *Canvas) ToDataURL(_mime string, _quality ...float64) (string, error) {
+	if c.Offscreen {
+		return "", fmt.Errorf("%s [ToDataURL] [error]: %w", c, GOWEB_ERROR_UNSUPPORTED)
+	}
+	args := []interface{}{_mime}
+	if len(_quality) > 0 {
+		args = append(args, _quality[0])
+	}
+	// a canvas tainted by a cross origin image throws a SecurityError
+	u, err := callJS(c.Value, function__toDataURL, args...)
+	if err != nil {
+		return "", fmt.Errorf("%s [ToDataURL] [error]: %v", c, err)
+	}
+	return u.String(), nil
+}
+
+// ToBlob encodes the canvas as an image File, ready for Bytes, an object URL
+// or a firebase Storage upload.
+func (c *Canvas) ToBlob(_ctx context.Context, _mime string, _quality ...float64) (*File, error) {
+	if err := ValidJSValue(tag__canvas, c.Value); err != nil {
+		return nil, fmt.Errorf("%s [ToBlob] [error]: %v", c, err)
+	}
+	var blob js.Value
+	var err error
+	if c.Offscreen {
+		opts := map[string]interface{}{file__type: _mime}
+		if len(_quality) > 0 {
+			opts["quality"] = _quality[0]
+		}
+		blob, err = awaitCall(_ctx, c.Value, function__convertToBlob, opts)
+	} else {
+		// toBlob takes a callback; wrap it in a promise to await it
+		p, resolve, _ := deferred()
+		args := []interface{}{resolve, _mime}
+		if len(_quality) > 0 {
+			args = append(args, _quality[0])
+		}
+		if _, err = callJS(c.Value, function__toBlob, args...); err == nil {
+			blob, err = Await(_ctx, p.Value)
+		}
+	}
+	if err == nil && blob.IsNull() {
+		err = fmt.Errorf("empty canvas")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s [ToBlob] [error]: %v", c, err)
+	}
+	return NewFile(blob), nil
+}
+
+// TransferToOffscreen hands drawing over to an OffscreenCanvas that can be
+// posted to a worker with Transfer; the <canvas> then shows what the worker
+// draws. It can only be done once per canvas.
+func (c *Canvas) TransferToOffscreen() (*Canvas, error) {
+	if c.Offscreen || c.Value.Get(function__transferControlToOffscreen).Type() != js.TypeFunction {
+		return nil, fmt.Errorf("%s [TransferToOffscreen] [error]: %w", c, GOWEB_ERROR_UNSUPPORTED)
+	}
+	v, err := callJS(c.Value, function__transferControlToOffscreen)
+	if err != nil {
+		return nil, fmt.Errorf("%s [TransferToOffscreen] [error]: %v", c, err)
+	}
+	return &Canvas{Value: v, Offscreen: true}, nil
+}
+
+// LoadImage loads _src into an <img> and waits until it can be drawn. Set
+// _crossOrigin ("anonymous") for images from another origin that are read
+// back or used as textures.
+func LoadImage(_ctx context.Context, _src string, _crossOrigin ...string) (js.Value, error) {
+	ctor := js.Global().Get(image__constructor)
+	if ctor.Type() != js.TypeFunction {
+		return js.Undefined(), fmt.Errorf("[image] [LoadImage] [error]: %w", GOWEB_ERROR_UNSUPPORTED)
+	}
+	img := ctor.New()
+	if len(_crossOrigin) > 0 {
+		img.Set(image__crossOrigin, _crossOrigin[0])
+	}
+	img.Set(image__src, _src)
+	if _, err := awaitCall(_ctx, img, function__decode); err != nil {
+		return js.Undefined(), fmt.Errorf("[image] [LoadImage] [%s] [error]: %v", _src, err)
+	}
+	return img, nil
+}
+
+// imageSource turns the accepted DrawImage / CreatePattern sources into a
+// js.Value.
+func imageSource(_src interface{}) js.Value {
+	switch s := _src.(type) {
+	case *Canvas:
+		return s.Value
+	case *MediaElement:
+		return s.Value
+	case *Element:
+		return s.Value
+	case js.Value:
+		return s
+	}
+	return js.Undefined()
+}
@@ -0,0 +1,162 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall/js"
+)
+
+const (
+	navigator__clipboard = "clipboard"
+
+	function__writeText   = "writeText"
+	function__readText    = "readText"
+	function__write       = "write"
+	function__getType     = "getType"
+	function__execCommand = "execCommand"
+	function__select      = "select"
+
+	clipboardItem__constructor = "ClipboardItem"
+	clipboardItem__types       = "types"
+
+	PERMISSION__clipboardRead  = "clipboard-read"
+	PERMISSION__clipboardWrite = "clipboard-write"
+)
+
+var GOWEB_ERROR_CLIPBOARD_EMPTY = errors.New("no matching clipboard item")
+
+// Clipboard wraps the async clipboard API. Reading needs a user gesture or the
+// clipboard-read permission, and every method needs a secure context; where
+// the async API is missing WriteText falls back to execCommand("copy").
+type Clipboard struct {
+	win *Window
+}
+
+// Clipboard ...
+func (w *Window) Clipboard() *Clipboard {
+	return &Clipboard{win: w}
+}
+
+// Permission returns the clipboard-read (_read) or clipboard-write state.
+// Firefox does not expose these names; the error then wraps
+// GOWEB_ERROR_UNSUPPORTED and the API may still work on a user gesture.
+func (c *Clipboard) Permission(_ctx context.Context, _read bool) (PermissionState, error) {
+	if _read {
+		return QueryPermission(_ctx, PERMISSION__clipboardRead)
+	}
+	return QueryPermission(_ctx, PERMISSION__clipboardWrite)
+}
+
+// WriteText copies _s.
+func (c *Clipboard) WriteText(_ctx context.Context, _s string) error {
+	cb, err := navigatorGet(navigator__clipboard)
+	if err != nil {
+		if ferr := c.execCopy(_s); ferr != nil {
+			return fmt.Errorf("[clipboard] [WriteText] [error]: %w", ferr)
+		}
+		return nil
+	}
+	if _, err = awaitCall(_ctx, cb, function__writeText, _s); err != nil {
+		return fmt.Errorf("[clipboard] [WriteText] [error]: %v", err)
+	}
+	return nil
+}
+
+// ReadText returns the clipboard's text.
+func (c *Clipboard) ReadText(_ctx context.Context) (string, error) {
+	cb, err := navigatorGet(navigator__clipboard)
+	if err != nil {
+		return "", fmt.Errorf("[clipboard] [ReadText] [error]: %v", err)
+	}
+	v, err := awaitCall(_ctx, cb, function__readText)
+	if err != nil {
+		return "", fmt.Errorf("[clipboard] [ReadText] [error]: %v", err)
+	}
+	return v.String(), nil
+}
+
+// WriteImage copies an encoded image. Browsers only accept "image/png"
+// reliably.
+func (c *Clipboard) WriteImage(_ctx context.Context, _data []byte, _mime string) error {
+	cb, err := navigatorGet(navigator__clipboard)
+	if err != nil {
+		return fmt.Errorf("[clipboard] [WriteImage] [error]: %v", err)
+	}
+	ctor := js.Global().Get(clipboardItem__constructor)
+	if err = ValidJSValue(clipboardItem__constructor, ctor); err != nil {
+		return fmt.Errorf("[clipboard] [WriteImage] [error]: %w: %v", GOWEB_ERROR_UNSUPPORTED, err)
+	}
+	item, err := newJS(ctor, map[string]interface{}{_mime: NewBlob(_data, _mime)})
+	if err != nil {
+		return fmt.Errorf("[clipboard] [WriteImage] [error]: %v", err)
+	}
+	if _, err = awaitCall(_ctx, cb, function__write, []interface{}{item}); err != nil {
+		return fmt.Errorf("[clipboard] [WriteImage] [error]: %v", err)
+	}
+	return nil
+}
+
+// ReadImage returns the first image on the clipboard and its MIME type, or an
+// error wrapping GOWEB_ERROR_CLIPBOARD_EMPTY.
+func (c *Clipboard) ReadImage(_ctx context.Context) ([]byte, string, error) {
+	cb, err := navigatorGet(navigator__clipboard)
+	if err != nil {
+		return nil, "", fmt.Errorf("[clipboard] [ReadImage] [error]: %v", err)
+	}
+	items, err := awaitCall(_ctx, cb, function__read)
+	if err != nil {
+		return nil, "", fmt.Errorf("[clipboard] [ReadImage] [error]: %v", err)
+	}
+	for _, item := range jsSlice(items) {
+		for _, t := range jsSlice(item.Get(clipboardItem__types)) {
+			mime := t.String()
+			if !strings.HasPrefix(mime, "image/") {
+				continue
+			}
+			blob, err := awaitCall(_ctx, item, function__getType, mime)
+			if err != nil {
+				return nil, "", fmt.Errorf("[clipboard] [ReadImage] [error]: %v", err)
+			}
+			buf, err := awaitCall(_ctx, blob, function__arrayBuffer)
+			if err != nil {
+				return nil, "", fmt.Errorf("[clipboard] [ReadImage] [error]: %v", err)
+			}
+			return bytesOf(buf), mime, nil
+		}
+	}
+	return nil, "", fmt.Errorf("[clipboard] [ReadImage] [error]: %v", GOWEB_ERROR_CLIPBOARD_EMPTY)
+}
+
+// execCopy is the pre async API way to copy: select a hidden textarea and
+// run the copy command.
+func (c *Clipboard) execCopy(_s string) error {
+	if err := ValidJSValue(document, c.win.document); err != nil {
+		return fmt.Errorf("%w: %v", GOWEB_ERROR_UNSUPPORTED, err)
+	}
+	body := c.win.document.Get(document__body)
+	if err := ValidJSValue(document__body, body); err != nil {
+		return fmt.Errorf("%w: %v", GOWEB_ERROR_UNSUPPORTED, err)
+	}
+	if c.win.document.Get(function__execCommand).Type() != js.TypeFunction {
+		return fmt.Errorf("%w: no %s", GOWEB_ERROR_UNSUPPORTED, function__execCommand)
+	}
+	ta := c.win.NewElementWithTag("textarea")
+	ta.Value.Set(PROPERTY__value, _s)
+	ta.SetStyles(map[string]string{"position": "fixed", "top": "-1000px", "opacity": "0"})
+	body.Call(FUNCTION__appendChild, ta.Value)
+	defer body.Call(function__removeChild, ta.Value)
+
+	ta.Value.Call(function__select)
+	ok, err := callJS(c.win.document, function__execCommand, "copy")
+	if err != nil {
+		return err
+	}
+	if !ok.Truthy() {
+		return fmt.Errorf("%w: copy command refused", GOWEB_ERROR_UNSUPPORTED)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+func TestClipboardExecCopy(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	doc := js.Global().Get("document")
+
+	cb := web.NewWindow().Clipboard()
+	if err := cb.WriteText(context.Background(), "hi"); !errors.Is(err, web.GOWEB_ERROR_UNSUPPORTED) {
+		t.Errorf("WriteText without execCommand = %v, want GOWEB_ERROR_UNSUPPORTED", err)
+	}
+
+	var copied string
+	exec := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		kids := doc.Get("body").Get("children")
+		if _args[0].String() == "copy" && kids.Length() == 1 {
+			copied = kids.Index(0).Get("value").String()
+		}
+		return true
+	})
+	defer exec.Release()
+	doc.Set("execCommand", exec)
+
+	if err := cb.WriteText(context.Background(), "hi"); err != nil || copied != "hi" {
+		t.Errorf("WriteText = %v, copied %q", err, copied)
+	}
+	if n := doc.Get("body").Get("children").Length(); n != 0 {
+		t.Errorf("%d elements left in body after the copy", n)
+	}
+
+	body := doc.Get("body")
+	doc.Set("body", js.Null())
+	defer doc.Set("body", body)
+	if err := cb.WriteText(context.Background(), "hi"); !errors.Is(err, web.GOWEB_ERROR_UNSUPPORTED) {
+		t.Errorf("WriteText without a body = %v, want GOWEB_ERROR_UNSUPPORTED", err)
+	}
+}
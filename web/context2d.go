@@ -0,0 +1,458 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+)
+
+const (
+	ctx2d__fillStyle     = "fillStyle"
+	ctx2d__strokeStyle   = "strokeStyle"
+	ctx2d__lineWidth     = "lineWidth"
+	ctx2d__lineCap       = "lineCap"
+	ctx2d__lineJoin      = "lineJoin"
+	ctx2d__globalAlpha   = "globalAlpha"
+	ctx2d__compositeOp   = "globalCompositeOperation"
+	ctx2d__font          = "font"
+	ctx2d__textAlign     = "textAlign"
+	ctx2d__textBaseline  = "textBaseline"
+	ctx2d__shadowColor   = "shadowColor"
+	ctx2d__shadowBlur    = "shadowBlur"
+	ctx2d__shadowOffsetX = "shadowOffsetX"
+	ctx2d__shadowOffsetY = "shadowOffsetY"
+	ctx2d__smoothing     = "imageSmoothingEnabled"
+
+	function__save                 = "save"
+	function__restore              = "restore"
+	function__translate            = "translate"
+	function__rotate               = "rotate"
+	function__scale                = "scale"
+	function__setTransform         = "setTransform"
+	function__fillRect             = "fillRect"
+	function__strokeRect           = "strokeRect"
+	function__clearRect            = "clearRect"
+	function__beginPath            = "beginPath"
+	function__closePath            = "closePath"
+	function__moveTo               = "moveTo"
+	function__lineTo               = "lineTo"
+	function__arc                  = "arc"
+	function__arcTo                = "arcTo"
+	function__ellipse              = "ellipse"
+	function__rect                 = "rect"
+	function__roundRect            = "roundRect"
+	function__quadraticCurveTo     = "quadraticCurveTo"
+	function__bezierCurveTo        = "bezierCurveTo"
+	function__fill                 = "fill"
+	function__stroke               = "stroke"
+	function__clip                 = "clip"
+	function__fillText             = "fillText"
+	function__strokeText           = "strokeText"
+	function__measureText          = "measureText"
+	function__drawImage            = "drawImage"
+	function__createLinearGradient = "createLinearGradient"
+	function__createRadialGradient = "createRadialGradient"
+	function__createPattern        = "createPattern"
+	function__addColorStop         = "addColorStop"
+	function__getImageData         = "getImageData"
+	function__putImageData         = "putImageData"
+	function__createImageData      = "createImageData"
+	function__setLineDash          = "setLineDash"
+
+	metrics__width   = "width"
+	metrics__ascent  = "actualBoundingBoxAscent"
+	metrics__descent = "actualBoundingBoxDescent"
+
+	imageData__data   = "data"
+	imageData__width  = "width"
+	imageData__height = "height"
+
+	ALIGN__left   = "left"
+	ALIGN__center = "center"
+	ALIGN__right  = "right"
+
+	BASELINE__top        = "top"
+	BASELINE__middle     = "middle"
+	BASELINE__alphabetic = "alphabetic"
+	BASELINE__bottom     = "bottom"
+
+	REPEAT__both = "repeat"
+	REPEAT__x    = "repeat-x"
+	REPEAT__y    = "repeat-y"
+	REPEAT__none = "no-repeat"
+)
+
+// Context2D is a CanvasRenderingContext2D. Setters return the context so
+// state can be chained:
+//
+//	ctx.SetFillStyle("#fff").SetFont("bold 32px sans-serif").SetTextAlign(web.ALIGN__center)
+//	ctx.FillText("Player 1", 128, 40)
+type Context2D struct {
+	Value  js.Value
+	Canvas *Canvas
+}
+
+// Gradient is a CanvasGradient, usable as a fill or stroke style.
+type Gradient struct {
+	Value js.Value
+}
+
+// AddStop adds _color at _offset (0 to 1).
+func (g *Gradient) AddStop(_offset float64, _color string) *Gradient {
+	g.Value.Call(function__addColorStop, _offset, _color)
+	return g
+}
+
+// Pattern is a CanvasPattern, usable as a fill or stroke style.
+type Pattern struct {
+	Value js.Value
+}
+
+// TextMetrics ...
+type TextMetrics struct {
+	Width   float64
+	Ascent  float64
+	Descent float64
+}
+
+// styleValue accepts a CSS color string, *Gradient or *Pattern.
+func styleValue(_s interface{}) interface{} {
+	switch s := _s.(type) {
+	case *Gradient:
+		return s.Value
+	case *Pattern:
+		return s.Value
+	}
+	return _s
+}
+
+// Save pushes the styles, transform and clip.
+func (c *Context2D) Save() { c.Value.Call(function__save) }
+
+// Restore pops what Save pushed.
+func (c *Context2D) Restore() { c.Value.Call(function__restore) }
+
+// Translate ...
+func (c *Context2D) Translate(_x, _y float64) { c.Value.Call(function__translate, _x, _y) }
+
+// Rotate takes degrees, like aframe rotations.
+func (c *Context2D) Rotate(_deg float64) { c.Value.Call(function__rotate, _deg*math.Pi/180) }
+
+// Scale ...
+func (c *Context2D) Scale(_x, _y float64) { c.Value.Call(function__scale, _x, _y) }
+
+// SetTransform replaces the transform with the matrix [a c e; b d f].
+func (c *Context2D) SetTransform(_a, _b, _c, _d, _e, _f float64) {
+	c.Value.Call(function__setTransform, _a, _b, _c, _d, _e, _f)
+}
+
+// ResetTransform ...
+func (c *Context2D) ResetTransform() { c.Value.Call(function__setTransform, 1, 0, 0, 1, 0, 0) }
+
+// SetFillStyle takes a CSS color, *Gradient or *Pattern.
+func (c *Context2D) SetFillStyle(_s interface{}) *Context2D {
+	c.Value.Set(ctx2d__fillStyle, styleValue(_s))
+	return c
+}
+
+// SetStrokeStyle takes a CSS color, *Gradient or *Pattern.
+func (c *Context2D) SetStrokeStyle(_s interface{}) *Context2D {
+	c.Value.Set(ctx2d__strokeStyle, styleValue(_s))
+	return c
+}
+
+// SetLineWidth ...
+func (c *Context2D) SetLineWidth(_w float64) *Context2D {
+	c.Value.Set(ctx2d__lineWidth, _w)
+	return c
+}
+
+// SetLineCap is "butt", "round" or "square".
+func (c *Context2D) SetLineCap(_cap string) *Context2D {
+	c.Value.Set(ctx2d__lineCap, _cap)
+	return c
+}
+
+// SetLineJoin is "miter", "round" or "bevel".
+func (c *Context2D) SetLineJoin(_join string) *Context2D {
+	c.Value.Set(ctx2d__lineJoin, _join)
+	return c
+}
+
+// SetLineDash sets the dash pattern; none draws solid lines.
+func (c *Context2D) SetLineDash(_segments ...float64) *Context2D {
+	s := make([]interface{}, len(_segments))
+	for i, f := range _segments {
+		s[i] = f
+	}
+	c.Value.Call(function__setLineDash, s)
+	return c
+}
+
+// SetGlobalAlpha ...
+func (c *Context2D) SetGlobalAlpha(_a float64) *Context2D {
+	c.Value.Set(ctx2d__globalAlpha, _a)
+	return c
+}
+
+// SetCompositeOperation takes a globalCompositeOperation like
+// "source-over", "multiply" or "destination-out".
+func (c *Context2D) SetCompositeOperation(_op string) *Context2D {
+	c.Value.Set(ctx2d__compositeOp, _op)
+	return c
+}
+
+// SetFont takes a CSS font, e.g. "bold 24px sans-serif".
+func (c *Context2D) SetFont(_font string) *Context2D {
+	c.Value.Set(ctx2d__font, _font)
+	return c
+}
+
+// SetTextAlign takes one of the ALIGN__ constants.
+func (c *Context2D) SetTextAlign(_align string) *Context2D {
+	c.Value.Set(ctx2d__textAlign, _align)
+	return c
+}
+
+// SetTextBaseline takes one of the BASELINE__ constants.
+func (c *Context2D) SetTextBaseline(_baseline string) *Context2D {
+	c.Value.Set(ctx2d__textBaseline, _baseline)
+	return c
+}
+
+// SetShadow shadows what is drawn next; a transparent _color turns it off.
+func (c *Context2D) SetShadow(_color string, _blur, _offsetX, _offsetY float64) *Context2D {
+	c.Value.Set(ctx2d__shadowColor, _color)
+	c.Value.Set(ctx2d__shadowBlur, _blur)
+	c.Value.Set(ctx2d__shadowOffsetX, _offsetX)
+	c.Value.Set(ctx2d__shadowOffsetY, _offsetY)
+	return c
+}
+
+// SetImageSmoothing turns scaling interpolation off for pixel art.
+func (c *Context2D) SetImageSmoothing(_on bool) *Context2D {
+	c.Value.Set(ctx2d__smoothing, _on)
+	return c
+}
+
+// FillRect ...
+func (c *Context2D) FillRect(_x, _y, _w, _h float64) {
+	c.Value.Call(function__fillRect, _x, _y, _w, _h)
+}
+
+// StrokeRect ...
+func (c *Context2D) StrokeRect(_x, _y, _w, _h float64) {
+	c.Value.Call(function__strokeRect, _x, _y, _w, _h)
+}
+
+// ClearRect ...
+func (c *Context2D) ClearRect(_x, _y, _w, _h float64) {
+	c.Value.Call(function__clearRect, _x, _y, _w, _h)
+}
+
+// Clear clears the whole canvas, whatever the transform.
+func (c *Context2D) Clear() {
+	c.Save()
+	c.ResetTransform()
+	c.ClearRect(0, 0, float64(c.Canvas.Width()), float64(c.Canvas.Height()))
+	c.Restore()
+}
+
+// BeginPath ...
+func (c *Context2D) BeginPath() { c.Value.Call(function__beginPath) }
+
+// ClosePath ...
+func (c *Context2D) ClosePath() { c.Value.Call(function__closePath) }
+
+// MoveTo ...
+func (c *Context2D) MoveTo(_x, _y float64) { c.Value.Call(function__moveTo, _x, _y) }
+
+// LineTo ...
+func (c *Context2D) LineTo(_x, _y float64) { c.Value.Call(function__lineTo, _x, _y) }
+
+// Arc takes its angles in degrees, clockwise from the x axis.
+func (c *Context2D) Arc(_x, _y, _r, _startDeg, _endDeg float64) {
+	c.Value.Call(function__arc, _x, _y, _r, _startDeg*math.Pi/180, _endDeg*math.Pi/180)
+}
+
+// Circle is a full Arc.
+func (c *Context2D) Circle(_x, _y, _r float64) {
+	c.MoveTo(_x+_r, _y)
+	c.Value.Call(function__arc, _x, _y, _r, 0, 2*math.Pi)
+}
+
+// ArcTo ...
+func (c *Context2D) ArcTo(_x1, _y1, _x2, _y2, _r float64) {
+	c.Value.Call(function__arcTo, _x1, _y1, _x2, _y2, _r)
+}
+
+// Ellipse takes its rotation in degrees and draws it whole.
+func (c *Context2D) Ellipse(_x, _y, _rx, _ry, _rotDeg float64) {
+	c.Value.Call(function__ellipse, _x, _y, _rx, _ry, _rotDeg*math.Pi/180, 0, 2*math.Pi)
+}
+
+// Rect ...
+func (c *Context2D) Rect(_x, _y, _w, _h float64) { c.Value.Call(function__rect, _x, _y, _w, _h) }
+
+// RoundRect adds a rectangle with corners of radius _r, drawn with arcs where
+// the browser has no roundRect.
+func (c *Context2D) RoundRect(_x, _y, _w, _h, _r float64) {
+	if c.Value.Get(function__roundRect).Type() == js.TypeFunction {
+		c.Value.Call(function__roundRect, _x, _y, _w, _h, _r)
+		return
+	}
+	c.MoveTo(_x+_r, _y)
+	c.ArcTo(_x+_w, _y, _x+_w, _y+_h, _r)
+	c.ArcTo(_x+_w, _y+_h, _x, _y+_h, _r)
+	c.ArcTo(_x, _y+_h, _x, _y, _r)
+	c.ArcTo(_x, _y, _x+_w, _y, _r)
+	c.ClosePath()
+}
+
+// QuadraticCurveTo ...
+func (c *Context2D) QuadraticCurveTo(_cx, _cy, _x, _y float64) {
+	c.Value.Call(function__quadraticCurveTo, _cx, _cy, _x, _y)
+}
+
+// BezierCurveTo ...
+func (c *Context2D) BezierCurveTo(_c1x, _c1y, _c2x, _c2y, _x, _y float64) {
+	c.Value.Call(function__bezierCurveTo, _c1x, _c1y, _c2x, _c2y, _x, _y)
+}
+
+// Fill fills the current path.
+func (c *Context2D) Fill() { c.Value.Call(function__fill) }
+
+// Stroke strokes the current path.
+func (c *Context2D) Stroke() { c.Value.Call(function__stroke) }
+
+// Clip restricts drawing to the current path until Restore.
+func (c *Context2D) Clip() { c.Value.Call(function__clip) }
+
+// FillText ...
+func (c *Context2D) FillText(_text string, _x, _y float64) {
+	c.Value.Call(function__fillText, _text, _x, _y)
+}
+
+// StrokeText ...
+func (c *Context2D) StrokeText(_text string, _x, _y float64) {
+	c.Value.Call(function__strokeText, _text, _x, _y)
+}
+
+// MeasureText measures _text in the current font.
+func (c *Context2D) MeasureText(_text string) TextMetrics {
+	m := c.Value.Call(function__measureText, _text)
+	tm := TextMetrics{Width: m.Get(metrics__width).Float()}
+	if a := m.Get(metrics__ascent); a.Type() == js.TypeNumber {
+		tm.Ascent = a.Float()
+	}
+	if d := m.Get(metrics__descent); d.Type() == js.TypeNumber {
+		tm.Descent = d.Float()
+	}
+	return tm
+}
+
+// DrawImage draws _src, a *Canvas, *MediaElement (the current video frame),
+// an <img> *Element or a js.Value image (see LoadImage), at _x, _y in its own
+// size.
+func (c *Context2D) DrawImage(_src interface{}, _x, _y float64) error {
+	if _, err := callJS(c.Value, function__drawImage, imageSource(_src), _x, _y); err != nil {
+		return fmt.Errorf("[canvas] [DrawImage] [error]: %v", err)
+	}
+	return nil
+}
+
+// DrawImageScaled draws _src scaled into _w x _h.
+func (c *Context2D) DrawImageScaled(_src interface{}, _x, _y, _w, _h float64) error {
+	if _, err := callJS(c.Value, function__drawImage, imageSource(_src), _x, _y, _w, _h); err != nil {
+		return fmt.Errorf("[canvas] [DrawImageScaled] [error]: %v", err)
+	}
+	return nil
+}
+
+// DrawImageRegion draws the _sx, _sy, _sw, _sh region of _src into the _dx,
+// _dy, _dw, _dh rectangle, e.g. one sprite of a sheet.
+func (c *Context2D) DrawImageRegion(_src interface{}, _sx, _sy, _sw, _sh, _dx, _dy, _dw, _dh float64) error {
+	if _, err := callJS(c.Value, function__drawImage, imageSource(_src), _sx, _sy, _sw, _sh, _dx, _dy, _dw, _dh); err != nil {
+		return fmt.Errorf("[canvas] [DrawImageRegion] [error]: %v", err)
+	}
+	return nil
+}
+
+// LinearGradient runs from _x0, _y0 to _x1, _y1.
+func (c *Context2D) LinearGradient(_x0, _y0, _x1, _y1 float64) *Gradient {
+	return &Gradient{Value: c.Value.Call(function__createLinearGradient, _x0, _y0, _x1, _y1)}
+}
+
+// RadialGradient runs from the circle _x0, _y0, _r0 to _x1, _y1, _r1.
+func (c *Context2D) RadialGradient(_x0, _y0, _r0, _x1, _y1, _r1 float64) *Gradient {
+	return &Gradient{Value: c.Value.Call(function__createRadialGradient, _x0, _y0, _r0, _x1, _y1, _r1)}
+}
+
+// Pattern repeats _src (see DrawImage) as one of the REPEAT__ constants.
+func (c *Context2D) Pattern(_src interface{}, _repeat string) (*Pattern, error) {
+	v, err := callJS(c.Value, function__createPattern, imageSource(_src), _repeat)
+	if err == nil {
+		err = ValidJSValue(function__createPattern, v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[canvas] [Pattern] [error]: %v", err)
+	}
+	return &Pattern{Value: v}, nil
+}
+
+// ImageData is a copy of canvas pixels: Width*Height RGBA bytes, row by row.
+type ImageData struct {
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// NewImageData returns transparent black pixels.
+func NewImageData(_width, _height int) *ImageData {
+	return &ImageData{Width: _width, Height: _height, Data: make([]byte, _width*_height*4)}
+}
+
+// At returns the RGBA of pixel _x, _y.
+func (d *ImageData) At(_x, _y int) (byte, byte, byte, byte) {
+	i := (_y*d.Width + _x) * 4
+	return d.Data[i], d.Data[i+1], d.Data[i+2], d.Data[i+3]
+}
+
+// Set ...
+func (d *ImageData) Set(_x, _y int, _r, _g, _b, _a byte) {
+	i := (_y*d.Width + _x) * 4
+	d.Data[i], d.Data[i+1], d.Data[i+2], d.Data[i+3] = _r, _g, _b, _a
+}
+
+// GetImageData copies the pixels of a rectangle. A canvas tainted by a cross
+// origin image cannot be read and returns an error.
+func (c *Context2D) GetImageData(_x, _y, _w, _h int) (*ImageData, error) {
+	v, err := callJS(c.Value, function__getImageData, _x, _y, _w, _h)
+	if err != nil {
+		return nil, fmt.Errorf("[canvas] [GetImageData] [error]: %v", err)
+	}
+	return &ImageData{
+		Width:  v.Get(imageData__width).Int(),
+		Height: v.Get(imageData__height).Int(),
+		Data:   bytesOf(v.Get(imageData__data)),
+	}, nil
+}
+
+// PutImageData writes _d at _x, _y, ignoring the transform and alpha settings.
+func (c *Context2D) PutImageData(_d *ImageData, _x, _y int) error {
+	if len(_d.Data) != _d.Width*_d.Height*4 {
+		return fmt.Errorf("[canvas] [PutImageData] [error]: %d bytes for %dx%d pixels", len(_d.Data), _d.Width, _d.Height)
+	}
+	v, err := callJS(c.Value, function__createImageData, _d.Width, _d.Height)
+	if err != nil {
+		return fmt.Errorf("[canvas] [PutImageData] [error]: %v", err)
+	}
+	data := v.Get(imageData__data)
+	js.CopyBytesToJS(js.Global().Get(uint8Array__name).New(data.Get(property__buffer), data.Get(property__byteOffset), data.Get(property__byteLength)), _d.Data)
+	if _, err = callJS(c.Value, function__putImageData, v, _x, _y); err != nil {
+		return fmt.Errorf("[canvas] [PutImageData] [error]: %v", err)
+	}
+	return nil
+}
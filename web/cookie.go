@@ -0,0 +1,291 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall/js"
+	"time"
+)
+
+const (
+	cookie      = "cookie"
+	cookieStore = "cookieStore"
+
+	cookie__name     = "name"
+	cookie__value    = "value"
+	cookie__path     = "path"
+	cookie__domain   = "domain"
+	cookie__expires  = "expires"
+	cookie__secure   = "secure"
+	cookie__sameSite = "sameSite"
+
+	cookieChange__changed = "changed"
+	cookieChange__deleted = "deleted"
+
+	function__getAll = "getAll"
+	function__set    = "set"
+	function__delete = "delete"
+	function__get    = "get"
+
+	EVENT__cookieChange = "change"
+)
+
+// SameSite ...
+type SameSite string
+
+const (
+	SameSiteDefault SameSite = ""
+	SameSiteLax     SameSite = "Lax"
+	SameSiteStrict  SameSite = "Strict"
+	SameSiteNone    SameSite = "None"
+)
+
+// Cookie is a cookie with its attributes. Value is stored URL-encoded and
+// decoded again on read. MaxAge is in seconds: 0 leaves it unset and a
+// negative value expires the cookie immediately.
+type Cookie struct {
+	Name  string
+	Value string
+
+	Path     string
+	Domain   string
+	Expires  time.Time
+	MaxAge   int
+	Secure   bool
+	SameSite SameSite
+}
+
+// String serializes the cookie for a `document.cookie` write.
+func (c *Cookie) String() string {
+	var b strings.Builder
+	b.WriteString(url.PathEscape(c.Name))
+	b.WriteString("=")
+	b.WriteString(url.PathEscape(c.Value))
+
+	if c.Path != "" {
+		fmt.Fprintf(&b, "; Path=%s", c.Path)
+	}
+	if c.Domain != "" {
+		fmt.Fprintf(&b, "; Domain=%s", c.Domain)
+	}
+	if !c.Expires.IsZero() {
+		fmt.Fprintf(&b, "; Expires=%s", c.Expires.UTC().Format(http.TimeFormat))
+	}
+	if c.MaxAge > 0 {
+		fmt.Fprintf(&b, "; Max-Age=%d", c.MaxAge)
+	} else if c.MaxAge < 0 {
+		b.WriteString("; Max-Age=0")
+	}
+	if c.Secure || c.SameSite == SameSiteNone {
+		b.WriteString("; Secure")
+	}
+	if c.SameSite != SameSiteDefault {
+		fmt.Fprintf(&b, "; SameSite=%s", c.SameSite)
+	}
+	return b.String()
+}
+
+// Cookies returns every cookie visible to the document, with decoded values.
+func (w *Window) Cookies() (map[string]string, error) {
+	err := ValidJSValue(document, w.document)
+	if err != nil {
+		return nil, fmt.Errorf("[window] [Cookies] [error]: %v", err)
+	}
+	tv := w.document.Get(cookie)
+	if err = ValidJSValue(cookie, tv); err != nil {
+		return nil, fmt.Errorf("[window] [Cookies] [error]: %v", err)
+	}
+	return parseCookies(tv.String()), nil
+}
+
+// GetCookie returns the decoded value of the named cookie, or "" when it is not
+// set.
+func (w *Window) GetCookie(_name string) (string, error) {
+	cks, err := w.Cookies()
+	if err != nil {
+		return "", fmt.Errorf("[window] [GetCookie] [error]: %v", err)
+	}
+	return cks[_name], nil
+}
+
+// SetCookie writes a session cookie with no attributes.
+func (w *Window) SetCookie(_name, _val string) error {
+	return w.WriteCookie(&Cookie{Name: _name, Value: _val})
+}
+
+// WriteCookie writes the cookie with all of its attributes.
+func (w *Window) WriteCookie(_c *Cookie) error {
+	if err := ValidJSValue(document, w.document); err != nil {
+		return fmt.Errorf("[window] [WriteCookie] [error]: %v", err)
+	}
+	w.document.Set(cookie, _c.String())
+	return nil
+}
+
+// DeleteCookie expires the named cookie. _path and _domain must match the ones
+// it was written with.
+func (w *Window) DeleteCookie(_name, _path, _domain string) error {
+	return w.WriteCookie(&Cookie{
+		Name:    _name,
+		Path:    _path,
+		Domain:  _domain,
+		Expires: time.Unix(0, 0),
+		MaxAge:  -1,
+	})
+}
+
+// parseCookies splits a `document.cookie` string. Values may contain '=';
+// a pair without '=' is a nameless cookie, as browsers treat it. When a name
+// repeats the first one wins: browsers list the most specific path first.
+func parseCookies(_s string) map[string]string {
+	r := map[string]string{}
+	for _, c := range strings.Split(_s, ";") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		name, value := "", c
+		if i := strings.Index(c, "="); i >= 0 {
+			name, value = strings.TrimSpace(c[:i]), strings.TrimSpace(c[i+1:])
+		}
+		name = decodeCookie(name)
+		if _, ok := r[name]; !ok {
+			r[name] = decodeCookie(value)
+		}
+	}
+	return r
+}
+
+func decodeCookie(_s string) string {
+	if d, err := url.PathUnescape(_s); err == nil {
+		return d
+	}
+	return _s
+}
+
+// CookieStore wraps the async Cookie Store API, available in secure contexts
+// in Chromium based browsers.
+type CookieStore struct {
+	value js.Value
+}
+
+// CookieStore returns the async cookie store, or an error when the browser does
+// not support it.
+func (w *Window) CookieStore() (*CookieStore, error) {
+	tv, err := w.GetGlobal(cookieStore)
+	if err != nil {
+		return nil, fmt.Errorf("[window] [CookieStore] [error]: %v", err)
+	}
+	return &CookieStore{value: tv}, nil
+}
+
+// Get returns the named cookie, or nil when it is not set.
+func (cs *CookieStore) Get(_ctx context.Context, _name string) (*Cookie, error) {
+	tv, err := Await(_ctx, cs.value.Call(function__get, url.PathEscape(_name)))
+	if err != nil {
+		return nil, fmt.Errorf("[cookieStore] [Get] [%s] [error]: %v", _name, err)
+	}
+	if ValidJSValue(_name, tv) != nil {
+		return nil, nil
+	}
+	return cookieFromJS(tv), nil
+}
+
+// GetAll ...
+func (cs *CookieStore) GetAll(_ctx context.Context) ([]*Cookie, error) {
+	tv, err := Await(_ctx, cs.value.Call(function__getAll))
+	if err != nil {
+		return nil, fmt.Errorf("[cookieStore] [GetAll] [error]: %v", err)
+	}
+	return cookieList(tv), nil
+}
+
+// Set writes the cookie. MaxAge is converted into an expiry time since the
+// Cookie Store API only takes `expires`.
+func (cs *CookieStore) Set(_ctx context.Context, _c *Cookie) error {
+	opts := map[string]interface{}{
+		cookie__name:  url.PathEscape(_c.Name),
+		cookie__value: url.PathEscape(_c.Value),
+	}
+	if _c.Path != "" {
+		opts[cookie__path] = _c.Path
+	}
+	if _c.Domain != "" {
+		opts[cookie__domain] = _c.Domain
+	}
+	expires := _c.Expires
+	if _c.MaxAge != 0 {
+		expires = time.Now().Add(time.Duration(_c.MaxAge) * time.Second)
+	}
+	if !expires.IsZero() {
+		opts[cookie__expires] = float64(expires.UnixNano()) / float64(time.Millisecond)
+	}
+	if _c.SameSite != SameSiteDefault {
+		opts[cookie__sameSite] = strings.ToLower(string(_c.SameSite))
+	}
+
+	if _, err := Await(_ctx, cs.value.Call(function__set, opts)); err != nil {
+		return fmt.Errorf("[cookieStore] [Set] [%s] [error]: %v", _c.Name, err)
+	}
+	return nil
+}
+
+// Delete ...
+func (cs *CookieStore) Delete(_ctx context.Context, _name string) error {
+	if _, err := Await(_ctx, cs.value.Call(function__delete, url.PathEscape(_name))); err != nil {
+		return fmt.Errorf("[cookieStore] [Delete] [%s] [error]: %v", _name, err)
+	}
+	return nil
+}
+
+// OnChange calls _cb with the changed and deleted cookies whenever the jar
+// changes, from this document or elsewhere. The returned func stops listening.
+func (cs *CookieStore) OnChange(_cb func(_changed, _deleted []*Cookie)) func() {
+	return listen(cs.value, EVENT__cookieChange, func(_e Event) {
+		_cb(cookieList(_e.Value.Get(cookieChange__changed)), cookieList(_e.Value.Get(cookieChange__deleted)))
+	}, ListenerOptions{})
+}
+
+func cookieList(_v js.Value) []*Cookie {
+	r := []*Cookie{}
+	if ValidJSValue("cookies", _v) != nil {
+		return r
+	}
+	for i := 0; i < _v.Length(); i++ {
+		r = append(r, cookieFromJS(_v.Index(i)))
+	}
+	return r
+}
+
+func cookieFromJS(_v js.Value) *Cookie {
+	c := &Cookie{
+		Name: decodeCookie(_v.Get(cookie__name).String()),
+	}
+	if tv := _v.Get(cookie__value); tv.Type() == js.TypeString {
+		c.Value = decodeCookie(tv.String())
+	}
+	if tv := _v.Get(cookie__path); tv.Type() == js.TypeString {
+		c.Path = tv.String()
+	}
+	if tv := _v.Get(cookie__domain); tv.Type() == js.TypeString {
+		c.Domain = tv.String()
+	}
+	if tv := _v.Get(cookie__expires); tv.Type() == js.TypeNumber {
+		c.Expires = msToTime(tv.Float())
+	}
+	c.Secure = _v.Get(cookie__secure).Truthy()
+	switch strings.ToLower(_v.Get(cookie__sameSite).String()) {
+	case "lax":
+		c.SameSite = SameSiteLax
+	case "strict":
+		c.SameSite = SameSiteStrict
+	case "none":
+		c.SameSite = SameSiteNone
+	}
+	return c
+}
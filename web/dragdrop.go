@@ -0,0 +1,219 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"strings"
+	"syscall/js"
+)
+
+const (
+	EVENT__dragstart = "dragstart"
+	EVENT__dragenter = "dragenter"
+	EVENT__dragover  = "dragover"
+	EVENT__dragleave = "dragleave"
+	EVENT__drop      = "drop"
+	EVENT__dragend   = "dragend"
+
+	event__dataTransfer = "dataTransfer"
+
+	dataTransfer__files         = "files"
+	dataTransfer__types         = "types"
+	dataTransfer__dropEffect    = "dropEffect"
+	dataTransfer__effectAllowed = "effectAllowed"
+
+	function__getData = "getData"
+	function__setData = "setData"
+
+	DROP__copy = "copy"
+	DROP__move = "move"
+	DROP__link = "link"
+	DROP__none = "none"
+
+	dataTransfer__filesType = "Files"
+)
+
+// DragEvent is a drag and drop event.
+type DragEvent struct {
+	Event
+}
+
+// Drag ...
+func (e Event) Drag() DragEvent {
+	return DragEvent{Event: e}
+}
+
+func (e DragEvent) dataTransfer() js.Value {
+	return e.Value.Get(event__dataTransfer)
+}
+
+// Files returns the dropped files; they are only readable during drop.
+func (e DragEvent) Files() []*File {
+	dt := e.dataTransfer()
+	if ValidJSValue(event__dataTransfer, dt) != nil {
+		return []*File{}
+	}
+	return fileList(dt.Get(dataTransfer__files))
+}
+
+// Types lists the dragged formats ("Files", "text/plain", ...); unlike the
+// data it is readable during dragenter and dragover.
+func (e DragEvent) Types() []string {
+	dt := e.dataTransfer()
+	if ValidJSValue(event__dataTransfer, dt) != nil {
+		return []string{}
+	}
+	r := []string{}
+	for _, t := range jsSlice(dt.Get(dataTransfer__types)) {
+		r = append(r, t.String())
+	}
+	return r
+}
+
+// HasFiles reports whether files are being dragged.
+func (e DragEvent) HasFiles() bool {
+	for _, t := range e.Types() {
+		if t == dataTransfer__filesType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetData ...
+func (e DragEvent) GetData(_format string) string {
+	dt := e.dataTransfer()
+	if ValidJSValue(event__dataTransfer, dt) != nil {
+		return ""
+	}
+	return dt.Call(function__getData, _format).String()
+}
+
+// SetData is for dragstart.
+func (e DragEvent) SetData(_format, _data string) {
+	if dt := e.dataTransfer(); ValidJSValue(event__dataTransfer, dt) == nil {
+		dt.Call(function__setData, _format, _data)
+	}
+}
+
+// SetDropEffect sets the cursor feedback, one of the DROP__ constants.
+func (e DragEvent) SetDropEffect(_effect string) {
+	if dt := e.dataTransfer(); ValidJSValue(event__dataTransfer, dt) == nil {
+		dt.Set(dataTransfer__dropEffect, _effect)
+	}
+}
+
+// SetEffectAllowed is for dragstart.
+func (e DragEvent) SetEffectAllowed(_effect string) {
+	if dt := e.dataTransfer(); ValidJSValue(event__dataTransfer, dt) == nil {
+		dt.Set(dataTransfer__effectAllowed, _effect)
+	}
+}
+
+// DropZoneOptions configure Element.DropZone.
+type DropZoneOptions struct {
+	// Accept filters the dropped files like <input accept>: ".glb",
+	// "image/*", "model/gltf-binary". Empty accepts anything.
+	Accept []string
+	// HoverClass is added while files are dragged over the element.
+	HoverClass string
+	// Single keeps only the first accepted file.
+	Single bool
+}
+
+// DropZone makes elem accept dropped files: it cancels dragover so the
+// browser does not open the file, toggles HoverClass and calls _cb with the
+// accepted files. Reading them needs a goroutine (see File). The returned func
+// removes every listener.
+func (elem *Element) DropZone(_opts DropZoneOptions, _cb func([]*File, DragEvent)) func() {
+	depth := 0
+	hover := func(_on bool) {
+		if _opts.HoverClass != "" {
+			elem.ClassList().Toggle(_opts.HoverClass, _on)
+		}
+	}
+
+	offs := []func(){
+		elem.On(EVENT__dragenter, func(_e Event) {
+			d := _e.Drag()
+			if !d.HasFiles() {
+				return
+			}
+			_e.PreventDefault()
+			depth++
+			hover(true)
+		}),
+		elem.On(EVENT__dragover, func(_e Event) {
+			d := _e.Drag()
+			if !d.HasFiles() {
+				return
+			}
+			_e.PreventDefault()
+			d.SetDropEffect(DROP__copy)
+		}),
+		// enter and leave fire for every child crossed, so count them
+		elem.On(EVENT__dragleave, func(_e Event) {
+			if depth > 0 {
+				depth--
+			}
+			if depth == 0 {
+				hover(false)
+			}
+		}),
+		elem.On(EVENT__drop, func(_e Event) {
+			d := _e.Drag()
+			if !d.HasFiles() {
+				return
+			}
+			_e.PreventDefault()
+			depth = 0
+			hover(false)
+
+			files := []*File{}
+			for _, f := range d.Files() {
+				if f.Accepts(_opts.Accept...) {
+					files = append(files, f)
+				}
+			}
+			if _opts.Single && len(files) > 1 {
+				files = files[:1]
+			}
+			if len(files) > 0 {
+				_cb(files, d)
+			}
+		}),
+	}
+	return func() {
+		for _, off := range offs {
+			off()
+		}
+		hover(false)
+	}
+}
+
+// Files returns the files chosen in an <input type="file">.
+func (elem *Element) Files() []*File {
+	if ValidJSValue(elem.String(), elem.Value) != nil {
+		return []*File{}
+	}
+	return fileList(elem.Value.Get(control__files))
+}
+
+// OnFiles calls _cb with the chosen files whenever the selection of an
+// <input type="file"> changes. The files are filtered by the input's own
+// accept attribute, which browsers only use as a hint in the picker.
+func (elem *Element) OnFiles(_cb func([]*File)) func() {
+	return elem.On(EVENT__change, func(Event) {
+		var accept []string
+		if a := elem.Value.Call(function__getAttribute, "accept"); a.Type() == js.TypeString {
+			accept = strings.Split(a.String(), ",")
+		}
+		files := []*File{}
+		for _, f := range elem.Files() {
+			if f.Accepts(accept...) {
+				files = append(files, f)
+			}
+		}
+		_cb(files)
+	})
+}
@@ -8,12 +8,15 @@ import (
 )
 
 const (
-	ELEMENT__tag        = "tagName"
-	ELEMENT__id         = "id"
-	ELEMENT__class      = "className"
-	ELEMENT__innerText  = "innerText"
-	element__parent     = "parent"
-	element__parentNode = "parentNode"
+	ELEMENT__tag             = "tagName"
+	ELEMENT__id              = "id"
+	ELEMENT__class           = "className"
+	ELEMENT__innerText       = "innerText"
+	element__parent          = "parent"
+	element__parentNode      = "parentNode"
+	element__children        = "children"
+	element__nextSibling     = "nextElementSibling"
+	element__previousSibling = "previousElementSibling"
 
 	function__emit             = "emit"
 	function__addEventListener = "addEventListener"
@@ -22,6 +25,8 @@ const (
 	function__removeAttribute  = "removeAttribute"
 	FUNCTION__appendChild      = "appendChild"
 	function__removeChild      = "removeChild"
+	function__insertBefore     = "insertBefore"
+	function__matches          = "matches"
 
 	PROPERTY__value = "value"
 
@@ -53,6 +58,10 @@ func NewElement(_v js.Value) *Element {
 		Components: map[string]*Component{},
 	}
 
+	if err := ValidJSValue("element", _v); err != nil {
+		return e
+	}
+
 	id := _v.Get(ELEMENT__id)
 	if err := ValidJSValue(ELEMENT__id, id); err == nil {
 		e.ID = id.String()
@@ -72,6 +81,9 @@ func (elem *Element) Remove() error {
 			delete(elem.Components, k)
 		}
 	}
+	if c := cacheOf(elem.Value); c != nil {
+		c.Clear()
+	}
 	parent, err := elem.GetProperty(element__parentNode)
 	if err != nil {
 		return fmt.Errorf("%s [Remove] [error]: %v", elem, err)
@@ -129,23 +141,23 @@ func (elem *Element) SetProperty(_val interface{}, _names ...string) error {
 	return nil
 }
 
-// SetAttribute ...
-func (elem *Element) SetAttribute(_compName string, _vals map[string]interface{}) error {
+// SetAttribute sets an attribute (or A-Frame component) on the element. A map
+// with a single "var" key sets that value, an empty map sets "", and every
+// value, maps and Go structs included, goes through Marshal.
+func (elem *Element) SetAttribute(_compName string, _vals interface{}) error {
 	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
 		return fmt.Errorf("%s [SetAttribute] [error]: %v", elem, err)
 	}
 
-	switch len(_vals) {
-	case 0:
+	m, ok := _vals.(map[string]interface{})
+	val, isVar := m["var"]
+	switch {
+	case ok && len(m) == 0:
 		elem.Value.Call(function__setAttibute, _compName, "")
-	case 1:
-		if val, ok := _vals["var"]; ok {
-			elem.Value.Call(function__setAttibute, _compName, val)
-			return nil
-		}
-		elem.Value.Call(function__setAttibute, _compName, _vals)
+	case len(m) == 1 && isVar:
+		elem.Value.Call(function__setAttibute, _compName, Marshal(val))
 	default:
-		elem.Value.Call(function__setAttibute, _compName, _vals)
+		elem.Value.Call(function__setAttibute, _compName, Marshal(_vals))
 	}
 	return nil
 }
@@ -175,6 +187,9 @@ func (elem *Element) RemoveAttribute(_compName string) error {
 	}
 	elem.Value.Call(function__removeAttribute, _compName)
 	delete(elem.Components, _compName)
+	if _compName == ELEMENT__id {
+		elem.ID = ""
+	}
 	return nil
 }
 
@@ -192,6 +207,39 @@ func (elem *Element) SetChild(_v js.Value) error {
 	return nil
 }
 
+// InsertBefore inserts _child before _ref, or appends it when _ref is nil.
+// A child that is already in the DOM is moved.
+func (elem *Element) InsertBefore(_child, _ref *Element) error {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return fmt.Errorf("%s [InsertBefore] [error]: %v", elem, err)
+	}
+	if _child == nil || ValidJSValue("child", _child.Value) != nil {
+		return fmt.Errorf("%s [InsertBefore] [error]: child is not a node", elem)
+	}
+	ref := js.Null()
+	if _ref != nil {
+		ref = _ref.Value
+	}
+	if _, err := callJS(elem.Value, function__insertBefore, _child.Value, ref); err != nil {
+		return fmt.Errorf("%s [InsertBefore] %s [error]: %v", elem, _child, err)
+	}
+	return nil
+}
+
+// RemoveChild ...
+func (elem *Element) RemoveChild(_child *Element) error {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return fmt.Errorf("%s [RemoveChild] [error]: %v", elem, err)
+	}
+	if _child == nil || ValidJSValue("child", _child.Value) != nil {
+		return fmt.Errorf("%s [RemoveChild] [error]: child is not a node", elem)
+	}
+	if _, err := callJS(elem.Value, function__removeChild, _child.Value); err != nil {
+		return fmt.Errorf("%s [RemoveChild] %s [error]: %v", elem, _child, err)
+	}
+	return nil
+}
+
 // RemoveChildByID ...
 func (elem *Element) RemoveChildById(_id string) error {
 	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
@@ -205,12 +253,6 @@ func (elem *Element) RemoveChildById(_id string) error {
 	return nil
 }
 
-// StoreCacheValue - NOT IMPLEMENTED
-func (elem *Element) StoreCacheValue(_name, _type string, _val interface{}) error {
-
-	return nil
-}
-
 // AddEventListener ...
 func (elem *Element) AddEventListener(_eventName string, _cb js.Func, _opts map[string]interface{}) error {
 	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
@@ -230,7 +272,8 @@ func (elem *Element) String() string {
 	return fmt.Sprintf("[%s]element[%s]", elem.Tag, elem.ID)
 }
 
-// Parent ...
+// Parent returns the id of the A-Frame parent entity; use ParentElement to walk
+// the DOM.
 func (elem *Element) Parent() string {
 	p := elem.Value.Get(element__parent)
 	err := ValidJSValue(fmt.Sprintf("%s.%s", elem, element__parent), p)
@@ -244,11 +287,106 @@ func (elem *Element) Parent() string {
 	return id.String()
 }
 
-// NOT IMPLEMENTED
-func (elem *Element) GetCacheValue(_name string) (interface{}, error) {
-	var v interface{}
+// ParentElement returns the parent element, or nil at the root.
+func (elem *Element) ParentElement() *Element {
+	return elem.related(node__parentElement)
+}
 
-	return v, nil
+// NextSibling returns the next sibling element, or nil.
+func (elem *Element) NextSibling() *Element {
+	return elem.related(element__nextSibling)
+}
+
+// PreviousSibling returns the previous sibling element, or nil.
+func (elem *Element) PreviousSibling() *Element {
+	return elem.related(element__previousSibling)
+}
+
+// Children returns the child elements (text nodes excluded).
+func (elem *Element) Children() []*Element {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return []*Element{}
+	}
+	return elementList(elem.Value.Get(element__children))
+}
+
+// QuerySelector returns the first descendant matching the CSS selector, or nil
+// when nothing matches.
+func (elem *Element) QuerySelector(_sel string) (*Element, error) {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return nil, fmt.Errorf("%s [QuerySelector] [error]: %v", elem, err)
+	}
+	tv, err := callJS(elem.Value, function__querySelector, _sel)
+	if err != nil {
+		return nil, fmt.Errorf("%s [QuerySelector] [%s] [error]: %v", elem, _sel, err)
+	}
+	if ValidJSValue(_sel, tv) != nil {
+		return nil, nil
+	}
+	return NewElement(tv), nil
+}
+
+// QuerySelectorAll returns every descendant matching the CSS selector.
+func (elem *Element) QuerySelectorAll(_sel string) ([]*Element, error) {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return nil, fmt.Errorf("%s [QuerySelectorAll] [error]: %v", elem, err)
+	}
+	tv, err := callJS(elem.Value, function__querySelectorAll, _sel)
+	if err != nil {
+		return nil, fmt.Errorf("%s [QuerySelectorAll] [%s] [error]: %v", elem, _sel, err)
+	}
+	return elementList(tv), nil
+}
+
+// Closest returns the element itself or its nearest ancestor matching the CSS
+// selector, or nil.
+func (elem *Element) Closest(_sel string) (*Element, error) {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return nil, fmt.Errorf("%s [Closest] [error]: %v", elem, err)
+	}
+	tv, err := callJS(elem.Value, function__closest, _sel)
+	if err != nil {
+		return nil, fmt.Errorf("%s [Closest] [%s] [error]: %v", elem, _sel, err)
+	}
+	if ValidJSValue(_sel, tv) != nil {
+		return nil, nil
+	}
+	return NewElement(tv), nil
+}
+
+// Matches reports whether the element matches the CSS selector.
+func (elem *Element) Matches(_sel string) (bool, error) {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return false, fmt.Errorf("%s [Matches] [error]: %v", elem, err)
+	}
+	tv, err := callJS(elem.Value, function__matches, _sel)
+	if err != nil {
+		return false, fmt.Errorf("%s [Matches] [%s] [error]: %v", elem, _sel, err)
+	}
+	return tv.Truthy(), nil
+}
+
+func (elem *Element) related(_name string) *Element {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return nil
+	}
+	tv := elem.Value.Get(_name)
+	if err := ValidJSValue(_name, tv); err != nil {
+		return nil
+	}
+	return NewElement(tv)
+}
+
+// elementList converts a NodeList / HTMLCollection into elements.
+func elementList(_v js.Value) []*Element {
+	if err := ValidJSValue("list", _v); err != nil {
+		return []*Element{}
+	}
+	r := make([]*Element, 0, _v.Length())
+	for i := 0; i < _v.Length(); i++ {
+		r = append(r, NewElement(_v.Index(i)))
+	}
+	return r
 }
 
 // GetProperty ...
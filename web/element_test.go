@@ -0,0 +1,102 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+func TestElementSetAttribute(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	win := web.NewWindow()
+	el := win.NewElementWithTag("a-entity")
+
+	type light struct {
+		Type      string  `js:"type"`
+		Intensity float64 `js:"intensity,omitempty"`
+	}
+
+	for _, c := range []struct {
+		name string
+		vals interface{}
+		want string
+	}{
+		{"visible", map[string]interface{}{"var": false}, "false"},
+		{"shadow", map[string]interface{}{}, ""},
+		{"position", map[string]interface{}{"x": 1}, `{"x":1}`},
+		{"text", map[string]interface{}{"value": "hi"}, `{"value":"hi"}`},
+		{"light", light{Type: "ambient"}, `{"type":"ambient"}`},
+		{"light", map[string]interface{}{"var": light{Type: "point"}}, `{"type":"point"}`},
+		{"lights", map[string]interface{}{"main": light{Type: "spot", Intensity: 2}}, `{"main":{"type":"spot","intensity":2}}`},
+	} {
+		h.Reset()
+		if err := el.SetAttribute(c.name, c.vals); err != nil {
+			t.Fatalf("SetAttribute(%s): %v", c.name, err)
+		}
+		calls := h.CallsTo(webtest.TargetElement, "setAttribute")
+		if len(calls) != 1 {
+			t.Fatalf("SetAttribute(%s): %d setAttribute calls, want 1", c.name, len(calls))
+		}
+		if got := calls[0].Arg(0).String(); got != c.name {
+			t.Errorf("SetAttribute(%s): name = %q", c.name, got)
+		}
+		if got := calls[0].String(); got != "element.setAttribute("+c.name+", "+c.want+")" {
+			t.Errorf("SetAttribute(%s): got %s, want value %s", c.name, got, c.want)
+		}
+		if !calls[0].This.Equal(el.Value) {
+			t.Errorf("SetAttribute(%s): called on another element", c.name)
+		}
+	}
+}
+
+func TestElementSetAttributeInvalid(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	el := web.NewElement(js.Null())
+	if err := el.SetAttribute("visible", map[string]interface{}{"var": true}); err == nil {
+		t.Error("SetAttribute on a null element: want an error")
+	}
+	if n := len(h.CallsTo(webtest.TargetElement, "setAttribute")); n != 0 {
+		t.Errorf("setAttribute called %d times, want 0", n)
+	}
+}
+
+func TestElementRemoveAttribute(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	el := web.NewWindow().NewElementWithTag("div")
+	el.SetAttribute("title", map[string]interface{}{"var": "x"})
+	if err := el.RemoveAttribute("title"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := el.GetAttribute("title")
+	if err == nil && !v.IsNull() {
+		t.Errorf("GetAttribute(title) after RemoveAttribute = %v", v)
+	}
+}
+
+func TestElementSetAttributeMap(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	el := web.NewWindow().NewElementWithTag("a-entity")
+	if err := el.SetAttribute("position", map[string]interface{}{"x": 1, "y": 2, "z": 3}); err != nil {
+		t.Fatal(err)
+	}
+	calls := h.CallsTo(webtest.TargetElement, "setAttribute")
+	if len(calls) != 1 {
+		t.Fatalf("%d setAttribute calls, want 1", len(calls))
+	}
+	v := calls[0].Arg(1)
+	if v.Get("x").Int() != 1 || v.Get("y").Int() != 2 || v.Get("z").Int() != 3 {
+		t.Errorf("got %s", calls[0])
+	}
+}
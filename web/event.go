@@ -0,0 +1,337 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"fmt"
+	"sync"
+	"syscall/js"
+)
+
+const (
+	EVENT__click    = "click"
+	EVENT__dblclick = "dblclick"
+	EVENT__input    = "input"
+	EVENT__change   = "change"
+	EVENT__submit   = "submit"
+	EVENT__focus    = "focus"
+	EVENT__blur     = "blur"
+	EVENT__keydown  = "keydown"
+	EVENT__keyup    = "keyup"
+
+	event__type          = "type"
+	event__target        = "target"
+	event__currentTarget = "currentTarget"
+	event__detail        = "detail"
+	event__timeStamp     = "timeStamp"
+	event__bubbles       = "bubbles"
+	event__defaultPrev   = "defaultPrevented"
+
+	option__once    = "once"
+	option__passive = "passive"
+	option__capture = "capture"
+
+	function__removeEventListener      = "removeEventListener"
+	function__stopPropagation          = "stopPropagation"
+	function__stopImmediatePropagation = "stopImmediatePropagation"
+	function__closest                  = "closest"
+	function__contains                 = "contains"
+
+	node__type          = "nodeType"
+	node__parentElement = "parentElement"
+	node__elementType   = 1
+)
+
+// ListenerOptions map to the addEventListener options object.
+type ListenerOptions struct {
+	Once    bool
+	Passive bool
+	Capture bool
+}
+
+func (o ListenerOptions) jsValue() map[string]interface{} {
+	return map[string]interface{}{
+		option__once:    o.Once,
+		option__passive: o.Passive,
+		option__capture: o.Capture,
+	}
+}
+
+// Event wraps a DOM Event. The typed views (Mouse, Keyboard, Input, Custom)
+// share the same underlying js.Value.
+type Event struct {
+	js.Value
+	delegate js.Value
+}
+
+// Type ...
+func (e Event) Type() string {
+	return e.Value.Get(event__type).String()
+}
+
+// Target ...
+func (e Event) Target() *Element {
+	return NewElement(e.Value.Get(event__target))
+}
+
+// CurrentTarget ...
+func (e Event) CurrentTarget() *Element {
+	return NewElement(e.Value.Get(event__currentTarget))
+}
+
+// DelegateTarget returns the element matched by the selector given to
+// Element.Delegate, or the current target for plain listeners.
+func (e Event) DelegateTarget() *Element {
+	if ValidJSValue("delegate", e.delegate) == nil {
+		return NewElement(e.delegate)
+	}
+	return e.CurrentTarget()
+}
+
+// TimeStamp is the event time in milliseconds.
+func (e Event) TimeStamp() float64 {
+	return e.Value.Get(event__timeStamp).Float()
+}
+
+// Bubbles ...
+func (e Event) Bubbles() bool {
+	return e.Value.Get(event__bubbles).Bool()
+}
+
+// DefaultPrevented ...
+func (e Event) DefaultPrevented() bool {
+	return e.Value.Get(event__defaultPrev).Bool()
+}
+
+// PreventDefault ...
+func (e Event) PreventDefault() {
+	e.Value.Call(FUNCTION__form_preventDefault)
+}
+
+// StopPropagation ...
+func (e Event) StopPropagation() {
+	e.Value.Call(function__stopPropagation)
+}
+
+// StopImmediatePropagation ...
+func (e Event) StopImmediatePropagation() {
+	e.Value.Call(function__stopImmediatePropagation)
+}
+
+// Mouse ...
+func (e Event) Mouse() MouseEvent {
+	return MouseEvent{e}
+}
+
+// Keyboard ...
+func (e Event) Keyboard() KeyboardEvent {
+	return KeyboardEvent{e}
+}
+
+// Input ...
+func (e Event) Input() InputEvent {
+	return InputEvent{e}
+}
+
+// Custom ...
+func (e Event) Custom() CustomEvent {
+	return CustomEvent{e}
+}
+
+// Modifiers is the modifier key state shared by mouse and keyboard events.
+type Modifiers struct {
+	Alt   bool
+	Ctrl  bool
+	Shift bool
+	Meta  bool
+}
+
+func (e Event) modifiers() Modifiers {
+	return Modifiers{
+		Alt:   e.Value.Get("altKey").Bool(),
+		Ctrl:  e.Value.Get("ctrlKey").Bool(),
+		Shift: e.Value.Get("shiftKey").Bool(),
+		Meta:  e.Value.Get("metaKey").Bool(),
+	}
+}
+
+// MouseEvent ...
+type MouseEvent struct {
+	Event
+}
+
+// ClientX is the horizontal position in CSS pixels, relative to the viewport.
+func (e MouseEvent) ClientX() float64 { return e.Value.Get("clientX").Float() }
+
+// ClientY is the vertical position relative to the viewport.
+func (e MouseEvent) ClientY() float64 { return e.Value.Get("clientY").Float() }
+
+// PageX is relative to the whole document, so it includes horizontal scroll.
+func (e MouseEvent) PageX() float64 { return e.Value.Get("pageX").Float() }
+
+// PageY is relative to the whole document, so it includes vertical scroll.
+func (e MouseEvent) PageY() float64 { return e.Value.Get("pageY").Float() }
+
+// OffsetX is relative to the left padding edge of the target element.
+func (e MouseEvent) OffsetX() float64 { return e.Value.Get("offsetX").Float() }
+
+// OffsetY is relative to the top padding edge of the target element.
+func (e MouseEvent) OffsetY() float64 { return e.Value.Get("offsetY").Float() }
+
+// Button is the button that changed state (0 main, 1 auxiliary, 2 secondary).
+func (e MouseEvent) Button() int { return e.Value.Get("button").Int() }
+
+// Buttons is the bitmask of buttons held down.
+func (e MouseEvent) Buttons() int { return e.Value.Get("buttons").Int() }
+
+// Modifiers ...
+func (e MouseEvent) Modifiers() Modifiers { return e.modifiers() }
+
+// KeyboardEvent ...
+type KeyboardEvent struct {
+	Event
+}
+
+// Key is the produced value, e.g. "a" or "Enter".
+func (e KeyboardEvent) Key() string { return e.Value.Get("key").String() }
+
+// Code is the physical key, e.g. "KeyA".
+func (e KeyboardEvent) Code() string { return e.Value.Get("code").String() }
+
+// Repeat ...
+func (e KeyboardEvent) Repeat() bool { return e.Value.Get("repeat").Bool() }
+
+// Modifiers ...
+func (e KeyboardEvent) Modifiers() Modifiers { return e.modifiers() }
+
+// InputEvent ...
+type InputEvent struct {
+	Event
+}
+
+// Data is the inserted text, if any.
+func (e InputEvent) Data() string {
+	d := e.Value.Get("data")
+	if ValidJSValue("data", d) != nil {
+		return ""
+	}
+	return d.String()
+}
+
+// InputType ...
+func (e InputEvent) InputType() string {
+	t := e.Value.Get("inputType")
+	if ValidJSValue("inputType", t) != nil {
+		return ""
+	}
+	return t.String()
+}
+
+// TargetValue is the current value of the target input.
+func (e InputEvent) TargetValue() string {
+	v := e.Value.Get(event__target).Get(PROPERTY__value)
+	if ValidJSValue(PROPERTY__value, v) != nil {
+		return ""
+	}
+	return v.String()
+}
+
+// CustomEvent ...
+type CustomEvent struct {
+	Event
+}
+
+// Detail ...
+func (e CustomEvent) Detail() js.Value {
+	return e.Value.Get(event__detail)
+}
+
+// DecodeDetail decodes the event detail into _v.
+func (e CustomEvent) DecodeDetail(_v interface{}) error {
+	d := e.Detail()
+	if err := ValidJSValue(event__detail, d); err != nil {
+		return fmt.Errorf("[event] [%s] [DecodeDetail] [error]: %v", e.Type(), err)
+	}
+	if err := Unmarshal(d, _v); err != nil {
+		return fmt.Errorf("[event] [%s] [DecodeDetail] [error]: %v", e.Type(), err)
+	}
+	return nil
+}
+
+// listen adds _cb as an event listener on _target. The returned func removes
+// the listener and releases its js.Func; it is safe to call more than once.
+func listen(_target js.Value, _event string, _cb func(Event), _opts ListenerOptions) func() {
+	var once sync.Once
+	var fn js.Func
+	unsubscribe := func() {
+		once.Do(func() {
+			_target.Call(function__removeEventListener, _event, fn, map[string]interface{}{option__capture: _opts.Capture})
+			fn.Release()
+		})
+	}
+
+	fn = js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		if _opts.Once {
+			defer unsubscribe()
+		}
+		_cb(Event{Value: firstArg(_args)})
+		return nil
+	})
+
+	_target.Call(function__addEventListener, _event, fn, _opts.jsValue())
+	return unsubscribe
+}
+
+// On listens for _event and returns a func that removes the listener again.
+func (elem *Element) On(_event string, _cb func(Event), _opts ...ListenerOptions) func() {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return func() {}
+	}
+	var o ListenerOptions
+	if len(_opts) > 0 {
+		o = _opts[0]
+	}
+	return listen(elem.Value, _event, _cb, o)
+}
+
+// Once is On with ListenerOptions{Once: true}.
+func (elem *Element) Once(_event string, _cb func(Event)) func() {
+	return elem.On(_event, _cb, ListenerOptions{Once: true})
+}
+
+// Delegate listens for _event on elem and calls _cb only when the event target
+// sits inside a descendant matching _selector; elem itself never matches.
+// Event.DelegateTarget returns that descendant.
+func (elem *Element) Delegate(_event, _selector string, _cb func(Event), _opts ...ListenerOptions) func() {
+	return elem.On(_event, func(_e Event) {
+		t := _e.Value.Get(event__target)
+		if ValidJSValue(event__target, t) != nil {
+			return
+		}
+		if t.Get(node__type).Int() != node__elementType {
+			t = t.Get(node__parentElement)
+			if ValidJSValue(node__parentElement, t) != nil {
+				return
+			}
+		}
+		m := t.Call(function__closest, _selector)
+		if ValidJSValue(_selector, m) != nil || m.Equal(elem.Value) || !elem.Value.Call(function__contains, m).Bool() {
+			return
+		}
+		_e.delegate = m
+		_cb(_e)
+	}, _opts...)
+}
+
+// On listens for _event on the window (or worker global scope) and returns a
+// func that removes the listener again.
+func (w *Window) On(_event string, _cb func(Event), _opts ...ListenerOptions) func() {
+	if err := ValidJSValue(window, w.value); err != nil {
+		return func() {}
+	}
+	var o ListenerOptions
+	if len(_opts) > 0 {
+		o = _opts[0]
+	}
+	return listen(w.value, _event, _cb, o)
+}
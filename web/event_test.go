@@ -0,0 +1,166 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+// fire dispatches a plain event object of type _typ with the given extra
+// properties on _target.
+func fire(_target js.Value, _typ string, _bubbles bool, _props map[string]interface{}) {
+	evt := js.ValueOf(_props)
+	if _props == nil {
+		evt = js.Global().Get("Object").New()
+	}
+	evt.Set("type", _typ)
+	evt.Set("bubbles", _bubbles)
+	_target.Call("dispatchEvent", evt)
+}
+
+func TestEventViews(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	el := web.NewWindow().NewElementWithValue(h.AddElement("button", "b"))
+
+	var got []web.Event
+	var delegate js.Value
+	off := el.On(web.EVENT__click, func(_e web.Event) {
+		got = append(got, _e)
+		delegate = _e.DelegateTarget().Value
+	})
+	defer off()
+	fire(el.Value, web.EVENT__click, true, map[string]interface{}{
+		"clientX": 1, "clientY": 2, "pageX": 3, "pageY": 4, "offsetX": 5, "offsetY": 6,
+		"button": 2, "buttons": 3, "altKey": false, "ctrlKey": false, "shiftKey": true, "metaKey": true,
+	})
+	if len(got) != 1 {
+		t.Fatalf("On: %d calls, want 1", len(got))
+	}
+	e := got[0]
+	if e.Type() != "click" || !e.Bubbles() || e.Target().Value.Get("id").String() != "b" {
+		t.Errorf("event = %s bubbles=%v target=%v", e.Type(), e.Bubbles(), e.Target().Value)
+	}
+	if !delegate.Equal(el.Value) {
+		t.Error("DelegateTarget of a plain listener is not the current target")
+	}
+	m := e.Mouse()
+	if m.ClientX() != 1 || m.ClientY() != 2 || m.PageX() != 3 || m.PageY() != 4 || m.OffsetX() != 5 || m.OffsetY() != 6 {
+		t.Errorf("mouse coordinates = %v %v %v %v %v %v", m.ClientX(), m.ClientY(), m.PageX(), m.PageY(), m.OffsetX(), m.OffsetY())
+	}
+	if m.Button() != 2 || m.Buttons() != 3 {
+		t.Errorf("Button = %d, Buttons = %d", m.Button(), m.Buttons())
+	}
+	if mods := m.Modifiers(); mods != (web.Modifiers{Shift: true, Meta: true}) {
+		t.Errorf("Modifiers = %+v", mods)
+	}
+
+	got = nil
+	el.On(web.EVENT__keydown, func(_e web.Event) { got = append(got, _e) })
+	fire(el.Value, web.EVENT__keydown, true, map[string]interface{}{"key": "a", "code": "KeyA", "repeat": true,
+		"altKey": false, "ctrlKey": true, "shiftKey": false, "metaKey": false,
+	})
+	if len(got) != 1 {
+		t.Fatalf("keydown: %d calls, want 1", len(got))
+	}
+	if k := got[0].Keyboard(); k.Key() != "a" || k.Code() != "KeyA" || !k.Repeat() || !k.Modifiers().Ctrl {
+		t.Errorf("keyboard = %q %q %v %+v", k.Key(), k.Code(), k.Repeat(), k.Modifiers())
+	}
+
+	type detail struct {
+		ID    string `js:"id"`
+		Count int    `js:"count"`
+	}
+	var d detail
+	el.On("picked", func(_e web.Event) {
+		if err := _e.Custom().DecodeDetail(&d); err != nil {
+			t.Error(err)
+		}
+	})
+	el.Value.Call("emit", "picked", map[string]interface{}{"id": "x", "count": 2}, false)
+	if d != (detail{"x", 2}) {
+		t.Errorf("DecodeDetail = %+v", d)
+	}
+}
+
+func TestEventOnOnce(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	el := web.NewWindow().NewElementWithValue(h.AddElement("div", "d"))
+
+	var on, once int
+	off := el.On(web.EVENT__click, func(web.Event) { on++ })
+	el.Once(web.EVENT__click, func(web.Event) { once++ })
+	fire(el.Value, web.EVENT__click, false, nil)
+	fire(el.Value, web.EVENT__click, false, nil)
+	if on != 2 || once != 1 {
+		t.Errorf("after two clicks: On ran %d times, Once %d; want 2 and 1", on, once)
+	}
+
+	off()
+	off()
+	fire(el.Value, web.EVENT__click, false, nil)
+	if on != 2 {
+		t.Errorf("On ran %d times after unsubscribing, want 2", on)
+	}
+	if n := el.Value.Get("__listeners").Get("click").Length(); n != 0 {
+		t.Errorf("%d click listeners left, want 0", n)
+	}
+
+	var fired bool
+	stop := web.NewWindow().On("resize", func(web.Event) { fired = true })
+	h.EmitWindow("resize", nil)
+	stop()
+	if !fired {
+		t.Error("Window.On did not fire")
+	}
+}
+
+func TestEventDelegate(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	win := web.NewWindow()
+
+	wrap := h.AddElement("div", "wrap")
+	wrap.Get("classList").Call("add", "row")
+	list := js.Global().Get("document").Call("createElement", "ul")
+	list.Get("classList").Call("add", "row")
+	wrap.Call("appendChild", list)
+	item := control(list, "li", "class", "row")
+	label := control(item, "span")
+	text := js.Global().Get("document").Call("createTextNode", "hi")
+	label.Call("appendChild", text)
+	other := control(list, "p")
+
+	el := win.NewElementWithValue(list)
+	var hits []js.Value
+	off := el.Delegate(web.EVENT__click, ".row", func(_e web.Event) {
+		hits = append(hits, _e.DelegateTarget().Value)
+	})
+	defer off()
+
+	for _, c := range []struct {
+		name   string
+		target js.Value
+		want   bool
+	}{
+		{"matching descendant", item, true},
+		{"inside a matching descendant", label, true},
+		{"text node", text, true},
+		{"element itself", list, false},
+		{"non-matching child", other, false},
+	} {
+		hits = nil
+		fire(c.target, web.EVENT__click, true, nil)
+		if c.want && (len(hits) != 1 || !hits[0].Equal(item)) {
+			t.Errorf("%s: delegate hits = %v, want the li", c.name, hits)
+		}
+		if !c.want && len(hits) != 0 {
+			t.Errorf("%s: delegate fired for %v", c.name, hits)
+		}
+	}
+}
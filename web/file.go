@@ -0,0 +1,269 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"syscall/js"
+	"time"
+)
+
+const (
+	file__name         = "name"
+	file__size         = "size"
+	file__type         = "type"
+	file__lastModified = "lastModified"
+
+	blob__constructor = "Blob"
+	url__constructor  = "URL"
+
+	function__stream          = "stream"
+	function__slice           = "slice"
+	function__text            = "text"
+	function__createObjectURL = "createObjectURL"
+	function__revokeObjectURL = "revokeObjectURL"
+)
+
+// File is a browser File (from an <input type="file">, a drop or a Blob).
+// Value keeps the JS object for APIs that take the file itself, e.g. an
+// upload.
+//
+// File is also an io.Reader, streaming the contents through Blob.stream(),
+// and an io.ReaderAt, reading slices on demand. Both block on promises, so
+// like Await they must be used from a goroutine, never directly inside an
+// event callback:
+//
+//	input.OnFiles(func(_files []*web.File) {
+//		go func() {
+//			defer _files[0].Close()
+//			doc, err := gltf.Decode(_files[0])
+//			...
+//		}()
+//	})
+//
+// Read and ReadAt wait as long as the browser takes and cannot be cancelled;
+// Reader and ReadAtContext take a context to bound them.
+type File struct {
+	Name         string
+	Size         int64
+	Type         string
+	LastModified time.Time
+
+	Value js.Value
+
+	rd io.ReadCloser
+}
+
+// NewFile ...
+func NewFile(_v js.Value) *File {
+	f := &File{Value: _v}
+	if ValidJSValue("file", _v) != nil {
+		return f
+	}
+	if n := _v.Get(file__name); n.Type() == js.TypeString {
+		f.Name = n.String()
+	}
+	if s := _v.Get(file__size); s.Type() == js.TypeNumber {
+		f.Size = int64(s.Float())
+	}
+	if t := _v.Get(file__type); t.Type() == js.TypeString {
+		f.Type = t.String()
+	}
+	if m := _v.Get(file__lastModified); m.Type() == js.TypeNumber {
+		f.LastModified = msToTime(m.Float())
+	}
+	return f
+}
+
+// NewBlob copies _b into a new Blob of MIME type _mime.
+func NewBlob(_b []byte, _mime string) js.Value {
+	opts := map[string]interface{}{}
+	if _mime != "" {
+		opts[file__type] = _mime
+	}
+	return js.Global().Get(blob__constructor).New([]interface{}{uint8ArrayOf(_b)}, opts)
+}
+
+// fileList converts a FileList (or array of files) into Files.
+func fileList(_v js.Value) []*File {
+	if ValidJSValue("files", _v) != nil {
+		return []*File{}
+	}
+	r := make([]*File, 0, _v.Length())
+	for i := 0; i < _v.Length(); i++ {
+		r = append(r, NewFile(_v.Index(i)))
+	}
+	return r
+}
+
+// String ...
+func (f *File) String() string {
+	return fmt.Sprintf("[%s]file[%s]", f.Type, f.Name)
+}
+
+// Read streams the contents from Blob.stream(); where that is missing it
+// falls back to reading slices.
+func (f *File) Read(_p []byte) (int, error) {
+	if err := ValidJSValue(f.String(), f.Value); err != nil {
+		return 0, fmt.Errorf("%s [Read] [error]: %v", f, err)
+	}
+	if f.rd == nil {
+		f.rd = f.Reader(context.Background())
+	}
+	return f.rd.Read(_p)
+}
+
+// Reader returns a new reader over the whole file, independent of Read.
+// Once _ctx is done its reads fail with the context's error; Close releases
+// the underlying stream.
+func (f *File) Reader(_ctx context.Context) io.ReadCloser {
+	if ValidJSValue(f.String(), f.Value) == nil && f.Value.Get(function__stream).Type() == js.TypeFunction {
+		return &streamReader{
+			ctx:    _ctx,
+			reader: f.Value.Call(function__stream).Call(function__getReader),
+		}
+	}
+	return &sliceReader{ctx: _ctx, file: f}
+}
+
+// ReadAt reads len(_p) bytes from offset _off through Blob.slice.
+func (f *File) ReadAt(_p []byte, _off int64) (int, error) {
+	return f.ReadAtContext(context.Background(), _p, _off)
+}
+
+// ReadAtContext is ReadAt, giving up when _ctx is done.
+func (f *File) ReadAtContext(_ctx context.Context, _p []byte, _off int64) (int, error) {
+	if err := ValidJSValue(f.String(), f.Value); err != nil {
+		return 0, fmt.Errorf("%s [ReadAt] [error]: %v", f, err)
+	}
+	if _off >= f.Size {
+		return 0, io.EOF
+	}
+	end := _off + int64(len(_p))
+	if end > f.Size {
+		end = f.Size
+	}
+	buf, err := Await(_ctx, f.Value.Call(function__slice, _off, end).Call(function__arrayBuffer))
+	if err != nil {
+		if _ctx.Err() != nil {
+			return 0, _ctx.Err()
+		}
+		return 0, fmt.Errorf("%s [ReadAt] [error]: %v", f, err)
+	}
+	n := copy(_p, bytesOf(buf))
+	if n < len(_p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close stops a stream started by Read; the next Read starts over.
+func (f *File) Close() error {
+	if f.rd != nil {
+		f.rd.Close()
+		f.rd = nil
+	}
+	return nil
+}
+
+// Bytes reads the whole file.
+func (f *File) Bytes(_ctx context.Context) ([]byte, error) {
+	if err := ValidJSValue(f.String(), f.Value); err != nil {
+		return nil, fmt.Errorf("%s [Bytes] [error]: %v", f, err)
+	}
+	buf, err := awaitCall(_ctx, f.Value, function__arrayBuffer)
+	if err != nil {
+		return nil, fmt.Errorf("%s [Bytes] [error]: %v", f, err)
+	}
+	return bytesOf(buf), nil
+}
+
+// Text reads the whole file as UTF-8.
+func (f *File) Text(_ctx context.Context) (string, error) {
+	if err := ValidJSValue(f.String(), f.Value); err != nil {
+		return "", fmt.Errorf("%s [Text] [error]: %v", f, err)
+	}
+	s, err := awaitCall(_ctx, f.Value, function__text)
+	if err != nil {
+		return "", fmt.Errorf("%s [Text] [error]: %v", f, err)
+	}
+	return s.String(), nil
+}
+
+// Slice returns bytes [_start, _end) as a new Blob backed File.
+func (f *File) Slice(_start, _end int64) *File {
+	s := NewFile(f.Value.Call(function__slice, _start, _end, f.Type))
+	s.Name = f.Name
+	return s
+}
+
+// ObjectURL returns a blob: URL for the file, usable as an <img> src, a
+// texture or a skybox face, and the func revoking it once loaded.
+func (f *File) ObjectURL() (string, func(), error) {
+	u, err := CreateObjectURL(f.Value)
+	if err != nil {
+		return "", func() {}, fmt.Errorf("%s [ObjectURL] [error]: %v", f, err)
+	}
+	return u, func() { RevokeObjectURL(u) }, nil
+}
+
+// Accepts reports whether the file matches an <input accept> style list:
+// ".glb", "image/*" or "model/gltf-binary". An empty list accepts anything.
+func (f *File) Accepts(_accept ...string) bool {
+	if len(_accept) == 0 {
+		return true
+	}
+	name, typ := strings.ToLower(f.Name), strings.ToLower(f.Type)
+	for _, a := range _accept {
+		a = strings.ToLower(strings.TrimSpace(a))
+		switch {
+		case a == "":
+		case strings.HasPrefix(a, "."):
+			if strings.HasSuffix(name, a) {
+				return true
+			}
+		case strings.HasSuffix(a, "/*"):
+			if strings.HasPrefix(typ, strings.TrimSuffix(a, "*")) {
+				return true
+			}
+		case a == typ:
+			return true
+		}
+	}
+	return false
+}
+
+// sliceReader reads a file through ReadAtContext where Blob.stream() is
+// missing.
+type sliceReader struct {
+	ctx  context.Context
+	file *File
+	off  int64
+}
+
+func (s *sliceReader) Read(_p []byte) (int, error) {
+	n, err := s.file.ReadAtContext(s.ctx, _p, s.off)
+	s.off += int64(n)
+	return n, err
+}
+
+func (s *sliceReader) Close() error {
+	return nil
+}
+
+// CreateObjectURL returns a blob: URL for a Blob, File or MediaSource.
+func CreateObjectURL(_v js.Value) (string, error) {
+	u, err := callJS(js.Global().Get(url__constructor), function__createObjectURL, _v)
+	if err != nil {
+		return "", fmt.Errorf("[url] [CreateObjectURL] [error]: %v", err)
+	}
+	return u.String(), nil
+}
+
+// RevokeObjectURL releases a URL made by CreateObjectURL.
+func RevokeObjectURL(_u string) {
+	js.Global().Get(url__constructor).Call(function__revokeObjectURL, _u)
+}
@@ -0,0 +1,767 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	FORM__tag = "FORM"
+
+	// FormErrorSlot is the data-error-for value of the element that shows
+	// errors not tied to a single field.
+	FormErrorSlot = "_form"
+
+	form__elements = "elements"
+
+	control__name     = "name"
+	control__type     = "type"
+	control__checked  = "checked"
+	control__disabled = "disabled"
+	control__files    = "files"
+	control__options  = "options"
+	control__selected = "selected"
+	control__multiple = "multiple"
+
+	node__textContent = "textContent"
+
+	attribute__errorFor    = "data-error-for"
+	attribute__ariaInvalid = "aria-invalid"
+
+	function__setCustomValidity = "setCustomValidity"
+	function__reset             = "reset"
+
+	tag__form     = "form"
+	tag__validate = "validate"
+	tag__msg      = "msg"
+)
+
+var (
+	GOWEB_ERROR_FORM_TARGET  = errors.New("form target must be a non-nil pointer to a struct")
+	GOWEB_ERROR_NOT_A_FORM   = errors.New("element is not a <form>")
+	GOWEB_ERROR_FORM_CONTROL = errors.New("unsupported form field type")
+
+	formDateLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02", "15:04:05", "15:04", "2006-01", time.RFC3339}
+
+	fileType  = reflect.TypeOf(&File{})
+	filesType = reflect.TypeOf([]*File{})
+)
+
+// Form binds a <form> to Go structs. Struct fields are matched to controls by
+// their `form:"name"` tag (or field name; "-" skips a field) and checked with
+// `validate:"..."` rules:
+//
+//	required        a submitted, non-empty value (checked box, chosen file, ...)
+//	min=N, max=N    numeric bounds, or length bounds for strings and slices
+//	len=N           exact length of a string, slice or submitted number
+//	email           looks like an e-mail address
+//	oneof=a b c     one of the space separated values
+//	eqfield=Field   equal to another Go field (password confirmation)
+//	pattern=RE      matches RE, anchored like the HTML pattern attribute; it
+//	                takes the rest of the tag, commas included, so it must
+//	                be the last rule
+//
+// Rules other than required and eqfield are skipped for a field whose control
+// submitted nothing or only an empty value; a submitted 0 is still checked.
+// A `msg:"..."` tag replaces the default messages for the field. Errors are
+// written into elements marked `data-error-for="name"` inside the form:
+//
+//	type signUp struct {
+//		Email    string `form:"email" validate:"required,email"`
+//		Password string `form:"password" validate:"required,min=8"`
+//		Confirm  string `form:"confirm" validate:"eqfield=Password" msg:"passwords do not match"`
+//		Terms    bool   `form:"terms" validate:"required"`
+//	}
+//
+//	var in signUp
+//	form.OnSubmit(&in, func(_e web.Event, _err error) {
+//		if _err != nil {
+//			return
+//		}
+//		go func() {
+//			prom, err := auth.CreateUser(in.Email, in.Password)
+//			...
+//		}()
+//	})
+type Form struct {
+	*Element
+
+	mu      sync.Mutex
+	decoded uintptr
+	filled  map[string]bool
+}
+
+// NewForm ...
+func NewForm(_elem *Element) (*Form, error) {
+	if _elem == nil || ValidJSValue("form", _elem.Value) != nil {
+		return nil, fmt.Errorf("[form] [NewForm] [error]: %v", GOWEB_ERROR_NOT_A_FORM)
+	}
+	if !strings.EqualFold(_elem.Tag, FORM__tag) {
+		return nil, fmt.Errorf("%s [NewForm] [error]: %v", _elem, GOWEB_ERROR_NOT_A_FORM)
+	}
+	return &Form{Element: _elem}, nil
+}
+
+// FormById ...
+func (w *Window) FormById(_id string) (*Form, error) {
+	tv, err := w.GetValueById(_id)
+	if err != nil {
+		return nil, fmt.Errorf("[window] [FormById] [error]: %v", err)
+	}
+	if err = ValidJSValue(_id, tv); err != nil {
+		return nil, fmt.Errorf("[window] [FormById] [error]: %v", err)
+	}
+	return NewForm(NewElement(tv))
+}
+
+// FieldError is one failed rule (or undecodable value) for a form field.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// Error ...
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// ValidationErrors is returned by Decode and Validate when fields fail.
+type ValidationErrors []*FieldError
+
+// Error ...
+func (v ValidationErrors) Error() string {
+	s := make([]string, len(v))
+	for i, e := range v {
+		s[i] = e.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Field returns the errors for one field name.
+func (v ValidationErrors) Field(_name string) []*FieldError {
+	r := []*FieldError{}
+	for _, e := range v {
+		if e.Field == _name {
+			r = append(r, e)
+		}
+	}
+	return r
+}
+
+// OnSubmit handles the form's submit event: it prevents the browser
+// submission, decodes into _v, validates, renders any errors and then calls
+// _cb with nil or the error. _cb runs inside the event callback; start a
+// goroutine before awaiting anything.
+func (f *Form) OnSubmit(_v interface{}, _cb func(Event, error)) func() {
+	return f.On(EVENT__submit, func(_e Event) {
+		_e.PreventDefault()
+		err := f.Decode(_v)
+		if err == nil {
+			err = f.Validate(_v)
+		}
+		f.ShowErrors(err)
+		_cb(_e, err)
+	})
+}
+
+// Decode reads the enabled, named controls into the struct pointed to by _v.
+// Text-like inputs, textareas and single selects fill scalar fields; checked
+// checkboxes and radios contribute their value; multiple selects and repeated
+// names fill slices; file inputs fill *File or []*File fields; date and time
+// inputs fill time.Time fields. Pointer fields are nil when their control
+// is empty and point to the decoded value otherwise. A field whose controls
+// submitted nothing (an unchecked box or radio group) is reset to its zero
+// value; fields without any enabled control of their name are left alone.
+// Values that do not parse are reported as ValidationErrors with the rule
+// "type".
+func (f *Form) Decode(_v interface{}) error {
+	rv := reflect.ValueOf(_v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%s [Decode] [error]: %v", f, GOWEB_ERROR_FORM_TARGET)
+	}
+	target := rv.Pointer()
+	rv = rv.Elem()
+	controls := f.controls()
+	vals, files := formValues(controls)
+
+	var errs ValidationErrors
+	filled := map[string]bool{}
+	for _, fd := range formFields(rv.Type()) {
+		if _, ok := controls[fd.name]; !ok {
+			continue
+		}
+		fv := rv.FieldByIndex(fd.index)
+		filled[fd.name] = len(files[fd.name]) > 0 || nonEmpty(vals[fd.name])
+		switch {
+		case fv.Type() == fileType:
+			if fs := files[fd.name]; len(fs) > 0 {
+				fv.Set(reflect.ValueOf(fs[0]))
+			} else {
+				fv.Set(reflect.Zero(fileType))
+			}
+			continue
+		case fv.Type() == filesType:
+			fv.Set(reflect.ValueOf(files[fd.name]))
+			continue
+		}
+
+		vs, ok := vals[fd.name]
+		if !ok {
+			fv.Set(reflect.Zero(fv.Type()))
+			continue
+		}
+		if err := decodeFormValue(fv, vs); err != nil {
+			errs = append(errs, &FieldError{Field: fd.name, Rule: "type", Message: fd.message(err.Error())})
+		}
+	}
+
+	f.mu.Lock()
+	f.decoded, f.filled = target, filled
+	f.mu.Unlock()
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate checks the `validate` rules of the struct pointed to by _v and
+// returns ValidationErrors, or nil. Whether a field is empty comes from the
+// last Decode into the same pointer; otherwise an empty string, false, nil,
+// empty slice or zero time counts as empty, and numbers never do.
+func (f *Form) Validate(_v interface{}) error {
+	rv := reflect.ValueOf(_v)
+	var filled map[string]bool
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		f.mu.Lock()
+		if f.decoded == rv.Pointer() {
+			filled = f.filled
+		}
+		f.mu.Unlock()
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%s [Validate] [error]: %v", f, GOWEB_ERROR_FORM_TARGET)
+	}
+
+	var errs ValidationErrors
+	for _, fd := range formFields(rv.Type()) {
+		fv := rv.FieldByIndex(fd.index)
+		empty := isEmptyField(fv)
+		if sub, ok := filled[fd.name]; ok {
+			empty = !sub
+		}
+		for _, rule := range fd.rules {
+			if msg := checkRule(rv, fv, rule, empty); msg != "" {
+				errs = append(errs, &FieldError{Field: fd.name, Rule: rule.name, Message: fd.message(msg)})
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Fill writes the struct pointed to by (or passed as) _v into the controls,
+// checking boxes, radios and options whose value matches.
+func (f *Form) Fill(_v interface{}) error {
+	rv := reflect.ValueOf(_v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%s [Fill] [error]: %v", f, GOWEB_ERROR_FORM_TARGET)
+	}
+	controls := f.controls()
+
+	for _, fd := range formFields(rv.Type()) {
+		fv := rv.FieldByIndex(fd.index)
+		if fv.Type() == fileType || fv.Type() == filesType {
+			continue
+		}
+		for _, c := range controls[fd.name] {
+			fillControl(c, fv)
+		}
+	}
+	return nil
+}
+
+// Reset ...
+func (f *Form) Reset() {
+	f.ClearErrors()
+	f.Value.Call(function__reset)
+}
+
+// ShowErrors clears previous errors and renders _err: field errors go to
+// their `data-error-for` elements and mark the controls aria-invalid with a
+// custom validity message; any other error goes to the FormErrorSlot element.
+func (f *Form) ShowErrors(_err error) {
+	f.ClearErrors()
+	if _err == nil {
+		return
+	}
+
+	var verrs ValidationErrors
+	if !errors.As(_err, &verrs) {
+		f.setErrorText(FormErrorSlot, _err.Error())
+		return
+	}
+
+	controls := f.controls()
+	msgs := map[string][]string{}
+	order := []string{}
+	for _, e := range verrs {
+		if _, ok := msgs[e.Field]; !ok {
+			order = append(order, e.Field)
+		}
+		msgs[e.Field] = append(msgs[e.Field], e.Message)
+	}
+	for _, name := range order {
+		msg := strings.Join(msgs[name], "; ")
+		f.setErrorText(name, msg)
+		for _, c := range controls[name] {
+			c.Call(function__setAttibute, attribute__ariaInvalid, "true")
+			if c.Get(function__setCustomValidity).Type() == js.TypeFunction {
+				c.Call(function__setCustomValidity, msg)
+			}
+		}
+	}
+}
+
+// ClearErrors empties every `data-error-for` element and resets the controls'
+// validity.
+func (f *Form) ClearErrors() {
+	if list, err := f.QuerySelectorAll(fmt.Sprintf("[%s]", attribute__errorFor)); err == nil {
+		for _, el := range list {
+			el.Value.Set(node__textContent, "")
+		}
+	}
+	for _, cs := range f.controls() {
+		for _, c := range cs {
+			c.Call(function__removeAttribute, attribute__ariaInvalid)
+			if c.Get(function__setCustomValidity).Type() == js.TypeFunction {
+				c.Call(function__setCustomValidity, "")
+			}
+		}
+	}
+}
+
+func (f *Form) setErrorText(_name, _msg string) {
+	list, err := f.QuerySelectorAll(fmt.Sprintf("[%s=%s]", attribute__errorFor, cssString(_name)))
+	if err != nil {
+		return
+	}
+	for _, el := range list {
+		el.Value.Set(node__textContent, _msg)
+	}
+}
+
+// cssString quotes _s as a CSS string, for attribute selectors.
+func cssString(_s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range _s {
+		switch {
+		case c == 0:
+			b.WriteRune('\uFFFD')
+		case c < 0x20 || c == 0x7f:
+			// the space terminates the hex escape
+			fmt.Fprintf(&b, "\\%x ", c)
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// controls groups the form's enabled, named controls by name. Buttons are
+// left out since they only submit.
+func (f *Form) controls() map[string][]js.Value {
+	r := map[string][]js.Value{}
+	els := f.Value.Get(form__elements)
+	if ValidJSValue(form__elements, els) != nil {
+		return r
+	}
+	for i := 0; i < els.Length(); i++ {
+		c := els.Index(i)
+		name := c.Get(control__name)
+		if name.Type() != js.TypeString || name.String() == "" || c.Get(control__disabled).Truthy() {
+			continue
+		}
+		switch c.Get(control__type).String() {
+		case "submit", "button", "reset", "image":
+			continue
+		}
+		r[name.String()] = append(r[name.String()], c)
+	}
+	return r
+}
+
+// formValues collects the submitted values of _controls the way FormData
+// would.
+func formValues(_controls map[string][]js.Value) (map[string][]string, map[string][]*File) {
+	vals := map[string][]string{}
+	files := map[string][]*File{}
+	for name, cs := range _controls {
+		for _, c := range cs {
+			switch c.Get(control__type).String() {
+			case "checkbox", "radio":
+				if c.Get(control__checked).Truthy() {
+					vals[name] = append(vals[name], c.Get(PROPERTY__value).String())
+				}
+			case "file":
+				files[name] = append(files[name], fileList(c.Get(control__files))...)
+			case "select-multiple":
+				opts := c.Get(control__options)
+				for i := 0; i < opts.Length(); i++ {
+					if o := opts.Index(i); o.Get(control__selected).Truthy() {
+						vals[name] = append(vals[name], o.Get(PROPERTY__value).String())
+					}
+				}
+			default:
+				vals[name] = append(vals[name], c.Get(PROPERTY__value).String())
+			}
+		}
+	}
+	return vals, files
+}
+
+func nonEmpty(_vals []string) bool {
+	for _, v := range _vals {
+		if strings.TrimSpace(v) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeFormValue(_fv reflect.Value, _vals []string) error {
+	if _fv.Type() == timeType {
+		if len(_vals) == 0 || _vals[0] == "" {
+			_fv.Set(reflect.Zero(timeType))
+			return nil
+		}
+		for _, l := range formDateLayouts {
+			if t, err := time.ParseInLocation(l, _vals[0], time.Local); err == nil {
+				_fv.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("is not a valid date")
+	}
+
+	switch _fv.Kind() {
+	case reflect.Ptr:
+		if !nonEmpty(_vals) {
+			_fv.Set(reflect.Zero(_fv.Type()))
+			return nil
+		}
+		pv := reflect.New(_fv.Type().Elem())
+		if err := decodeFormValue(pv.Elem(), _vals); err != nil {
+			return err
+		}
+		_fv.Set(pv)
+		return nil
+	case reflect.Bool:
+		b := len(_vals) > 0
+		if b {
+			if pb, err := strconv.ParseBool(_vals[0]); err == nil {
+				b = pb
+			}
+		}
+		_fv.SetBool(b)
+		return nil
+	case reflect.Slice:
+		s := reflect.MakeSlice(_fv.Type(), 0, len(_vals))
+		for _, v := range _vals {
+			ev := reflect.New(_fv.Type().Elem()).Elem()
+			if err := decodeFormValue(ev, []string{v}); err != nil {
+				return err
+			}
+			s = reflect.Append(s, ev)
+		}
+		_fv.Set(s)
+		return nil
+	case reflect.String:
+		_fv.SetString(_vals[0])
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if strings.TrimSpace(_vals[0]) == "" {
+			_fv.Set(reflect.Zero(_fv.Type()))
+			return nil
+		}
+		if err := setQueryValue(_fv, strings.TrimSpace(_vals[0])); err != nil {
+			return fmt.Errorf("is not a valid number")
+		}
+		return nil
+	}
+	return GOWEB_ERROR_FORM_CONTROL
+}
+
+func fillControl(_c js.Value, _fv reflect.Value) {
+	switch _c.Get(control__type).String() {
+	case "checkbox":
+		if _fv.Kind() == reflect.Bool {
+			_c.Set(control__checked, _fv.Bool())
+			return
+		}
+		_c.Set(control__checked, containsFormValue(_fv, _c.Get(PROPERTY__value).String()))
+	case "radio":
+		_c.Set(control__checked, containsFormValue(_fv, _c.Get(PROPERTY__value).String()))
+	case "select-multiple", "select-one":
+		opts := _c.Get(control__options)
+		for i := 0; i < opts.Length(); i++ {
+			o := opts.Index(i)
+			o.Set(control__selected, containsFormValue(_fv, o.Get(PROPERTY__value).String()))
+		}
+	default:
+		_c.Set(PROPERTY__value, formatFormValue(_fv, _c.Get(control__type).String()))
+	}
+}
+
+func containsFormValue(_fv reflect.Value, _s string) bool {
+	if _fv.Kind() == reflect.Slice && _fv.Type() != timeType {
+		for i := 0; i < _fv.Len(); i++ {
+			if formatFormValue(_fv.Index(i), "") == _s {
+				return true
+			}
+		}
+		return false
+	}
+	return formatFormValue(_fv, "") == _s
+}
+
+func formatFormValue(_fv reflect.Value, _inputType string) string {
+	if _fv.Type() == timeType {
+		t := _fv.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		switch _inputType {
+		case "date":
+			return t.Format("2006-01-02")
+		case "time":
+			return t.Format("15:04")
+		case "month":
+			return t.Format("2006-01")
+		}
+		return t.Format("2006-01-02T15:04")
+	}
+	switch _fv.Kind() {
+	case reflect.Slice:
+		if _fv.Len() == 0 {
+			return ""
+		}
+		return formatFormValue(_fv.Index(0), _inputType)
+	case reflect.Ptr:
+		if _fv.IsNil() {
+			return ""
+		}
+		return formatFormValue(_fv.Elem(), _inputType)
+	}
+	return fmt.Sprint(_fv.Interface())
+}
+
+type formField struct {
+	index []int
+	name  string
+	rules []formRule
+	msg   string
+}
+
+type formRule struct {
+	name  string
+	param string
+}
+
+func (fd formField) message(_def string) string {
+	if fd.msg != "" {
+		return fd.msg
+	}
+	return _def
+}
+
+func formFields(_t reflect.Type) []formField {
+	r := []formField{}
+	for i := 0; i < _t.NumField(); i++ {
+		sf := _t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup(tag__form); ok {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		r = append(r, formField{
+			index: sf.Index,
+			name:  name,
+			rules: parseRules(sf.Tag.Get(tag__validate)),
+			msg:   sf.Tag.Get(tag__msg),
+		})
+	}
+	return r
+}
+
+func parseRules(_tag string) []formRule {
+	r := []formRule{}
+	for _tag != "" {
+		var part string
+		_tag = strings.TrimLeft(_tag, " ")
+		if strings.HasPrefix(_tag, "pattern=") {
+			part, _tag = _tag, ""
+		} else if i := strings.Index(_tag, ","); i >= 0 {
+			part, _tag = _tag[:i], _tag[i+1:]
+		} else {
+			part, _tag = _tag, ""
+		}
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		rule := formRule{name: kv[0]}
+		if len(kv) == 2 {
+			rule.param = kv[1]
+		}
+		if rule.name != "" {
+			r = append(r, rule)
+		}
+	}
+	return r
+}
+
+// checkRule returns the failure message, or "" when _fv passes. _empty tells
+// whether the field's control submitted a value.
+func checkRule(_sv, _fv reflect.Value, _r formRule, _empty bool) string {
+	if _r.name == "required" {
+		if _empty {
+			return "is required"
+		}
+		return ""
+	}
+	if _empty && _r.name != "eqfield" {
+		return ""
+	}
+	if _r.name == "eqfield" {
+		other := _sv.FieldByName(_r.param)
+		if !other.IsValid() || !reflect.DeepEqual(other.Interface(), _fv.Interface()) {
+			return fmt.Sprintf("must match %s", _r.param)
+		}
+		return ""
+	}
+	if _fv.Kind() == reflect.Ptr && !_fv.IsNil() {
+		_fv = _fv.Elem()
+	}
+
+	switch _r.name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(_r.param, 64)
+		if err != nil {
+			return ""
+		}
+		n, isLen := fieldMeasure(_fv)
+		if _r.name == "min" && n < limit {
+			if isLen {
+				return fmt.Sprintf("must be at least %s characters", _r.param)
+			}
+			return fmt.Sprintf("must be at least %s", _r.param)
+		}
+		if _r.name == "max" && n > limit {
+			if isLen {
+				return fmt.Sprintf("must be at most %s characters", _r.param)
+			}
+			return fmt.Sprintf("must be at most %s", _r.param)
+		}
+	case "len":
+		n, err := strconv.Atoi(_r.param)
+		if err != nil {
+			return ""
+		}
+		l, isLen := fieldMeasure(_fv)
+		if !isLen {
+			l = float64(len([]rune(fmt.Sprint(_fv.Interface()))))
+		}
+		if int(l) != n {
+			return fmt.Sprintf("must be exactly %s characters", _r.param)
+		}
+	case "email":
+		s := fmt.Sprint(_fv.Interface())
+		at := strings.LastIndex(s, "@")
+		if at < 1 || !strings.Contains(s[at+1:], ".") || strings.ContainsAny(s, " \t\r\n") || strings.HasSuffix(s, ".") {
+			return "must be a valid email address"
+		}
+	case "oneof":
+		s := fmt.Sprint(_fv.Interface())
+		opts := strings.Fields(_r.param)
+		for _, o := range opts {
+			if o == s {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(opts, ", "))
+	case "pattern":
+		re, err := regexp.Compile("^(?:" + _r.param + ")$")
+		if err != nil {
+			return ""
+		}
+		if !re.MatchString(fmt.Sprint(_fv.Interface())) {
+			return "is not in the expected format"
+		}
+	}
+	return ""
+}
+
+// isEmptyField judges a field that was not decoded from the form: numbers
+// are never empty, since 0 may be a real answer.
+func isEmptyField(_fv reflect.Value) bool {
+	if _fv.Type() == timeType {
+		return _fv.Interface().(time.Time).IsZero()
+	}
+	switch _fv.Kind() {
+	case reflect.String:
+		return _fv.String() == ""
+	case reflect.Bool:
+		return !_fv.Bool()
+	case reflect.Slice, reflect.Map:
+		return _fv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return _fv.IsNil()
+	}
+	return false
+}
+
+// fieldMeasure is the value min/max compare against: the number itself, or the
+// length (in runes for strings) for strings and slices.
+func fieldMeasure(_fv reflect.Value) (float64, bool) {
+	switch _fv.Kind() {
+	case reflect.String:
+		return float64(len([]rune(_fv.String()))), true
+	case reflect.Slice, reflect.Map:
+		return float64(_fv.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(_fv.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(_fv.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return _fv.Float(), false
+	}
+	return 0, false
+}
@@ -0,0 +1,243 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"errors"
+	"syscall/js"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+// control appends a <_tag> with the given attributes to _parent.
+func control(_parent js.Value, _tag string, _attrs ...string) js.Value {
+	el := js.Global().Get("document").Call("createElement", _tag)
+	for i := 0; i+1 < len(_attrs); i += 2 {
+		el.Call("setAttribute", _attrs[i], _attrs[i+1])
+	}
+	_parent.Call("appendChild", el)
+	return el
+}
+
+func newTestForm(t *testing.T, _h *webtest.Harness) (*web.Form, js.Value) {
+	t.Helper()
+	el := _h.AddElement("form", "f")
+	f, err := web.NewWindow().FormById("f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, el
+}
+
+func TestFormDecode(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	f, el := newTestForm(t, h)
+
+	type profile struct {
+		Name   string      `form:"name"`
+		Age    int         `form:"age"`
+		Score  *int        `form:"score"`
+		Terms  bool        `form:"terms"`
+		Plan   string      `form:"plan"`
+		Colors []string    `form:"colors"`
+		Avatar *web.File   `form:"avatar"`
+		Docs   []*web.File `form:"docs"`
+		ID     string      `form:"id"`
+	}
+
+	control(el, "input", "name", "name", "value", "Ada")
+	control(el, "input", "name", "age", "type", "number", "value", "36")
+	score := control(el, "input", "name", "score", "type", "number")
+	terms := control(el, "input", "name", "terms", "type", "checkbox", "value", "true", "checked", "")
+	plan := control(el, "input", "name", "plan", "type", "radio", "value", "pro", "checked", "")
+	sel := control(el, "select", "name", "colors", "multiple", "")
+	control(sel, "option", "value", "red", "selected", "")
+	control(sel, "option", "value", "green")
+	control(sel, "option", "value", "blue", "selected", "")
+	avatar := control(el, "input", "name", "avatar", "type", "file")
+	docs := control(el, "input", "name", "docs", "type", "file", "multiple", "")
+	control(el, "input", "name", "off", "disabled", "")
+
+	file := func(_name string) js.Value {
+		return js.ValueOf(map[string]interface{}{"name": _name, "size": 3, "type": "text/plain"})
+	}
+	avatar.Set("files", []interface{}{file("me.png")})
+	docs.Set("files", []interface{}{file("a.txt"), file("b.txt")})
+	score.Set("value", "0")
+
+	in := profile{ID: "keep"}
+	if err := f.Decode(&in); err != nil {
+		t.Fatal(err)
+	}
+	if in.Name != "Ada" || in.Age != 36 || !in.Terms || in.Plan != "pro" {
+		t.Errorf("Decode scalars = %+v", in)
+	}
+	if in.Score == nil || *in.Score != 0 {
+		t.Errorf("Score = %v, want pointer to 0", in.Score)
+	}
+	if len(in.Colors) != 2 || in.Colors[0] != "red" || in.Colors[1] != "blue" {
+		t.Errorf("Colors = %v, want [red blue]", in.Colors)
+	}
+	if in.Avatar == nil || in.Avatar.Name != "me.png" || in.Avatar.Size != 3 {
+		t.Errorf("Avatar = %v", in.Avatar)
+	}
+	if len(in.Docs) != 2 || in.Docs[1].Name != "b.txt" {
+		t.Errorf("Docs = %v", in.Docs)
+	}
+
+	// a second submit into the same struct must not keep stale answers
+	terms.Set("checked", false)
+	plan.Set("checked", false)
+	score.Set("value", "")
+	avatar.Set("files", []interface{}{})
+	if err := f.Decode(&in); err != nil {
+		t.Fatal(err)
+	}
+	if in.Terms || in.Plan != "" || in.Score != nil || in.Avatar != nil {
+		t.Errorf("absent controls not reset: %+v", in)
+	}
+	if in.ID != "keep" {
+		t.Errorf("ID = %q, field without a control was changed", in.ID)
+	}
+
+	el.Get("children").Index(1).Set("value", "old")
+	err := f.Decode(&in)
+	var verrs web.ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs.Field("age")) != 1 || verrs[0].Rule != "type" {
+		t.Errorf("Decode(age=old) = %v, want a type error", err)
+	}
+}
+
+func TestFormRules(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	f, el := newTestForm(t, h)
+
+	type rules struct {
+		Name     string `form:"name" validate:"required"`
+		Age      int    `form:"age" validate:"min=18,max=99"`
+		Nick     string `form:"nick" validate:"max=3"`
+		Pin      string `form:"pin" validate:"len=4"`
+		Email    string `form:"email" validate:"email"`
+		Plan     string `form:"plan" validate:"oneof=free pro"`
+		Password string `form:"password"`
+		Confirm  string `form:"confirm" validate:"eqfield=Password" msg:"passwords do not match"`
+		Code     string `form:"code" validate:"pattern=[a-z]+"`
+	}
+	names := []string{"name", "age", "nick", "pin", "email", "plan", "password", "confirm", "code"}
+	inputs := map[string]js.Value{}
+	for _, n := range names {
+		inputs[n] = control(el, "input", "name", n)
+	}
+
+	for _, c := range []struct {
+		vals map[string]string
+		want map[string]string
+	}{
+		{
+			vals: map[string]string{},
+			want: map[string]string{"name": "required"},
+		},
+		{
+			vals: map[string]string{"name": "Ada", "age": "0", "nick": "abcd", "pin": "0", "email": "ada@", "plan": "gold", "password": "x", "confirm": "y", "code": "0"},
+			want: map[string]string{"age": "min", "nick": "max", "pin": "len", "email": "email", "plan": "oneof", "confirm": "eqfield", "code": "pattern"},
+		},
+		{
+			vals: map[string]string{"name": "Ada", "age": "100"},
+			want: map[string]string{"age": "max"},
+		},
+		{
+			vals: map[string]string{"name": "Ada", "age": "18", "nick": "ab", "pin": "0000", "email": "ada@example.com", "plan": "pro", "password": "x", "confirm": "x", "code": "abc"},
+			want: map[string]string{},
+		},
+	} {
+		for _, n := range names {
+			inputs[n].Set("value", c.vals[n])
+		}
+		var in rules
+		if err := f.Decode(&in); err != nil {
+			t.Fatal(err)
+		}
+		err := f.Validate(&in)
+		var verrs web.ValidationErrors
+		if err != nil && !errors.As(err, &verrs) {
+			t.Fatalf("Validate(%v) = %v", c.vals, err)
+		}
+		if len(verrs) != len(c.want) {
+			t.Errorf("Validate(%v) = %v, want rules %v", c.vals, err, c.want)
+		}
+		for _, e := range verrs {
+			if c.want[e.Field] != e.Rule {
+				t.Errorf("Validate(%v): %s failed %s, want %q", c.vals, e.Field, e.Rule, c.want[e.Field])
+			}
+			if e.Field == "confirm" && e.Message != "passwords do not match" {
+				t.Errorf("confirm message = %q", e.Message)
+			}
+		}
+	}
+}
+
+func TestFormShowErrors(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+	f, el := newTestForm(t, h)
+
+	type signUp struct {
+		Email string `form:"email" validate:"required,email"`
+		Terms bool   `form:"terms" validate:"required" msg:"please accept the terms"`
+	}
+	email := control(el, "input", "name", "email", "type", "email")
+	emailErr := control(el, "span", "data-error-for", "email")
+	terms := control(el, "input", "name", "terms", "type", "checkbox")
+	termsErr := control(el, "span", "data-error-for", "terms")
+	formErr := control(el, "p", "data-error-for", web.FormErrorSlot)
+
+	var in signUp
+	var got []error
+	off := f.OnSubmit(&in, func(_e web.Event, _err error) {
+		got = append(got, _err)
+	})
+	defer off()
+
+	email.Set("value", "nope")
+	el.Call("requestSubmit")
+	if len(got) != 1 || got[0] == nil {
+		t.Fatalf("OnSubmit errors = %v, want one failure", got)
+	}
+	if s := emailErr.Get("textContent").String(); s != "must be a valid email address" {
+		t.Errorf("email error text = %q", s)
+	}
+	if s := termsErr.Get("textContent").String(); s != "please accept the terms" {
+		t.Errorf("terms error text = %q", s)
+	}
+	if v := email.Call("getAttribute", "aria-invalid"); v.Type() != js.TypeString || v.String() != "true" {
+		t.Errorf("email aria-invalid = %v", v)
+	}
+	if s := terms.Get("validationMessage").String(); s != "please accept the terms" {
+		t.Errorf("terms validationMessage = %q", s)
+	}
+
+	email.Set("value", "ada@example.com")
+	terms.Set("checked", true)
+	el.Call("requestSubmit")
+	if len(got) != 2 || got[1] != nil {
+		t.Fatalf("OnSubmit errors = %v, want success", got)
+	}
+	if s := emailErr.Get("textContent").String(); s != "" {
+		t.Errorf("email error text after success = %q", s)
+	}
+	if !email.Call("getAttribute", "aria-invalid").IsNull() {
+		t.Error("email still aria-invalid after success")
+	}
+	if !in.Terms || in.Email != "ada@example.com" {
+		t.Errorf("decoded %+v", in)
+	}
+
+	f.ShowErrors(errors.New("server said no"))
+	if s := formErr.Get("textContent").String(); s != "server said no" {
+		t.Errorf("form error text = %q", s)
+	}
+}
@@ -0,0 +1,441 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	function__getGamepads = "getGamepads"
+
+	gamepad__index     = "index"
+	gamepad__id        = "id"
+	gamepad__mapping   = "mapping"
+	gamepad__connected = "connected"
+	gamepad__buttons   = "buttons"
+	gamepad__axes      = "axes"
+	gamepad__timestamp = "timestamp"
+
+	event__gamepad = "gamepad"
+
+	button__pressed = "pressed"
+	button__touched = "touched"
+	button__value   = "value"
+
+	EVENT__gamepadconnected    = "gamepadconnected"
+	EVENT__gamepaddisconnected = "gamepaddisconnected"
+
+	MAPPING__standard   = "standard"
+	MAPPING__xrStandard = "xr-standard"
+
+	gamepad__deadzone = 0.15
+)
+
+// Buttons of the "standard" mapping, named by position (BUTTON__bottom is A
+// on an Xbox pad and cross on a PlayStation one).
+const (
+	BUTTON__bottom = iota
+	BUTTON__right
+	BUTTON__left
+	BUTTON__top
+	BUTTON__leftBumper
+	BUTTON__rightBumper
+	BUTTON__leftTrigger
+	BUTTON__rightTrigger
+	BUTTON__select
+	BUTTON__start
+	BUTTON__leftStick
+	BUTTON__rightStick
+	BUTTON__dpadUp
+	BUTTON__dpadDown
+	BUTTON__dpadLeft
+	BUTTON__dpadRight
+	BUTTON__home
+)
+
+// Sticks of the "standard" mapping; each is two axes, x then y (down is
+// positive).
+const (
+	STICK__left = iota
+	STICK__right
+)
+
+// GamepadButton ...
+type GamepadButton struct {
+	Pressed bool
+	Touched bool
+	// Value is 0 to 1, analog for triggers.
+	Value float64
+}
+
+// Gamepad is a snapshot of a controller's state.
+type Gamepad struct {
+	Index     int
+	ID        string
+	Mapping   string
+	Connected bool
+	Buttons   []GamepadButton
+	Axes      []float64
+	// Timestamp changes whenever the controller reports new data.
+	Timestamp time.Duration
+	Value     js.Value
+}
+
+// NewGamepad snapshots a Gamepad object, e.g. the gamepad of a WebXR input
+// source, which navigator.getGamepads does not list.
+func NewGamepad(_v js.Value) *Gamepad {
+	g := &Gamepad{Value: _v}
+	if ValidJSValue("gamepad", _v) != nil {
+		return g
+	}
+	g.Index = int(floatOrZero(_v.Get(gamepad__index)))
+	g.ID = _v.Get(gamepad__id).String()
+	g.Mapping = _v.Get(gamepad__mapping).String()
+	g.Connected = _v.Get(gamepad__connected).Truthy()
+	g.Timestamp = msDuration(floatOrZero(_v.Get(gamepad__timestamp)))
+	for _, b := range jsSlice(_v.Get(gamepad__buttons)) {
+		g.Buttons = append(g.Buttons, GamepadButton{
+			Pressed: b.Get(button__pressed).Truthy(),
+			Touched: b.Get(button__touched).Truthy(),
+			Value:   floatOrZero(b.Get(button__value)),
+		})
+	}
+	for _, a := range jsSlice(_v.Get(gamepad__axes)) {
+		g.Axes = append(g.Axes, floatOrZero(a))
+	}
+	return g
+}
+
+// Gamepads snapshots the connected controllers, without deadzones. Browsers
+// only list a controller after one of its buttons was pressed on the page.
+func Gamepads() []*Gamepad {
+	nav := navigatorValue()
+	if ValidJSValue(navigator, nav) != nil || nav.Get(function__getGamepads).Type() != js.TypeFunction {
+		return []*Gamepad{}
+	}
+	r := []*Gamepad{}
+	for _, v := range jsSlice(nav.Call(function__getGamepads)) {
+		// the list keeps a null in the slot of a disconnected pad
+		if v.Type() != js.TypeObject {
+			continue
+		}
+		if g := NewGamepad(v); g.Connected {
+			r = append(r, g)
+		}
+	}
+	return r
+}
+
+// Button returns button _i, or a released button when the pad has fewer.
+func (g *Gamepad) Button(_i int) GamepadButton {
+	if _i < 0 || _i >= len(g.Buttons) {
+		return GamepadButton{}
+	}
+	return g.Buttons[_i]
+}
+
+// Axis returns axis _i from -1 to 1, or 0 when the pad has fewer.
+func (g *Gamepad) Axis(_i int) float64 {
+	if _i < 0 || _i >= len(g.Axes) {
+		return 0
+	}
+	return g.Axes[_i]
+}
+
+// Stick returns the x, y axes of one of the STICK__ sticks.
+func (g *Gamepad) Stick(_stick int) (float64, float64) {
+	return g.Axis(_stick * 2), g.Axis(_stick*2 + 1)
+}
+
+// GamepadOptions ...
+type GamepadOptions struct {
+	// Deadzone is the stick and axis travel ignored around the center, where
+	// worn sticks drift. Zero means 0.15; negative disables it.
+	Deadzone float64
+}
+
+// GamepadInput polls the controllers once per Loop frame and keeps the
+// previous frame, so presses are edge triggered:
+//
+//	pads := web.NewGamepadInput(loop, web.GamepadOptions{})
+//	loop.Add(func(_f web.Frame) {
+//		x, y := pads.Stick(0, web.STICK__left)
+//		px += x * speed * _f.Delta.Seconds()
+//		pz += y * speed * _f.Delta.Seconds()
+//		player.SetPosition(px, 0, pz)
+//		if pads.Pressed(0, web.BUTTON__bottom) {
+//			jump()
+//		}
+//	})
+//
+// Create it before adding the ticks that read it: ticks run in the order they
+// were added, so they then see the state of the current frame.
+type GamepadInput struct {
+	deadzone float64
+
+	mu        sync.Mutex
+	pads      map[int]*Gamepad
+	prev      map[int][]bool
+	onConnect map[int]func(*Gamepad)
+	onLost    map[int]func(*Gamepad)
+	nextID    int
+	remove    func()
+	offs      []func()
+}
+
+// NewGamepadInput starts polling on every frame of _loop; the loop has to be
+// started. Connects and disconnects are also taken from the window's
+// gamepadconnected and gamepaddisconnected events, so OnConnect and
+// OnDisconnect fire while the loop is paused.
+func NewGamepadInput(_loop *Loop, _opts GamepadOptions) *GamepadInput {
+	in := &GamepadInput{
+		deadzone:  _opts.Deadzone,
+		pads:      map[int]*Gamepad{},
+		prev:      map[int][]bool{},
+		onConnect: map[int]func(*Gamepad){},
+		onLost:    map[int]func(*Gamepad){},
+	}
+	if in.deadzone == 0 {
+		in.deadzone = gamepad__deadzone
+	}
+	if in.deadzone < 0 {
+		in.deadzone = 0
+	}
+	in.remove = _loop.Add(func(Frame) { in.Poll() })
+	if g := js.Global(); g.Get(function__addEventListener).Type() == js.TypeFunction {
+		in.offs = []func(){
+			listen(g, EVENT__gamepadconnected, in.connected, ListenerOptions{}),
+			listen(g, EVENT__gamepaddisconnected, in.disconnected, ListenerOptions{}),
+		}
+	}
+	return in
+}
+
+// Poll reads the controllers; the Loop calls it, but it can also be called
+// directly without one.
+func (in *GamepadInput) Poll() {
+	in.mu.Lock()
+	seen := map[int]bool{}
+	connected, lost := []*Gamepad{}, []*Gamepad{}
+	for _, g := range Gamepads() {
+		seen[g.Index] = true
+		if old, ok := in.pads[g.Index]; ok {
+			in.prev[g.Index] = pressedOf(old)
+		} else {
+			in.prev[g.Index] = make([]bool, len(g.Buttons))
+			connected = append(connected, g)
+		}
+		in.pads[g.Index] = g
+	}
+	for i, g := range in.pads {
+		if !seen[i] {
+			g.Connected = false
+			lost = append(lost, g)
+			delete(in.pads, i)
+			delete(in.prev, i)
+		}
+	}
+	onConnect, onLost := callbacks(in.onConnect), callbacks(in.onLost)
+	in.mu.Unlock()
+
+	// called without the lock so the callbacks can read the input
+	notifyPads(onConnect, connected)
+	notifyPads(onLost, lost)
+}
+
+// Close stops polling and listening.
+func (in *GamepadInput) Close() {
+	in.remove()
+	for _, off := range in.offs {
+		off()
+	}
+}
+
+// connected adds the pad of a gamepadconnected event. Only Poll moves the
+// button state on, so presses stay edge triggered per frame.
+func (in *GamepadInput) connected(_e Event) {
+	g := NewGamepad(_e.Get(event__gamepad))
+	if ValidJSValue("gamepad", g.Value) != nil {
+		return
+	}
+	in.mu.Lock()
+	if _, ok := in.pads[g.Index]; ok {
+		in.mu.Unlock()
+		return
+	}
+	in.pads[g.Index] = g
+	in.prev[g.Index] = make([]bool, len(g.Buttons))
+	onConnect := callbacks(in.onConnect)
+	in.mu.Unlock()
+
+	notifyPads(onConnect, []*Gamepad{g})
+}
+
+// disconnected drops the pad of a gamepaddisconnected event.
+func (in *GamepadInput) disconnected(_e Event) {
+	v := _e.Get(event__gamepad)
+	if ValidJSValue("gamepad", v) != nil {
+		return
+	}
+	in.mu.Lock()
+	i := int(floatOrZero(v.Get(gamepad__index)))
+	g, ok := in.pads[i]
+	if !ok {
+		in.mu.Unlock()
+		return
+	}
+	g.Connected = false
+	delete(in.pads, i)
+	delete(in.prev, i)
+	onLost := callbacks(in.onLost)
+	in.mu.Unlock()
+
+	notifyPads(onLost, []*Gamepad{g})
+}
+
+// Pads returns the connected controllers by index.
+func (in *GamepadInput) Pads() []*Gamepad {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	r := make([]*Gamepad, 0, len(in.pads))
+	for _, g := range in.pads {
+		r = append(r, g)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Index < r[j].Index })
+	return r
+}
+
+// Pad returns controller _index, or nil when it is not connected.
+func (in *GamepadInput) Pad(_index int) *Gamepad {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.pads[_index]
+}
+
+// Down reports whether _button of pad _index is held.
+func (in *GamepadInput) Down(_index, _button int) bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	g, ok := in.pads[_index]
+	return ok && g.Button(_button).Pressed
+}
+
+// Pressed reports whether _button went down this frame.
+func (in *GamepadInput) Pressed(_index, _button int) bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	g, ok := in.pads[_index]
+	return ok && g.Button(_button).Pressed && !in.wasDown(_index, _button)
+}
+
+// Released reports whether _button went up this frame.
+func (in *GamepadInput) Released(_index, _button int) bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	g, ok := in.pads[_index]
+	return ok && !g.Button(_button).Pressed && in.wasDown(_index, _button)
+}
+
+// Axis returns axis _axis of pad _index with the deadzone applied, rescaled so
+// it still spans -1 to 1.
+func (in *GamepadInput) Axis(_index, _axis int) float64 {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	g, ok := in.pads[_index]
+	if !ok {
+		return 0
+	}
+	v := g.Axis(_axis)
+	return math.Copysign(deadzone(math.Abs(v), in.deadzone), v)
+}
+
+// Stick returns one of the STICK__ sticks of pad _index with a radial
+// deadzone, so diagonals are not cut off.
+func (in *GamepadInput) Stick(_index, _stick int) (float64, float64) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	g, ok := in.pads[_index]
+	if !ok {
+		return 0, 0
+	}
+	x, y := g.Stick(_stick)
+	mag := math.Hypot(x, y)
+	if mag == 0 {
+		return 0, 0
+	}
+	scaled := deadzone(math.Min(mag, 1), in.deadzone)
+	return x / mag * scaled, y / mag * scaled
+}
+
+// OnConnect calls _cb from the frame or event a controller first shows up
+// in; the returned func unregisters it.
+func (in *GamepadInput) OnConnect(_cb func(*Gamepad)) func() {
+	return in.register(in.onConnect, _cb)
+}
+
+// OnDisconnect calls _cb from the frame or event a controller is gone in.
+func (in *GamepadInput) OnDisconnect(_cb func(*Gamepad)) func() {
+	return in.register(in.onLost, _cb)
+}
+
+func (in *GamepadInput) register(_m map[int]func(*Gamepad), _cb func(*Gamepad)) func() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.nextID++
+	id := in.nextID
+	_m[id] = _cb
+	return func() {
+		in.mu.Lock()
+		defer in.mu.Unlock()
+		delete(_m, id)
+	}
+}
+
+// wasDown must be called with mu held.
+func (in *GamepadInput) wasDown(_index, _button int) bool {
+	p := in.prev[_index]
+	return _button >= 0 && _button < len(p) && p[_button]
+}
+
+func pressedOf(_g *Gamepad) []bool {
+	r := make([]bool, len(_g.Buttons))
+	for i, b := range _g.Buttons {
+		r[i] = b.Pressed
+	}
+	return r
+}
+
+// callbacks copies the registered funcs in registration order.
+func callbacks(_m map[int]func(*Gamepad)) []func(*Gamepad) {
+	ids := make([]int, 0, len(_m))
+	for id := range _m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	r := make([]func(*Gamepad), len(ids))
+	for i, id := range ids {
+		r[i] = _m[id]
+	}
+	return r
+}
+
+func notifyPads(_cbs []func(*Gamepad), _pads []*Gamepad) {
+	for _, g := range _pads {
+		for _, cb := range _cbs {
+			cb(g)
+		}
+	}
+}
+
+// deadzone maps _v (0 to 1) past _dz onto 0 to 1.
+func deadzone(_v, _dz float64) float64 {
+	if _v <= _dz {
+		return 0
+	}
+	return (_v - _dz) / (1 - _dz)
+}
@@ -0,0 +1,239 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	navigator__geolocation = "geolocation"
+
+	function__getCurrentPosition = "getCurrentPosition"
+	function__watchPosition      = "watchPosition"
+	function__clearWatch         = "clearWatch"
+
+	position__coords    = "coords"
+	position__timestamp = "timestamp"
+
+	coords__latitude         = "latitude"
+	coords__longitude        = "longitude"
+	coords__accuracy         = "accuracy"
+	coords__altitude         = "altitude"
+	coords__altitudeAccuracy = "altitudeAccuracy"
+	coords__heading          = "heading"
+	coords__speed            = "speed"
+
+	geoOption__highAccuracy = "enableHighAccuracy"
+	geoOption__timeout      = "timeout"
+	geoOption__maximumAge   = "maximumAge"
+
+	geoError__permissionDenied = "1"
+	geoError__unavailable      = "2"
+	geoError__timeout          = "3"
+
+	PERMISSION__geolocation = "geolocation"
+)
+
+var (
+	GOWEB_ERROR_SENSOR_DENIED   = errors.New("sensor permission denied")
+	GOWEB_ERROR_GEO_UNAVAILABLE = errors.New("position unavailable")
+	GOWEB_ERROR_GEO_TIMEOUT     = errors.New("position timed out")
+)
+
+// Position is a geolocation reading. Altitude, AltitudeAccuracy, Heading and
+// Speed are NaN when the device does not report them.
+type Position struct {
+	Latitude         float64
+	Longitude        float64
+	Accuracy         float64 // meters
+	Altitude         float64 // meters
+	AltitudeAccuracy float64
+	Heading          float64 // degrees clockwise from north
+	Speed            float64 // meters per second
+	Time             time.Time
+}
+
+// GeoOptions ...
+type GeoOptions struct {
+	// HighAccuracy asks for GPS over network location, at a battery cost.
+	HighAccuracy bool
+	// Timeout limits each reading; 0 waits indefinitely.
+	Timeout time.Duration
+	// MaxAge accepts a cached reading up to this old.
+	MaxAge time.Duration
+	// Interval is the minimum time between the readings Watch delivers.
+	Interval time.Duration
+}
+
+func (o GeoOptions) jsValue() map[string]interface{} {
+	m := map[string]interface{}{
+		geoOption__highAccuracy: o.HighAccuracy,
+		geoOption__maximumAge:   durationMs(o.MaxAge),
+	}
+	if o.Timeout > 0 {
+		m[geoOption__timeout] = durationMs(o.Timeout)
+	}
+	return m
+}
+
+// Geolocation wraps navigator.geolocation, which needs a secure context.
+type Geolocation struct {
+	win *Window
+}
+
+// Geolocation ...
+func (w *Window) Geolocation() *Geolocation {
+	return &Geolocation{win: w}
+}
+
+// Permission returns the geolocation permission state without prompting.
+func (g *Geolocation) Permission(_ctx context.Context) (PermissionState, error) {
+	return QueryPermission(_ctx, PERMISSION__geolocation)
+}
+
+// CurrentPosition prompts for permission if needed and returns one reading.
+// A refused prompt returns an error wrapping GOWEB_ERROR_SENSOR_DENIED.
+func (g *Geolocation) CurrentPosition(_ctx context.Context, _opts GeoOptions) (Position, error) {
+	geo, err := navigatorGet(navigator__geolocation)
+	if err != nil {
+		return Position{}, fmt.Errorf("[geolocation] [CurrentPosition] [error]: %w", err)
+	}
+	p, resolve, reject := deferred()
+	if _, err = callJS(geo, function__getCurrentPosition, resolve, reject, _opts.jsValue()); err != nil {
+		return Position{}, fmt.Errorf("[geolocation] [CurrentPosition] [error]: %v", err)
+	}
+	v, err := Await(_ctx, p.Value)
+	if err != nil {
+		return Position{}, fmt.Errorf("[geolocation] [CurrentPosition] [error]: %w", geoError(err))
+	}
+	return newPosition(v), nil
+}
+
+// Watch delivers readings as the device moves, at most one per
+// _opts.Interval. The channel keeps only the latest reading, so a slow reader
+// skips stale ones rather than blocking the browser. It is closed when _ctx
+// is done, the returned func is called or the permission is refused; a
+// permission already denied is returned as an error wrapping
+// GOWEB_ERROR_SENSOR_DENIED.
+func (g *Geolocation) Watch(_ctx context.Context, _opts GeoOptions) (<-chan Position, func(), error) {
+	geo, err := navigatorGet(navigator__geolocation)
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("[geolocation] [Watch] [error]: %w", err)
+	}
+	if s, _ := g.Permission(_ctx); s == PermissionDenied {
+		return nil, func() {}, fmt.Errorf("[geolocation] [Watch] [error]: %w", GOWEB_ERROR_SENSOR_DENIED)
+	}
+
+	ch := make(chan Position, 1)
+	th := throttle{interval: _opts.Interval}
+	var once sync.Once
+	var okFn, failFn js.Func
+	var id js.Value
+	stopped := make(chan struct{})
+	stop := func() {
+		once.Do(func() {
+			geo.Call(function__clearWatch, id)
+			okFn.Release()
+			failFn.Release()
+			close(ch)
+			close(stopped)
+		})
+	}
+
+	okFn = js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		if len(_args) > 0 && th.ready() {
+			p := newPosition(_args[0])
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- p
+		}
+		return nil
+	})
+	// timeouts and lost signal are retried by the browser; only a refusal
+	// ends the watch
+	failFn = js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		if len(_args) > 0 && errors.Is(geoError(NewPromiseError(_args[0])), GOWEB_ERROR_SENSOR_DENIED) {
+			go stop()
+		}
+		return nil
+	})
+	if id, err = callJS(geo, function__watchPosition, okFn, failFn, _opts.jsValue()); err != nil {
+		okFn.Release()
+		failFn.Release()
+		return nil, func() {}, fmt.Errorf("[geolocation] [Watch] [error]: %v", err)
+	}
+
+	go func() {
+		select {
+		case <-_ctx.Done():
+			stop()
+		case <-stopped:
+		}
+	}()
+	return ch, stop, nil
+}
+
+func newPosition(_v js.Value) Position {
+	c := _v.Get(position__coords)
+	return Position{
+		Latitude:         floatOrZero(c.Get(coords__latitude)),
+		Longitude:        floatOrZero(c.Get(coords__longitude)),
+		Accuracy:         floatOrZero(c.Get(coords__accuracy)),
+		Altitude:         floatOrNaN(c.Get(coords__altitude)),
+		AltitudeAccuracy: floatOrNaN(c.Get(coords__altitudeAccuracy)),
+		Heading:          floatOrNaN(c.Get(coords__heading)),
+		Speed:            floatOrNaN(c.Get(coords__speed)),
+		Time:             msToTime(floatOrZero(_v.Get(position__timestamp))),
+	}
+}
+
+// geoError maps a GeolocationPositionError code onto the sentinel errors.
+func geoError(_err error) error {
+	var perr *PromiseError
+	if !errors.As(_err, &perr) {
+		return _err
+	}
+	switch perr.Code {
+	case geoError__permissionDenied:
+		return GOWEB_ERROR_SENSOR_DENIED
+	case geoError__unavailable:
+		return fmt.Errorf("%w: %s", GOWEB_ERROR_GEO_UNAVAILABLE, perr.Message)
+	case geoError__timeout:
+		return GOWEB_ERROR_GEO_TIMEOUT
+	}
+	return _err
+}
+
+func floatOrNaN(_v js.Value) float64 {
+	if _v.Type() != js.TypeNumber {
+		return math.NaN()
+	}
+	return _v.Float()
+}
+
+// throttle passes at most one reading per interval.
+type throttle struct {
+	interval time.Duration
+	last     time.Duration
+}
+
+func (t *throttle) ready() bool {
+	if t.interval <= 0 {
+		return true
+	}
+	now := Now()
+	if t.last != 0 && now-t.last < t.interval {
+		return false
+	}
+	t.last = now
+	return true
+}
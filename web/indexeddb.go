@@ -0,0 +1,712 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+const (
+	indexedDB   = "indexedDB"
+	idbKeyRange = "IDBKeyRange"
+
+	function__open              = "open"
+	function__deleteDatabase    = "deleteDatabase"
+	function__transaction       = "transaction"
+	function__objectStore       = "objectStore"
+	function__createObjectStore = "createObjectStore"
+	function__deleteObjectStore = "deleteObjectStore"
+	function__createIndex       = "createIndex"
+	function__deleteIndex       = "deleteIndex"
+	function__index             = "index"
+	function__put               = "put"
+	function__add               = "add"
+	function__count             = "count"
+	function__openCursor        = "openCursor"
+	function__continue          = "continue"
+	function__update            = "update"
+	function__close             = "close"
+	function__only              = "only"
+	function__bound             = "bound"
+	function__lowerBound        = "lowerBound"
+	function__upperBound        = "upperBound"
+
+	idb__result           = "result"
+	idb__error            = "error"
+	idb__transaction      = "transaction"
+	idb__objectStoreNames = "objectStoreNames"
+	idb__oldVersion       = "oldVersion"
+	idb__newVersion       = "newVersion"
+	idb__version          = "version"
+	idb__onsuccess        = "onsuccess"
+	idb__onerror          = "onerror"
+	idb__onupgradeneeded  = "onupgradeneeded"
+	idb__oncomplete       = "oncomplete"
+	idb__onabort          = "onabort"
+	idb__success          = "success"
+	idb__upgradeneeded    = "upgradeneeded"
+	idb__blocked          = "blocked"
+	idb__versionchange    = "versionchange"
+	idb__keyPath          = "keyPath"
+	idb__autoIncrement    = "autoIncrement"
+	idb__unique           = "unique"
+	idb__multiEntry       = "multiEntry"
+
+	cursor__key        = "key"
+	cursor__primaryKey = "primaryKey"
+	cursor__value      = "value"
+)
+
+// GOWEB_ERROR_IDB_BLOCKED is returned by OpenDB when a connection in another
+// tab keeps an older version of the database open.
+var GOWEB_ERROR_IDB_BLOCKED = errors.New("database upgrade blocked by another connection")
+
+// TxMode is the mode an IndexedDB transaction is opened with.
+type TxMode string
+
+const (
+	ReadOnly  TxMode = "readonly"
+	ReadWrite TxMode = "readwrite"
+)
+
+// CursorDirection ...
+type CursorDirection string
+
+const (
+	CursorNext       CursorDirection = "next"
+	CursorNextUnique CursorDirection = "nextunique"
+	CursorPrev       CursorDirection = "prev"
+	CursorPrevUnique CursorDirection = "prevunique"
+)
+
+// StoreOptions map to the createObjectStore options. An empty KeyPath means
+// keys are given explicitly (or generated, with AutoIncrement).
+type StoreOptions struct {
+	KeyPath       string
+	AutoIncrement bool
+}
+
+// IndexOptions map to the createIndex options.
+type IndexOptions struct {
+	Unique     bool
+	MultiEntry bool
+}
+
+// DB is an open IndexedDB database. When another tab opens a newer version,
+// the connection closes itself so the upgrade can go ahead; calls made after
+// that fail and the database has to be opened again.
+type DB struct {
+	Name    string
+	Version int
+
+	value js.Value
+	off   func()
+}
+
+// Upgrade is handed to the OpenDB upgrade func while the database runs its
+// versionchange transaction. It is the only place stores and indexes can be
+// created or removed.
+type Upgrade struct {
+	DB         *DB
+	OldVersion int
+	NewVersion int
+
+	tx js.Value
+}
+
+// OpenDB opens (creating or upgrading as needed) the named database. When the
+// stored version is lower than _version, _upgrade runs inside the versionchange
+// transaction; it must not block or await, and returning an error aborts the
+// upgrade and fails the open. When a connection in another tab holds an older
+// version open and does not close it, OpenDB returns an error wrapping
+// GOWEB_ERROR_IDB_BLOCKED instead of waiting.
+//
+// Like every call here that returns once the browser answers, OpenDB must be
+// called from a goroutine, never directly inside a js.Func callback.
+func OpenDB(_ctx context.Context, _name string, _version int, _upgrade func(*Upgrade) error) (*DB, error) {
+	factory := js.Global().Get(indexedDB)
+	if err := ValidJSValue(indexedDB, factory); err != nil {
+		return nil, fmt.Errorf("[indexedDB] [OpenDB] [%s] [error]: %v", _name, err)
+	}
+	req, err := callJS(factory, function__open, _name, _version)
+	if err != nil {
+		return nil, fmt.Errorf("[indexedDB] [OpenDB] [%s] [error]: %v", _name, err)
+	}
+
+	var upgradeErr error
+	upgrade := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		evt := firstArg(_args)
+		u := &Upgrade{
+			DB:         &DB{Name: _name, Version: _version, value: req.Get(idb__result)},
+			OldVersion: evt.Get(idb__oldVersion).Int(),
+			NewVersion: evt.Get(idb__newVersion).Int(),
+			tx:         req.Get(idb__transaction),
+		}
+		if _upgrade == nil {
+			return nil
+		}
+		if upgradeErr = _upgrade(u); upgradeErr != nil {
+			u.tx.Call(function__abort)
+		}
+		return nil
+	})
+	defer upgrade.Release()
+	req.Set(idb__onupgradeneeded, upgrade)
+
+	ctx, cancel := context.WithCancel(_ctx)
+	defer cancel()
+	blocked := false
+	offBlocked := listen(req, idb__blocked, func(Event) {
+		blocked = true
+		cancel()
+	}, ListenerOptions{})
+	defer offBlocked()
+
+	tv, err := requestPromise(req).Await(ctx)
+	req.Set(idb__onupgradeneeded, js.Null())
+	if upgradeErr != nil {
+		return nil, fmt.Errorf("[indexedDB] [OpenDB] [%s] [upgrade] [error]: %v", _name, upgradeErr)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			abandonOpen(req)
+		}
+		if blocked {
+			return nil, fmt.Errorf("[indexedDB] [OpenDB] [%s] [error]: %w", _name, GOWEB_ERROR_IDB_BLOCKED)
+		}
+		return nil, fmt.Errorf("[indexedDB] [OpenDB] [%s] [error]: %v", _name, err)
+	}
+
+	db := &DB{Name: _name, Version: tv.Get(idb__version).Int(), value: tv}
+	db.off = listen(tv, idb__versionchange, func(Event) { db.Close() }, ListenerOptions{})
+	return db, nil
+}
+
+// abandonOpen makes an open request OpenDB gave up on harmless should it
+// still go through: a late upgrade is aborted, so the version is not bumped
+// without its stores, and a late connection is closed.
+func abandonOpen(_req js.Value) {
+	var offs []func()
+	stop := func() {
+		for _, off := range offs {
+			off()
+		}
+	}
+	offs = append(offs,
+		listen(_req, idb__upgradeneeded, func(Event) {
+			_req.Get(idb__transaction).Call(function__abort)
+		}, ListenerOptions{}),
+		listen(_req, idb__success, func(Event) {
+			_req.Get(idb__result).Call(function__close)
+			stop()
+		}, ListenerOptions{}),
+		listen(_req, EVENT__error, func(Event) { stop() }, ListenerOptions{}),
+	)
+}
+
+// DeleteDB deletes the named database. It waits while other connections keep
+// the database open.
+func DeleteDB(_ctx context.Context, _name string) error {
+	factory := js.Global().Get(indexedDB)
+	if err := ValidJSValue(indexedDB, factory); err != nil {
+		return fmt.Errorf("[indexedDB] [DeleteDB] [%s] [error]: %v", _name, err)
+	}
+	req, err := callJS(factory, function__deleteDatabase, _name)
+	if err != nil {
+		return fmt.Errorf("[indexedDB] [DeleteDB] [%s] [error]: %v", _name, err)
+	}
+	if _, err = requestPromise(req).Await(_ctx); err != nil {
+		return fmt.Errorf("[indexedDB] [DeleteDB] [%s] [error]: %v", _name, err)
+	}
+	return nil
+}
+
+// Close ...
+func (db *DB) Close() {
+	db.value.Call(function__close)
+	if db.off != nil {
+		db.off()
+	}
+}
+
+// HasStore ...
+func (db *DB) HasStore(_name string) bool {
+	return db.value.Get(idb__objectStoreNames).Call(function__contains, _name).Bool()
+}
+
+// Transaction starts a transaction over the named stores. The transaction
+// commits on its own once no request is pending, so requests must be issued
+// without awaiting anything unrelated in between.
+func (db *DB) Transaction(_mode TxMode, _stores ...string) (*Tx, error) {
+	names := make([]interface{}, len(_stores))
+	for i, s := range _stores {
+		names[i] = s
+	}
+	tv, err := callJS(db.value, function__transaction, names, string(_mode))
+	if err != nil {
+		return nil, fmt.Errorf("[indexedDB] [%s] [Transaction] [error]: %v", db.Name, err)
+	}
+	return newTx(tv), nil
+}
+
+// Get reads the record under _key from _store into _dst. It reports false when
+// there is no such record.
+func (db *DB) Get(_ctx context.Context, _store string, _key interface{}, _dst interface{}) (bool, error) {
+	st, err := db.store(ReadOnly, _store)
+	if err != nil {
+		return false, fmt.Errorf("[indexedDB] [%s] [Get] [error]: %v", db.Name, err)
+	}
+	tv, err := st.Get(_key).Await(_ctx)
+	if err != nil {
+		return false, fmt.Errorf("[indexedDB] [%s] [Get] [error]: %v", db.Name, err)
+	}
+	if tv.IsUndefined() {
+		return false, nil
+	}
+	if err = Unmarshal(tv, _dst); err != nil {
+		return true, fmt.Errorf("[indexedDB] [%s] [Get] [error]: %v", db.Name, err)
+	}
+	return true, nil
+}
+
+// GetAll reads every record of _store into _dst, a pointer to a slice.
+func (db *DB) GetAll(_ctx context.Context, _store string, _dst interface{}) error {
+	st, err := db.store(ReadOnly, _store)
+	if err != nil {
+		return fmt.Errorf("[indexedDB] [%s] [GetAll] [error]: %v", db.Name, err)
+	}
+	tv, err := st.GetAll(nil).Await(_ctx)
+	if err != nil {
+		return fmt.Errorf("[indexedDB] [%s] [GetAll] [error]: %v", db.Name, err)
+	}
+	if err = Unmarshal(tv, _dst); err != nil {
+		return fmt.Errorf("[indexedDB] [%s] [GetAll] [error]: %v", db.Name, err)
+	}
+	return nil
+}
+
+// Put writes _v to _store in its own transaction and waits for it to commit.
+// _key is only given for stores without a key path. It returns the record key.
+func (db *DB) Put(_ctx context.Context, _store string, _v interface{}, _key ...interface{}) (js.Value, error) {
+	tx, err := db.Transaction(ReadWrite, _store)
+	if err != nil {
+		return js.Undefined(), fmt.Errorf("[indexedDB] [%s] [Put] [error]: %v", db.Name, err)
+	}
+	st, err := tx.Store(_store)
+	if err != nil {
+		return js.Undefined(), fmt.Errorf("[indexedDB] [%s] [Put] [error]: %v", db.Name, err)
+	}
+	req := st.Put(_v, _key...)
+	if err = tx.Wait(_ctx); err != nil {
+		return js.Undefined(), fmt.Errorf("[indexedDB] [%s] [Put] [error]: %v", db.Name, err)
+	}
+	return req.Await(_ctx)
+}
+
+// Delete removes the record under _key from _store and waits for the commit.
+func (db *DB) Delete(_ctx context.Context, _store string, _key interface{}) error {
+	tx, err := db.Transaction(ReadWrite, _store)
+	if err != nil {
+		return fmt.Errorf("[indexedDB] [%s] [Delete] [error]: %v", db.Name, err)
+	}
+	st, err := tx.Store(_store)
+	if err != nil {
+		return fmt.Errorf("[indexedDB] [%s] [Delete] [error]: %v", db.Name, err)
+	}
+	st.Delete(_key)
+	if err = tx.Wait(_ctx); err != nil {
+		return fmt.Errorf("[indexedDB] [%s] [Delete] [error]: %v", db.Name, err)
+	}
+	return nil
+}
+
+func (db *DB) store(_mode TxMode, _name string) (*ObjectStore, error) {
+	tx, err := db.Transaction(_mode, _name)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Store(_name)
+}
+
+// Tx is an IndexedDB transaction. Its completion is tracked from creation, so
+// Wait may be called after the requests have been issued.
+type Tx struct {
+	value js.Value
+	done  *Promise
+}
+
+func newTx(_v js.Value) *Tx {
+	tx := &Tx{value: _v}
+	tx.done = eventPromise(_v, idb__oncomplete, func(_evt js.Value) js.Value {
+		if e := _v.Get(idb__error); ValidJSValue(idb__error, e) == nil {
+			return e
+		}
+		if ValidJSValue(idb__error, _evt) == nil {
+			if e := _evt.Get(event__target).Get(idb__error); ValidJSValue(idb__error, e) == nil {
+				return e
+			}
+		}
+		return js.Global().Get("Error").New("transaction aborted")
+	}, idb__onerror, idb__onabort)
+	return tx
+}
+
+// Store returns one of the stores the transaction was opened over.
+func (tx *Tx) Store(_name string) (*ObjectStore, error) {
+	tv, err := callJS(tx.value, function__objectStore, _name)
+	if err != nil {
+		return nil, fmt.Errorf("[indexedDB] [Store] [%s] [error]: %v", _name, err)
+	}
+	return &ObjectStore{Name: _name, value: tv}, nil
+}
+
+// Abort rolls back every change made in the transaction.
+func (tx *Tx) Abort() {
+	callJS(tx.value, function__abort)
+}
+
+// Wait blocks until the transaction commits or fails.
+func (tx *Tx) Wait(_ctx context.Context) error {
+	_, err := tx.done.Await(_ctx)
+	return err
+}
+
+// CreateStore ...
+func (u *Upgrade) CreateStore(_name string, _opts StoreOptions) (*ObjectStore, error) {
+	opts := map[string]interface{}{idb__autoIncrement: _opts.AutoIncrement}
+	if _opts.KeyPath != "" {
+		opts[idb__keyPath] = _opts.KeyPath
+	}
+	tv, err := callJS(u.DB.value, function__createObjectStore, _name, opts)
+	if err != nil {
+		return nil, fmt.Errorf("[indexedDB] [CreateStore] [%s] [error]: %v", _name, err)
+	}
+	return &ObjectStore{Name: _name, value: tv}, nil
+}
+
+// DeleteStore ...
+func (u *Upgrade) DeleteStore(_name string) error {
+	if _, err := callJS(u.DB.value, function__deleteObjectStore, _name); err != nil {
+		return fmt.Errorf("[indexedDB] [DeleteStore] [%s] [error]: %v", _name, err)
+	}
+	return nil
+}
+
+// Store returns an existing store so its indexes can be changed.
+func (u *Upgrade) Store(_name string) (*ObjectStore, error) {
+	tv, err := callJS(u.tx, function__objectStore, _name)
+	if err != nil {
+		return nil, fmt.Errorf("[indexedDB] [Store] [%s] [error]: %v", _name, err)
+	}
+	return &ObjectStore{Name: _name, value: tv}, nil
+}
+
+// ObjectStore ...
+type ObjectStore struct {
+	Name string
+
+	value js.Value
+}
+
+// Put inserts or replaces _v. _key is only given for stores without a key path.
+func (s *ObjectStore) Put(_v interface{}, _key ...interface{}) *Promise {
+	return s.request(function__put, append([]interface{}{Marshal(_v)}, keyArgs(_key)...)...)
+}
+
+// Add inserts _v and fails when the key already exists.
+func (s *ObjectStore) Add(_v interface{}, _key ...interface{}) *Promise {
+	return s.request(function__add, append([]interface{}{Marshal(_v)}, keyArgs(_key)...)...)
+}
+
+// Get resolves with the record under _key, or undefined.
+func (s *ObjectStore) Get(_key interface{}) *Promise {
+	return s.request(function__get, Marshal(_key))
+}
+
+// GetAll resolves with an array of the records matching _query (a key or a
+// KeyRange); nil matches every record.
+func (s *ObjectStore) GetAll(_query interface{}) *Promise {
+	return s.request(function__getAll, queryArg(_query))
+}
+
+// Delete ...
+func (s *ObjectStore) Delete(_key interface{}) *Promise {
+	return s.request(function__delete, Marshal(_key))
+}
+
+// Clear ...
+func (s *ObjectStore) Clear() *Promise {
+	return s.request(function__clear)
+}
+
+// Count resolves with the number of records matching _query; nil counts all.
+func (s *ObjectStore) Count(_query interface{}) *Promise {
+	return s.request(function__count, queryArg(_query))
+}
+
+// CreateIndex adds an index on _keyPath. It is only valid inside an upgrade.
+func (s *ObjectStore) CreateIndex(_name, _keyPath string, _opts IndexOptions) (*Index, error) {
+	tv, err := callJS(s.value, function__createIndex, _name, _keyPath, map[string]interface{}{
+		idb__unique:     _opts.Unique,
+		idb__multiEntry: _opts.MultiEntry,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[indexedDB] [%s] [CreateIndex] [%s] [error]: %v", s.Name, _name, err)
+	}
+	return &Index{Name: _name, value: tv}, nil
+}
+
+// DeleteIndex removes an index. It is only valid inside an upgrade.
+func (s *ObjectStore) DeleteIndex(_name string) error {
+	if _, err := callJS(s.value, function__deleteIndex, _name); err != nil {
+		return fmt.Errorf("[indexedDB] [%s] [DeleteIndex] [%s] [error]: %v", s.Name, _name, err)
+	}
+	return nil
+}
+
+// Index ...
+func (s *ObjectStore) Index(_name string) (*Index, error) {
+	tv, err := callJS(s.value, function__index, _name)
+	if err != nil {
+		return nil, fmt.Errorf("[indexedDB] [%s] [Index] [%s] [error]: %v", s.Name, _name, err)
+	}
+	return &Index{Name: _name, value: tv}, nil
+}
+
+// OpenCursor walks the records matching _query (nil for all) in _dir order,
+// calling _fn for each one until it returns false. _fn runs inside the
+// transaction and must not block. The promise resolves once the walk ends.
+func (s *ObjectStore) OpenCursor(_query interface{}, _dir CursorDirection, _fn func(*Cursor) bool) *Promise {
+	return openCursor(s.value, _query, _dir, _fn)
+}
+
+func (s *ObjectStore) request(_method string, _args ...interface{}) *Promise {
+	req, err := callJS(s.value, _method, _args...)
+	if err != nil {
+		return RejectedPromise(err.(js.Error).Value)
+	}
+	return requestPromise(req)
+}
+
+// Index is an index over an object store.
+type Index struct {
+	Name string
+
+	value js.Value
+}
+
+// Get resolves with the first record whose index key matches _key.
+func (i *Index) Get(_key interface{}) *Promise {
+	return i.request(function__get, Marshal(_key))
+}
+
+// GetAll resolves with every record matching _query; nil matches all.
+func (i *Index) GetAll(_query interface{}) *Promise {
+	return i.request(function__getAll, queryArg(_query))
+}
+
+// Count ...
+func (i *Index) Count(_query interface{}) *Promise {
+	return i.request(function__count, queryArg(_query))
+}
+
+// OpenCursor is ObjectStore.OpenCursor in index order.
+func (i *Index) OpenCursor(_query interface{}, _dir CursorDirection, _fn func(*Cursor) bool) *Promise {
+	return openCursor(i.value, _query, _dir, _fn)
+}
+
+func (i *Index) request(_method string, _args ...interface{}) *Promise {
+	req, err := callJS(i.value, _method, _args...)
+	if err != nil {
+		return RejectedPromise(err.(js.Error).Value)
+	}
+	return requestPromise(req)
+}
+
+// Cursor is the current position of an OpenCursor walk.
+type Cursor struct {
+	value js.Value
+}
+
+// Key is the cursor key (the index key when walking an index).
+func (c *Cursor) Key() js.Value {
+	return c.value.Get(cursor__key)
+}
+
+// PrimaryKey ...
+func (c *Cursor) PrimaryKey() js.Value {
+	return c.value.Get(cursor__primaryKey)
+}
+
+// Value ...
+func (c *Cursor) Value() js.Value {
+	return c.value.Get(cursor__value)
+}
+
+// Decode unmarshals the current record into _v.
+func (c *Cursor) Decode(_v interface{}) error {
+	return Unmarshal(c.Value(), _v)
+}
+
+// Update replaces the current record. Only valid in a readwrite transaction.
+func (c *Cursor) Update(_v interface{}) error {
+	if _, err := callJS(c.value, function__update, Marshal(_v)); err != nil {
+		return fmt.Errorf("[indexedDB] [cursor] [Update] [error]: %v", err)
+	}
+	return nil
+}
+
+// Delete removes the current record. Only valid in a readwrite transaction.
+func (c *Cursor) Delete() error {
+	if _, err := callJS(c.value, function__delete); err != nil {
+		return fmt.Errorf("[indexedDB] [cursor] [Delete] [error]: %v", err)
+	}
+	return nil
+}
+
+// KeyRange builds an IDBKeyRange for GetAll, Count and OpenCursor queries.
+type KeyRange struct {
+	js.Value
+}
+
+// KeyOnly matches a single key.
+func KeyOnly(_key interface{}) KeyRange {
+	return KeyRange{js.Global().Get(idbKeyRange).Call(function__only, Marshal(_key))}
+}
+
+// KeyBound matches keys between _lower and _upper, optionally excluding either.
+func KeyBound(_lower, _upper interface{}, _lowerOpen, _upperOpen bool) KeyRange {
+	return KeyRange{js.Global().Get(idbKeyRange).Call(function__bound, Marshal(_lower), Marshal(_upper), _lowerOpen, _upperOpen)}
+}
+
+// KeyLowerBound matches keys above _lower.
+func KeyLowerBound(_lower interface{}, _open bool) KeyRange {
+	return KeyRange{js.Global().Get(idbKeyRange).Call(function__lowerBound, Marshal(_lower), _open)}
+}
+
+// KeyUpperBound matches keys below _upper.
+func KeyUpperBound(_upper interface{}, _open bool) KeyRange {
+	return KeyRange{js.Global().Get(idbKeyRange).Call(function__upperBound, Marshal(_upper), _open)}
+}
+
+func queryArg(_q interface{}) interface{} {
+	switch q := _q.(type) {
+	case nil:
+		return js.Undefined()
+	case KeyRange:
+		return q.Value
+	}
+	return Marshal(_q)
+}
+
+func keyArgs(_key []interface{}) []interface{} {
+	if len(_key) == 0 || _key[0] == nil {
+		return nil
+	}
+	return []interface{}{Marshal(_key[0])}
+}
+
+func openCursor(_source js.Value, _query interface{}, _dir CursorDirection, _fn func(*Cursor) bool) *Promise {
+	if _dir == "" {
+		_dir = CursorNext
+	}
+	req, err := callJS(_source, function__openCursor, queryArg(_query), string(_dir))
+	if err != nil {
+		return RejectedPromise(err.(js.Error).Value)
+	}
+	return eventPromiseStep(req, func() (js.Value, bool) {
+		cur := req.Get(idb__result)
+		if ValidJSValue(idb__result, cur) != nil {
+			return js.Undefined(), true
+		}
+		if !_fn(&Cursor{value: cur}) {
+			return js.Undefined(), true
+		}
+		cur.Call(function__continue)
+		return js.Undefined(), false
+	})
+}
+
+// requestPromise turns an IDBRequest into a promise of its result.
+func requestPromise(_req js.Value) *Promise {
+	return eventPromiseStep(_req, func() (js.Value, bool) {
+		return _req.Get(idb__result), true
+	})
+}
+
+// eventPromiseStep settles a promise from an IDBRequest. _step runs on every
+// success event and reports whether the request is finished; cursors fire
+// success once per record.
+func eventPromiseStep(_req js.Value, _step func() (js.Value, bool)) *Promise {
+	p, resolve, reject := deferred()
+	var ok, fail js.Func
+	release := func() {
+		_req.Set(idb__onsuccess, js.Null())
+		_req.Set(idb__onerror, js.Null())
+		ok.Release()
+		fail.Release()
+	}
+	ok = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		if v, done := _step(); done {
+			release()
+			resolve.Invoke(v)
+		}
+		return nil
+	})
+	fail = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		e := _req.Get(idb__error)
+		release()
+		reject.Invoke(e)
+		return nil
+	})
+
+	_req.Set(idb__onsuccess, ok)
+	_req.Set(idb__onerror, fail)
+	return p
+}
+
+// eventPromise settles a promise from one success and any number of failure
+// event handlers on _target; _err maps the failure event to the rejection value.
+func eventPromise(_target js.Value, _success string, _err func(js.Value) js.Value, _failures ...string) *Promise {
+	p, resolve, reject := deferred()
+	var handlers []js.Func
+	release := func() {
+		_target.Set(_success, js.Null())
+		for _, f := range _failures {
+			_target.Set(f, js.Null())
+		}
+		for _, h := range handlers {
+			h.Release()
+		}
+	}
+	settled := false
+	ok := js.FuncOf(func(js.Value, []js.Value) interface{} {
+		if settled {
+			return nil
+		}
+		settled = true
+		release()
+		resolve.Invoke()
+		return nil
+	})
+	fail := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		if settled {
+			return nil
+		}
+		settled = true
+		e := _err(firstArg(_args))
+		release()
+		reject.Invoke(e)
+		return nil
+	})
+	handlers = append(handlers, ok, fail)
+
+	_target.Set(_success, ok)
+	for _, f := range _failures {
+		_target.Set(f, fail)
+	}
+	return p
+}
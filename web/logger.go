@@ -3,48 +3,570 @@
 package web
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"syscall/js"
+	"time"
 )
 
+const (
+	console = "console"
+
+	console__log            = "log"
+	console__debug          = "debug"
+	console__info           = "info"
+	console__warn           = "warn"
+	console__error          = "error"
+	console__group          = "group"
+	console__groupCollapsed = "groupCollapsed"
+	console__groupEnd       = "groupEnd"
+	console__time           = "time"
+	console__timeEnd        = "timeEnd"
+	console__table          = "table"
+)
+
+// Level is a log severity. Entries below the logger's level are dropped.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelOff
+)
+
+// String ...
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelOff:
+		return "OFF"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// MarshalText lets entries carry the level name when encoded as JSON.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// Field is one key/value pair attached with Logger.With.
+type Field struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON encodes an error value as its message; most error types have no
+// exported fields and would otherwise encode as {}.
+func (f Field) MarshalJSON() ([]byte, error) {
+	v := f.Value
+	if err, ok := v.(error); ok {
+		if _, ok := v.(json.Marshaler); !ok {
+			v = err.Error()
+		}
+	}
+	return json.Marshal(struct {
+		Key   string      `json:"key"`
+		Value interface{} `json:"value"`
+	}{f.Key, v})
+}
+
+// Entry is a single log record as handed to every Sink.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Prefix  string    `json:"prefix,omitempty"`
+	Message string    `json:"message"`
+	Fields  []Field   `json:"fields,omitempty"`
+}
+
+// FieldMap returns the entry fields as a map; later keys win.
+func (e Entry) FieldMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(e.Fields))
+	for _, f := range e.Fields {
+		m[f.Key] = f.Value
+	}
+	return m
+}
+
+// Sink receives every entry at or above the logger level. Log is called
+// synchronously, possibly from inside a js.Func callback, so it must not block.
+type Sink interface {
+	Log(Entry)
+}
+
+// logCore is shared by a logger and every logger derived from it with With, so
+// SetLevel and the sinks apply to all of them.
+type logCore struct {
+	mu    sync.Mutex
+	level Level
+	sinks []Sink
+}
+
+// Logger ...
 type Logger struct {
 	Prefix string
 	log    js.Value
+
+	fields []Field
+	core   *logCore
 }
 
+// NewLogger returns a logger at LevelDebug that writes to the console.
 func NewLogger() *Logger {
-	return &Logger{log: js.Global().Get("console")}
+	c := js.Global().Get(console)
+	return &Logger{
+		log: c,
+		core: &logCore{
+			level: LevelDebug,
+			sinks: []Sink{&ConsoleSink{value: c}},
+		},
+	}
 }
 
+// SetLevel sets the minimum level for this logger and every logger derived
+// from it.
+func (l *Logger) SetLevel(_lvl Level) {
+	l.core.mu.Lock()
+	l.core.level = _lvl
+	l.core.mu.Unlock()
+}
+
+// Level ...
+func (l *Logger) Level() Level {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	return l.core.level
+}
+
+// Enabled reports whether entries at _lvl are logged.
+func (l *Logger) Enabled(_lvl Level) bool {
+	return _lvl != LevelOff && _lvl >= l.Level()
+}
+
+// AddSink adds a sink next to the existing ones.
+func (l *Logger) AddSink(_s Sink) {
+	l.core.mu.Lock()
+	l.core.sinks = append(l.core.sinks, _s)
+	l.core.mu.Unlock()
+}
+
+// SetSinks replaces every sink, the console one included.
+func (l *Logger) SetSinks(_s ...Sink) {
+	l.core.mu.Lock()
+	l.core.sinks = append([]Sink{}, _s...)
+	l.core.mu.Unlock()
+}
+
+// With returns a logger that attaches the given key/value pairs to every
+// entry. _kv alternates keys and values; a key that is not a string is
+// formatted with %v and a trailing key gets a nil value.
+func (l *Logger) With(_kv ...interface{}) *Logger {
+	fields := make([]Field, len(l.fields), len(l.fields)+len(_kv)/2+1)
+	copy(fields, l.fields)
+	for i := 0; i < len(_kv); i += 2 {
+		f := Field{Key: fmt.Sprint(_kv[i])}
+		if i+1 < len(_kv) {
+			f.Value = _kv[i+1]
+		}
+		fields = append(fields, f)
+	}
+	return &Logger{Prefix: l.Prefix, log: l.log, fields: fields, core: l.core}
+}
+
+func (l *Logger) emit(_lvl Level, _msg string) {
+	l.core.mu.Lock()
+	if _lvl == LevelOff || _lvl < l.core.level {
+		l.core.mu.Unlock()
+		return
+	}
+	sinks := l.core.sinks
+	l.core.mu.Unlock()
+
+	e := Entry{
+		Time:    time.Now(),
+		Level:   _lvl,
+		Prefix:  l.Prefix,
+		Message: _msg,
+		Fields:  l.fields,
+	}
+	for _, s := range sinks {
+		s.Log(e)
+	}
+}
+
+// Print logs _msg at LevelInfo.
 func (l *Logger) Print(_msg string) {
-	l.log.Call("log", fmt.Sprintf("{%s} %s", l.Prefix, _msg))
+	l.emit(LevelInfo, _msg)
 }
 
 func (l *Logger) Error(_err error) {
-	l.log.Call("log", fmt.Sprintf("{%s|ERROR} %v", l.Prefix, _err))
+	l.emit(LevelError, fmt.Sprint(_err))
+}
+
+func (l *Logger) Warn(_msg string) {
+	l.emit(LevelWarn, _msg)
 }
 
 func (l *Logger) Debug(_msg string) {
-	l.log.Call("log", fmt.Sprintf("{%s|DEBUG} %s", l.Prefix, _msg))
+	l.emit(LevelDebug, _msg)
 }
 
 func (l *Logger) Info(_msg string) {
-	l.log.Call("log", fmt.Sprintf("{%s|INFO} %s", l.Prefix, _msg))
+	l.emit(LevelInfo, _msg)
 }
 
+// LogElement writes the element to the console at LevelDebug so it can be
+// inspected in DevTools. It bypasses the sinks.
 func (l *Logger) LogElement(_e *Element) {
-	l.log.Call("log", fmt.Sprintf("{%s|ELEMENT} ", l.Prefix), _e.Value)
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+	l.log.Call(console__debug, fmt.Sprintf("{%s|ELEMENT} ", l.Prefix), _e.Value)
 }
 
+// LogValue writes a raw js.Value to the console at LevelDebug.
 func (l *Logger) LogValue(_v js.Value) {
-	l.log.Call("log", _v)
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+	l.log.Call(console__debug, _v)
 }
 
+// Log passes its arguments straight to console.log at LevelDebug.
 func (l *Logger) Log(_v ...interface{}) {
-	l.log.Call("log", _v)
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+	l.log.Call(console__log, _v...)
+}
+
+// Group opens a console group and returns the func that closes it. Groups are
+// only opened at LevelDebug and LevelInfo.
+func (l *Logger) Group(_label string) func() {
+	return l.group(console__group, _label)
+}
+
+// GroupCollapsed is Group, collapsed by default.
+func (l *Logger) GroupCollapsed(_label string) func() {
+	return l.group(console__groupCollapsed, _label)
+}
+
+func (l *Logger) group(_method, _label string) func() {
+	if !l.Enabled(LevelInfo) {
+		return func() {}
+	}
+	l.log.Call(_method, fmt.Sprintf("{%s} %s", l.Prefix, _label))
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.log.Call(console__groupEnd) })
+	}
+}
+
+// Time starts a console timer and returns the func that stops and prints it.
+// Timers only run at LevelDebug.
+func (l *Logger) Time(_label string) func() {
+	if !l.Enabled(LevelDebug) {
+		return func() {}
+	}
+	label := fmt.Sprintf("{%s} %s", l.Prefix, _label)
+	l.log.Call(console__time, label)
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.log.Call(console__timeEnd, label) })
+	}
+}
+
+// Table prints _v (a slice, map or struct, converted with Marshal) with
+// console.table at LevelDebug. _columns optionally limits the columns shown.
+func (l *Logger) Table(_v interface{}, _columns ...string) {
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+	if len(_columns) == 0 {
+		l.log.Call(console__table, Marshal(_v))
+		return
+	}
+	cols := make([]interface{}, len(_columns))
+	for i, c := range _columns {
+		cols[i] = c
+	}
+	l.log.Call(console__table, Marshal(_v), cols)
 }
 
 func (l *Logger) Write(p []byte) (int, error) {
 	l.Print(string(p))
 	return len(p), nil
 }
+
+///////////////////////////////////// SINKS /////////////////////////////////////
+
+// ConsoleSink writes entries to the console method matching their level, with
+// the fields passed as a trailing object so DevTools can expand them.
+type ConsoleSink struct {
+	value js.Value
+}
+
+// NewConsoleSink ...
+func NewConsoleSink() *ConsoleSink {
+	return &ConsoleSink{value: js.Global().Get(console)}
+}
+
+// Log ...
+func (c *ConsoleSink) Log(_e Entry) {
+	method := console__log
+	switch _e.Level {
+	case LevelDebug:
+		method = console__debug
+	case LevelInfo:
+		method = console__info
+	case LevelWarn:
+		method = console__warn
+	case LevelError:
+		method = console__error
+	}
+	msg := fmt.Sprintf("{%s|%s} %s", _e.Prefix, _e.Level, _e.Message)
+	if len(_e.Fields) == 0 {
+		c.value.Call(method, msg)
+		return
+	}
+	fields := _e.FieldMap()
+	for k, v := range fields {
+		if err, ok := v.(error); ok {
+			fields[k] = err.Error()
+		}
+	}
+	c.value.Call(method, msg, Marshal(fields))
+}
+
+// RingBuffer keeps the last N entries in memory, e.g. for tests or an
+// in-app log view.
+type RingBuffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewRingBuffer ...
+func NewRingBuffer(_size int) *RingBuffer {
+	if _size < 1 {
+		_size = 1
+	}
+	return &RingBuffer{entries: make([]Entry, _size)}
+}
+
+// Log ...
+func (r *RingBuffer) Log(_e Entry) {
+	r.mu.Lock()
+	r.entries[r.next] = _e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+}
+
+// Entries returns the buffered entries, oldest first.
+func (r *RingBuffer) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Entry{}, r.entries[:r.next]...)
+	}
+	return append(append([]Entry{}, r.entries[r.next:]...), r.entries[:r.next]...)
+}
+
+// Reset ...
+func (r *RingBuffer) Reset() {
+	r.mu.Lock()
+	r.next, r.full = 0, false
+	r.mu.Unlock()
+}
+
+// RemoteSink batches entries and POSTs them as a JSON array to URL. A batch is
+// sent once it holds BatchSize entries or Interval has passed since the first
+// queued entry. Entries that arrive while the queue is full, or after Close,
+// are dropped and counted.
+type RemoteSink struct {
+	URL       string
+	BatchSize int
+	Interval  time.Duration
+
+	client  *Client
+	queue   chan Entry
+	flush   chan chan error
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	closed  bool
+	dropped int
+	err     error
+}
+
+// NewRemoteSink starts the background sender. Requests are bounded by the
+// client's Timeout. Close stops the sender after a final flush.
+func NewRemoteSink(_client *Client, _url string, _batch int, _interval time.Duration) *RemoteSink {
+	if _client == nil {
+		_client = NewClient()
+	}
+	if _batch < 1 {
+		_batch = 50
+	}
+	if _interval <= 0 {
+		_interval = 5 * time.Second
+	}
+	s := &RemoteSink{
+		URL:       _url,
+		BatchSize: _batch,
+		Interval:  _interval,
+		client:    _client,
+		queue:     make(chan Entry, _batch*4),
+		flush:     make(chan chan error),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Log queues the entry without blocking.
+func (s *RemoteSink) Log(_e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		s.dropped++
+		return
+	}
+	select {
+	case s.queue <- _e:
+	default:
+		s.dropped++
+	}
+}
+
+// Flush sends whatever is queued and waits for the request to finish. It must
+// be called from a goroutine.
+func (s *RemoteSink) Flush(_ctx context.Context) error {
+	r := make(chan error, 1)
+	select {
+	case s.flush <- r:
+	case <-s.done:
+		return fmt.Errorf("[logger] [RemoteSink] [Flush] [error]: sink closed")
+	case <-_ctx.Done():
+		return _ctx.Err()
+	}
+	select {
+	case err := <-r:
+		return err
+	case <-_ctx.Done():
+		return _ctx.Err()
+	}
+}
+
+// Close flushes the queue and stops the sender.
+func (s *RemoteSink) Close() error {
+	err := s.Flush(context.Background())
+	s.once.Do(func() {
+		// entries queued before closed is set are still drained by run
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		close(s.done)
+	})
+	return err
+}
+
+// Dropped is the number of entries lost to a full queue or logged after Close.
+func (s *RemoteSink) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Err is the last send error, if any.
+func (s *RemoteSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *RemoteSink) run() {
+	batch := make([]Entry, 0, s.BatchSize)
+	timer := time.NewTimer(s.Interval)
+	stopTimer(timer)
+
+	send := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.post(batch)
+		batch = batch[:0]
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		return err
+	}
+	drain := func() {
+		for n := len(s.queue); n > 0; n-- {
+			batch = append(batch, <-s.queue)
+		}
+	}
+
+	for {
+		select {
+		case e := <-s.queue:
+			if len(batch) == 0 {
+				timer.Reset(s.Interval)
+			}
+			batch = append(batch, e)
+			if len(batch) >= s.BatchSize {
+				stopTimer(timer)
+				send()
+			}
+		case <-timer.C:
+			send()
+		case r := <-s.flush:
+			stopTimer(timer)
+			drain()
+			r <- send()
+		case <-s.done:
+			stopTimer(timer)
+			drain()
+			send()
+			return
+		}
+	}
+}
+
+// stopTimer stops _t and drains a tick that already fired, so a later Reset
+// does not deliver it early.
+func stopTimer(_t *time.Timer) {
+	if !_t.Stop() {
+		select {
+		case <-_t.C:
+		default:
+		}
+	}
+}
+
+func (s *RemoteSink) post(_batch []Entry) error {
+	resp, err := s.client.PostJSON(context.Background(), s.URL, _batch)
+	if err != nil {
+		return fmt.Errorf("[logger] [RemoteSink] [post] [error]: %v", err)
+	}
+	resp.Body.Close()
+	if !resp.OK {
+		return fmt.Errorf("[logger] [RemoteSink] [post] [error]: %d %s", resp.Status, resp.StatusText)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"encoding/json"
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+func TestRemoteSink(t *testing.T) {
+	type entry struct {
+		Level   string
+		Message string
+		Fields  []map[string]interface{}
+	}
+	var posted [][]entry
+	fakeFetch(t, func(_url string, _init js.Value) js.Value {
+		b := make([]byte, _init.Get("body").Length())
+		js.CopyBytesToGo(b, _init.Get("body"))
+		var batch []entry
+		json.Unmarshal(b, &batch)
+		posted = append(posted, batch)
+		return js.Global().Get("Response").New(js.Null(), map[string]interface{}{"status": 204})
+	})
+
+	s := web.NewRemoteSink(nil, "/logs", 2, time.Hour)
+	l := web.NewLogger()
+	l.SetSinks(s)
+	l.With("err", errors.New("disk full"), "n", 3).Error(errors.New("save failed"))
+	l.Info("saved")
+	waitFor(t, "first batch", func() bool { return len(posted) == 1 })
+
+	l.Warn("closing")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 2 || len(posted[1]) != 1 || posted[1][0].Message != "closing" {
+		t.Fatalf("posted %v, want the queued entry flushed by Close", posted)
+	}
+	if len(posted[0]) != 2 || posted[0][0].Message != "save failed" || posted[0][0].Level != "ERROR" {
+		t.Errorf("first batch = %v", posted[0])
+	}
+	if f := posted[0][0].Fields; len(f) != 2 || f[0]["value"] != "disk full" || f[1]["value"] != 3.0 {
+		t.Errorf("fields = %v, want the error encoded as its message", f)
+	}
+
+	l.Info("too late")
+	if n := s.Dropped(); n != 1 {
+		t.Errorf("Dropped = %d after logging past Close, want 1", n)
+	}
+	if len(posted) != 2 {
+		t.Errorf("posted %d batches, want nothing sent after Close", len(posted))
+	}
+}
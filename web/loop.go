@@ -0,0 +1,216 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	function__requestAnimationFrame = "requestAnimationFrame"
+	function__cancelAnimationFrame  = "cancelAnimationFrame"
+
+	loop__fallbackFrame = 16 * time.Millisecond
+	loop__maxDelta      = 100 * time.Millisecond
+)
+
+// Frame is passed to Loop ticks.
+type Frame struct {
+	// Delta is the time since the previous frame, capped at Loop.MaxDelta.
+	Delta time.Duration
+	// Time is the frame's requestAnimationFrame timestamp.
+	Time time.Duration
+	// Count numbers the frames since Start, from 1.
+	Count uint64
+}
+
+// Loop calls Go funcs once per animation frame:
+//
+//	loop := web.NewLoop(func(_f web.Frame) {
+//		angle += speed * _f.Delta.Seconds()
+//		box.SetAttribute("rotation", map[string]interface{}{"y": angle})
+//	})
+//	loop.Start()
+//
+// Ticks run inside the requestAnimationFrame callback: they must not block
+// (start a goroutine to Await). Without requestAnimationFrame, e.g. in a
+// worker, frames are driven by setTimeout instead.
+type Loop struct {
+	// MaxDelta caps Frame.Delta so a frame after a hidden tab or a debugger
+	// pause does not teleport animations. Zero means 100ms.
+	MaxDelta time.Duration
+
+	mu      sync.Mutex
+	ticks   map[int]func(Frame)
+	order   []int
+	nextID  int
+	running bool
+	paused  bool
+	last    time.Duration
+	count   uint64
+	fn      js.Func
+	cancel  func()
+}
+
+// NewLoop returns a stopped Loop calling _tick (which may be nil) every frame.
+func NewLoop(_tick func(Frame)) *Loop {
+	l := &Loop{ticks: map[int]func(Frame){}}
+	if _tick != nil {
+		l.Add(_tick)
+	}
+	return l
+}
+
+// Add registers another per-frame func, called after those added before it.
+// The returned func removes it again.
+func (l *Loop) Add(_tick func(Frame)) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nextID++
+	id := l.nextID
+	l.ticks[id] = _tick
+	l.order = append(l.order, id)
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.ticks[id]; !ok {
+			return
+		}
+		delete(l.ticks, id)
+		for i, o := range l.order {
+			if o == id {
+				l.order = append(l.order[:i:i], l.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Start begins requesting frames. Starting a running loop does nothing.
+func (l *Loop) Start() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running {
+		return
+	}
+	l.running, l.paused = true, false
+	l.last, l.count = -1, 0
+	l.fn = js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		ts := Now()
+		if t := firstArg(_args); t.Type() == js.TypeNumber {
+			ts = msDuration(t.Float())
+		}
+		l.frame(ts)
+		return nil
+	})
+	l.request()
+}
+
+// Pause stops ticking but keeps the loop's state; Resume continues without
+// counting the paused time into the next Delta.
+func (l *Loop) Pause() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.running || l.paused {
+		return
+	}
+	l.paused = true
+	l.cancelFrame()
+}
+
+// Resume ...
+func (l *Loop) Resume() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.running || !l.paused {
+		return
+	}
+	l.paused = false
+	l.last = -1
+	l.request()
+}
+
+// Stop ends the loop and releases its js.Func. It can be started again.
+func (l *Loop) Stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.running {
+		return
+	}
+	l.running, l.paused = false, false
+	l.cancelFrame()
+	l.fn.Release()
+}
+
+// Running reports whether the loop is started (paused or not).
+func (l *Loop) Running() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running
+}
+
+// Paused ...
+func (l *Loop) Paused() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.paused
+}
+
+func (l *Loop) frame(_ts time.Duration) {
+	l.mu.Lock()
+	l.cancel = nil
+	if !l.running || l.paused {
+		l.mu.Unlock()
+		return
+	}
+	var dt time.Duration
+	if l.last >= 0 {
+		dt = _ts - l.last
+	}
+	max := l.MaxDelta
+	if max <= 0 {
+		max = loop__maxDelta
+	}
+	if dt > max {
+		dt = max
+	}
+	if dt < 0 {
+		dt = 0
+	}
+	l.last = _ts
+	l.count++
+	f := Frame{Delta: dt, Time: _ts, Count: l.count}
+	ticks := make([]func(Frame), 0, len(l.order))
+	for _, id := range l.order {
+		ticks = append(ticks, l.ticks[id])
+	}
+	// request the next frame first so a tick calling Pause or Stop wins
+	l.request()
+	l.mu.Unlock()
+
+	for _, t := range ticks {
+		t(f)
+	}
+}
+
+// request must be called with mu held.
+func (l *Loop) request() {
+	g := js.Global()
+	if g.Get(function__requestAnimationFrame).Type() != js.TypeFunction {
+		id := g.Call(function__setTimeout, l.fn, durationMs(loop__fallbackFrame))
+		l.cancel = func() { g.Call(function__clearTimeout, id) }
+		return
+	}
+	id := g.Call(function__requestAnimationFrame, l.fn)
+	l.cancel = func() { g.Call(function__cancelAnimationFrame, id) }
+}
+
+// cancelFrame must be called with mu held.
+func (l *Loop) cancelFrame() {
+	if l.cancel != nil {
+		l.cancel()
+		l.cancel = nil
+	}
+}
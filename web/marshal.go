@@ -0,0 +1,552 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	tag__js        = "js"
+	tag__omitempty = "omitempty"
+
+	object__constructor = "Object"
+	array__constructor  = "Array"
+	date__constructor   = "Date"
+
+	function__keys    = "keys"
+	function__getTime = "getTime"
+	function__isArray = "isArray"
+	function__isView  = "isView"
+
+	// Firestore Timestamp and Bytes (Blob) values
+	function__toMillis     = "toMillis"
+	function__toDate       = "toDate"
+	function__toUint8Array = "toUint8Array"
+)
+
+var (
+	GOWEB_ERROR_UNMARSHAL_TARGET = errors.New("unmarshal target must be a non-nil pointer")
+
+	jsValueType  = reflect.TypeOf(js.Value{})
+	jsFuncType   = reflect.TypeOf(js.Func{})
+	timeType     = reflect.TypeOf(time.Time{})
+	marshalerT   = reflect.TypeOf((*JSMarshaler)(nil)).Elem()
+	unmarshalerT = reflect.TypeOf((*JSUnmarshaler)(nil)).Elem()
+
+	fieldCache sync.Map // reflect.Type -> []field
+)
+
+// JSMarshaler is implemented by types that convert themselves to a js.Value.
+type JSMarshaler interface {
+	MarshalJS() js.Value
+}
+
+// JSUnmarshaler is implemented by types that read themselves from a js.Value.
+type JSUnmarshaler interface {
+	UnmarshalJS(js.Value) error
+}
+
+// UnmarshalTypeError reports a JS value that cannot be stored in a Go type.
+type UnmarshalTypeError struct {
+	JSType js.Type
+	Type   reflect.Type
+	Field  string
+}
+
+// Error ...
+func (e *UnmarshalTypeError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("[unmarshal] [%s] [error]: cannot store js %s in Go %s", e.Field, e.JSType, e.Type)
+	}
+	return fmt.Sprintf("[unmarshal] [error]: cannot store js %s in Go %s", e.JSType, e.Type)
+}
+
+// Marshal converts a Go value into a js.Value. Struct fields are named by their
+// `js:"name,omitempty"` tag (or the field name); nil pointers, slices and maps
+// become null, []byte becomes a Uint8Array and time.Time a Date.
+func Marshal(_v interface{}) js.Value {
+	if _v == nil {
+		return js.Null()
+	}
+	return marshalValue(reflect.ValueOf(_v))
+}
+
+func marshalValue(_rv reflect.Value) js.Value {
+	if !_rv.IsValid() {
+		return js.Null()
+	}
+
+	t := _rv.Type()
+	switch t {
+	case jsValueType:
+		return _rv.Interface().(js.Value)
+	case jsFuncType:
+		return _rv.Interface().(js.Func).Value
+	case timeType:
+		tm := _rv.Interface().(time.Time)
+		return js.Global().Get(date__constructor).New(float64(tm.UnixNano()) / float64(time.Millisecond))
+	}
+	if t.Implements(marshalerT) {
+		if _rv.Kind() == reflect.Ptr && _rv.IsNil() {
+			return js.Null()
+		}
+		return _rv.Interface().(JSMarshaler).MarshalJS()
+	}
+
+	switch _rv.Kind() {
+	case reflect.Bool:
+		return js.ValueOf(_rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return js.ValueOf(float64(_rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return js.ValueOf(float64(_rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return js.ValueOf(_rv.Float())
+	case reflect.String:
+		return js.ValueOf(_rv.String())
+
+	case reflect.Ptr, reflect.Interface:
+		if _rv.IsNil() {
+			return js.Null()
+		}
+		return marshalValue(_rv.Elem())
+
+	case reflect.Slice:
+		if _rv.IsNil() {
+			return js.Null()
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return uint8ArrayOf(_rv.Bytes())
+		}
+		fallthrough
+	case reflect.Array:
+		a := js.Global().Get(array__constructor).New(_rv.Len())
+		for i := 0; i < _rv.Len(); i++ {
+			a.SetIndex(i, marshalValue(_rv.Index(i)))
+		}
+		return a
+
+	case reflect.Map:
+		if _rv.IsNil() {
+			return js.Null()
+		}
+		o := js.Global().Get(object__constructor).New()
+		iter := _rv.MapRange()
+		for iter.Next() {
+			k, ok := mapKeyString(iter.Key())
+			if !ok {
+				continue
+			}
+			o.Set(k, marshalValue(iter.Value()))
+		}
+		return o
+
+	case reflect.Struct:
+		o := js.Global().Get(object__constructor).New()
+		for _, f := range cachedFields(t) {
+			fv := _rv.FieldByIndex(f.index)
+			if f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			o.Set(f.name, marshalValue(fv))
+		}
+		return o
+	}
+
+	return js.Undefined()
+}
+
+// Unmarshal stores the JS value _v in the Go value pointed to by _dst, using the
+// same naming rules as Marshal. Missing object keys leave fields untouched;
+// null and undefined reset the target to its zero value. Objects with toMillis
+// or toDate (Firestore Timestamps) fill time.Time, and objects with
+// toUint8Array (Firestore Bytes) fill []byte.
+func Unmarshal(_v js.Value, _dst interface{}) error {
+	rv := reflect.ValueOf(_dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return GOWEB_ERROR_UNMARSHAL_TARGET
+	}
+	return unmarshalValue(_v, rv.Elem(), "")
+}
+
+func unmarshalValue(_v js.Value, _rv reflect.Value, _path string) error {
+	t := _rv.Type()
+
+	switch t {
+	case jsValueType:
+		_rv.Set(reflect.ValueOf(_v))
+		return nil
+	}
+	if _rv.CanAddr() && reflect.PtrTo(t).Implements(unmarshalerT) {
+		return _rv.Addr().Interface().(JSUnmarshaler).UnmarshalJS(_v)
+	}
+
+	if _v.IsNull() || _v.IsUndefined() {
+		_rv.Set(reflect.Zero(t))
+		return nil
+	}
+
+	typeErr := func() error {
+		return &UnmarshalTypeError{JSType: _v.Type(), Type: t, Field: _path}
+	}
+
+	if t == timeType {
+		switch _v.Type() {
+		case js.TypeNumber:
+			_rv.Set(reflect.ValueOf(msToTime(_v.Float())))
+		case js.TypeString:
+			tm, err := time.Parse(time.RFC3339Nano, _v.String())
+			if err != nil {
+				return fmt.Errorf("[unmarshal] [%s] [error]: %v", _path, err)
+			}
+			_rv.Set(reflect.ValueOf(tm))
+		case js.TypeObject:
+			ms, ok := timeOf(_v)
+			if !ok {
+				return typeErr()
+			}
+			_rv.Set(reflect.ValueOf(msToTime(ms)))
+		default:
+			return typeErr()
+		}
+		return nil
+	}
+
+	switch _rv.Kind() {
+	case reflect.Bool:
+		if _v.Type() != js.TypeBoolean {
+			return typeErr()
+		}
+		_rv.SetBool(_v.Bool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _v.Type() != js.TypeNumber {
+			return typeErr()
+		}
+		n := int64(_v.Float())
+		if _rv.OverflowInt(n) {
+			return typeErr()
+		}
+		_rv.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if _v.Type() != js.TypeNumber || _v.Float() < 0 {
+			return typeErr()
+		}
+		n := uint64(_v.Float())
+		if _rv.OverflowUint(n) {
+			return typeErr()
+		}
+		_rv.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		if _v.Type() != js.TypeNumber {
+			return typeErr()
+		}
+		_rv.SetFloat(_v.Float())
+
+	case reflect.String:
+		if _v.Type() != js.TypeString {
+			return typeErr()
+		}
+		_rv.SetString(_v.String())
+
+	case reflect.Ptr:
+		if _rv.IsNil() {
+			_rv.Set(reflect.New(t.Elem()))
+		}
+		return unmarshalValue(_v, _rv.Elem(), _path)
+
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			return typeErr()
+		}
+		g := genericValue(_v)
+		if g == nil {
+			_rv.Set(reflect.Zero(t))
+			return nil
+		}
+		_rv.Set(reflect.ValueOf(g))
+
+	case reflect.Slice:
+		if _v.Type() != js.TypeObject {
+			return typeErr()
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !isArray(_v) {
+			if b, ok := bytesFrom(_v); ok {
+				_rv.SetBytes(b)
+				return nil
+			}
+			return typeErr()
+		}
+		if !isArray(_v) && !isView(_v) {
+			return typeErr()
+		}
+		n := _v.Length()
+		s := reflect.MakeSlice(t, n, n)
+		for i := 0; i < n; i++ {
+			if err := unmarshalValue(_v.Index(i), s.Index(i), fmt.Sprintf("%s[%d]", _path, i)); err != nil {
+				return err
+			}
+		}
+		_rv.Set(s)
+
+	case reflect.Array:
+		if _v.Type() != js.TypeObject || (!isArray(_v) && !isView(_v)) {
+			return typeErr()
+		}
+		n := _v.Length()
+		for i := 0; i < _rv.Len(); i++ {
+			if i >= n {
+				_rv.Index(i).Set(reflect.Zero(t.Elem()))
+				continue
+			}
+			if err := unmarshalValue(_v.Index(i), _rv.Index(i), fmt.Sprintf("%s[%d]", _path, i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if _v.Type() != js.TypeObject {
+			return typeErr()
+		}
+		if _rv.IsNil() {
+			_rv.Set(reflect.MakeMap(t))
+		}
+		keys := js.Global().Get(object__constructor).Call(function__keys, _v)
+		for i := 0; i < keys.Length(); i++ {
+			k := keys.Index(i).String()
+			kv, err := mapKeyValue(k, t.Key())
+			if err != nil {
+				return fmt.Errorf("[unmarshal] [%s] [error]: %v", _path, err)
+			}
+			ev := reflect.New(t.Elem()).Elem()
+			if err = unmarshalValue(_v.Get(k), ev, joinPath(_path, k)); err != nil {
+				return err
+			}
+			_rv.SetMapIndex(kv, ev)
+		}
+
+	case reflect.Struct:
+		if _v.Type() != js.TypeObject {
+			return typeErr()
+		}
+		for _, f := range cachedFields(t) {
+			fv := _v.Get(f.name)
+			if fv.IsUndefined() {
+				continue
+			}
+			if err := unmarshalValue(fv, _rv.FieldByIndex(f.index), joinPath(_path, f.name)); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return typeErr()
+	}
+
+	return nil
+}
+
+// genericValue decodes a JS value the way encoding/json decodes into an empty
+// interface, with Dates and Timestamps as time.Time and Uint8Arrays and Bytes
+// as []byte.
+func genericValue(_v js.Value) interface{} {
+	switch _v.Type() {
+	case js.TypeNull, js.TypeUndefined:
+		return nil
+	case js.TypeBoolean:
+		return _v.Bool()
+	case js.TypeNumber:
+		return _v.Float()
+	case js.TypeString:
+		return _v.String()
+	case js.TypeObject:
+		if isArray(_v) {
+			r := make([]interface{}, _v.Length())
+			for i := range r {
+				r[i] = genericValue(_v.Index(i))
+			}
+			return r
+		}
+		if ms, ok := timeOf(_v); ok {
+			return msToTime(ms)
+		}
+		if b, ok := bytesFrom(_v); ok {
+			return b
+		}
+		r := map[string]interface{}{}
+		keys := js.Global().Get(object__constructor).Call(function__keys, _v)
+		for i := 0; i < keys.Length(); i++ {
+			k := keys.Index(i).String()
+			r[k] = genericValue(_v.Get(k))
+		}
+		return r
+	}
+	return _v
+}
+
+// timeOf reads a Date, or a Timestamp-like object, as Unix milliseconds.
+func timeOf(_v js.Value) (float64, bool) {
+	switch {
+	case _v.InstanceOf(js.Global().Get(date__constructor)):
+		return _v.Call(function__getTime).Float(), true
+	case _v.Get(function__toMillis).Type() == js.TypeFunction:
+		return _v.Call(function__toMillis).Float(), true
+	case _v.Get(function__toDate).Type() == js.TypeFunction:
+		return timeOf(_v.Call(function__toDate))
+	}
+	return 0, false
+}
+
+// bytesFrom reads a Uint8Array, ArrayBuffer, other typed array view or
+// Bytes-like object.
+func bytesFrom(_v js.Value) ([]byte, bool) {
+	switch {
+	case _v.InstanceOf(js.Global().Get(arrayBuffer__name)), isView(_v):
+		return bytesOf(_v), true
+	case _v.Get(function__toUint8Array).Type() == js.TypeFunction:
+		return bytesOf(_v.Call(function__toUint8Array)), true
+	}
+	return nil, false
+}
+
+func isArray(_v js.Value) bool {
+	return js.Global().Get(array__constructor).Call(function__isArray, _v).Bool()
+}
+
+func isView(_v js.Value) bool {
+	return js.Global().Get(arrayBuffer__name).Call(function__isView, _v).Bool()
+}
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+func cachedFields(_t reflect.Type) []field {
+	if f, ok := fieldCache.Load(_t); ok {
+		return f.([]field)
+	}
+	f := typeFields(_t, nil)
+	fieldCache.Store(_t, f)
+	return f
+}
+
+// typeFields lists the exported fields of a struct, flattening untagged
+// embedded structs the way encoding/json does.
+func typeFields(_t reflect.Type, _index []int) []field {
+	fields := []field{}
+	for i := 0; i < _t.NumField(); i++ {
+		sf := _t.Field(i)
+		tag := sf.Tag.Get(tag__js)
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if c := strings.Index(tag, ","); c >= 0 {
+			name, opts = tag[:c], tag[c+1:]
+		}
+
+		index := make([]int, len(_index)+1)
+		copy(index, _index)
+		index[len(_index)] = i
+
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct && sf.Type != timeType {
+			fields = append(fields, typeFields(sf.Type, index)...)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		omit := false
+		for _, o := range strings.Split(opts, ",") {
+			if o == tag__omitempty {
+				omit = true
+			}
+		}
+		fields = append(fields, field{name: name, index: index, omitEmpty: omit})
+	}
+	return fields
+}
+
+func isEmptyValue(_v reflect.Value) bool {
+	switch _v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return _v.Len() == 0
+	case reflect.Bool:
+		return !_v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return _v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return _v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return _v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return _v.IsNil()
+	case reflect.Struct:
+		if _v.Type() == timeType {
+			return _v.Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
+
+func mapKeyString(_k reflect.Value) (string, bool) {
+	switch _k.Kind() {
+	case reflect.String:
+		return _k.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(_k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(_k.Uint(), 10), true
+	}
+	return "", false
+}
+
+func mapKeyValue(_k string, _t reflect.Type) (reflect.Value, error) {
+	kv := reflect.New(_t).Elem()
+	switch _t.Kind() {
+	case reflect.String:
+		kv.SetString(_k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(_k, 10, 64)
+		if err != nil || kv.OverflowInt(n) {
+			return kv, fmt.Errorf("invalid map key %q for %s", _k, _t)
+		}
+		kv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(_k, 10, 64)
+		if err != nil || kv.OverflowUint(n) {
+			return kv, fmt.Errorf("invalid map key %q for %s", _k, _t)
+		}
+		kv.SetUint(n)
+	default:
+		return kv, fmt.Errorf("unsupported map key type %s", _t)
+	}
+	return kv, nil
+}
+
+func msToTime(_ms float64) time.Time {
+	return time.Unix(0, int64(_ms*float64(time.Millisecond)))
+}
+
+func joinPath(_path, _name string) string {
+	if _path == "" {
+		return _name
+	}
+	return _path + "." + _name
+}
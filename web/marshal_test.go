@@ -0,0 +1,107 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"bytes"
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	type inner struct {
+		N int `js:"n"`
+	}
+	type doc struct {
+		Name  string            `js:"name"`
+		At    time.Time         `js:"at"`
+		Data  []byte            `js:"data"`
+		Tags  []string          `js:"tags"`
+		Inner *inner            `js:"inner"`
+		Attrs map[string]string `js:"attrs,omitempty"`
+	}
+
+	in := doc{
+		Name:  "a",
+		At:    time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Data:  []byte{1, 2, 3},
+		Tags:  []string{"x", "y"},
+		Inner: &inner{N: 7},
+	}
+	var out doc
+	if err := web.Unmarshal(web.Marshal(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != in.Name || !out.At.Equal(in.At) || !bytes.Equal(out.Data, in.Data) ||
+		len(out.Tags) != 2 || out.Inner == nil || out.Inner.N != 7 || out.Attrs != nil {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestUnmarshalTimestampAndBytes(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	ms := float64(at.UnixNano()) / float64(time.Millisecond)
+
+	millis := js.FuncOf(func(js.Value, []js.Value) interface{} { return ms })
+	defer millis.Release()
+	date := js.FuncOf(func(js.Value, []js.Value) interface{} { return js.Global().Get("Date").New(ms) })
+	defer date.Release()
+	u8 := js.FuncOf(func(js.Value, []js.Value) interface{} {
+		a := js.Global().Get("Uint8Array").New(2)
+		js.CopyBytesToJS(a, []byte{9, 8})
+		return a
+	})
+	defer u8.Release()
+
+	var got time.Time
+	if err := web.Unmarshal(js.ValueOf(map[string]interface{}{"toMillis": millis}), &got); err != nil || !got.Equal(at) {
+		t.Errorf("Unmarshal(Timestamp.toMillis) = %v, %v", got, err)
+	}
+	got = time.Time{}
+	if err := web.Unmarshal(js.ValueOf(map[string]interface{}{"toDate": date}), &got); err != nil || !got.Equal(at) {
+		t.Errorf("Unmarshal(Timestamp.toDate) = %v, %v", got, err)
+	}
+
+	var b []byte
+	if err := web.Unmarshal(js.ValueOf(map[string]interface{}{"toUint8Array": u8}), &b); err != nil || !bytes.Equal(b, []byte{9, 8}) {
+		t.Errorf("Unmarshal(Bytes) = %v, %v", b, err)
+	}
+
+	var g interface{}
+	if err := web.Unmarshal(js.ValueOf(map[string]interface{}{"toMillis": millis}), &g); err != nil {
+		t.Fatal(err)
+	}
+	if tm, ok := g.(time.Time); !ok || !tm.Equal(at) {
+		t.Errorf("Unmarshal(Timestamp) into interface{} = %#v", g)
+	}
+}
+
+func TestUnmarshalTypeErrors(t *testing.T) {
+	obj := js.ValueOf(map[string]interface{}{"a": 1})
+	var te *web.UnmarshalTypeError
+
+	var ints []int
+	if err := web.Unmarshal(obj, &ints); !errors.As(err, &te) {
+		t.Errorf("Unmarshal(object) into []int = %v, %v; want a type error", ints, err)
+	}
+	var arr [2]int
+	if err := web.Unmarshal(obj, &arr); !errors.As(err, &te) {
+		t.Errorf("Unmarshal(object) into [2]int = %v; want a type error", err)
+	}
+	var b []byte
+	if err := web.Unmarshal(obj, &b); !errors.As(err, &te) {
+		t.Errorf("Unmarshal(object) into []byte = %v, %v; want a type error", b, err)
+	}
+	var tm time.Time
+	if err := web.Unmarshal(obj, &tm); !errors.As(err, &te) {
+		t.Errorf("Unmarshal(object) into time.Time = %v; want a type error", err)
+	}
+
+	if err := web.Unmarshal(js.ValueOf([]interface{}{1, 2}), &ints); err != nil || len(ints) != 2 {
+		t.Errorf("Unmarshal([1,2]) = %v, %v", ints, err)
+	}
+}
@@ -0,0 +1,286 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+const (
+	navigator__mediaDevices = "mediaDevices"
+
+	function__enumerateDevices = "enumerateDevices"
+	function__getUserMedia     = "getUserMedia"
+	function__getTracks        = "getTracks"
+	function__getVideoTracks   = "getVideoTracks"
+	function__getAudioTracks   = "getAudioTracks"
+	function__stop             = "stop"
+	function__getSettings      = "getSettings"
+
+	device__deviceId = "deviceId"
+	device__kind     = "kind"
+	device__label    = "label"
+	device__groupId  = "groupId"
+
+	track__id         = "id"
+	track__kind       = "kind"
+	track__label      = "label"
+	track__enabled    = "enabled"
+	track__readyState = "readyState"
+	track__ended      = "ended"
+
+	EVENT__devicechange = "devicechange"
+	EVENT__ended        = "ended"
+
+	DEVICE__videoinput  = "videoinput"
+	DEVICE__audioinput  = "audioinput"
+	DEVICE__audiooutput = "audiooutput"
+
+	FACING__user        = "user"
+	FACING__environment = "environment"
+
+	error__notAllowed      = "NotAllowedError"
+	error__notFound        = "NotFoundError"
+	error__notReadable     = "NotReadableError"
+	error__overconstrained = "OverconstrainedError"
+)
+
+var (
+	GOWEB_ERROR_MEDIA_DENIED    = errors.New("media permission denied")
+	GOWEB_ERROR_MEDIA_NOT_FOUND = errors.New("no media device matches the constraints")
+	GOWEB_ERROR_MEDIA_BUSY      = errors.New("media device is in use")
+)
+
+// MediaDevice is an entry of navigator.mediaDevices.enumerateDevices(). Label
+// stays empty until the page has been granted camera or microphone access.
+type MediaDevice struct {
+	DeviceID string
+	Kind     string
+	Label    string
+	GroupID  string
+}
+
+// EnumerateDevices lists the cameras, microphones and outputs; pass one of the
+// DEVICE__ kinds to filter.
+func EnumerateDevices(_ctx context.Context, _kind ...string) ([]MediaDevice, error) {
+	md, err := navigatorGet(navigator__mediaDevices)
+	if err != nil {
+		return nil, fmt.Errorf("[media] [EnumerateDevices] [error]: %w", err)
+	}
+	list, err := awaitCall(_ctx, md, function__enumerateDevices)
+	if err != nil {
+		return nil, fmt.Errorf("[media] [EnumerateDevices] [error]: %v", err)
+	}
+	r := []MediaDevice{}
+	for _, d := range jsSlice(list) {
+		dev := MediaDevice{
+			DeviceID: d.Get(device__deviceId).String(),
+			Kind:     d.Get(device__kind).String(),
+			Label:    d.Get(device__label).String(),
+			GroupID:  d.Get(device__groupId).String(),
+		}
+		if len(_kind) > 0 && dev.Kind != _kind[0] {
+			continue
+		}
+		r = append(r, dev)
+	}
+	return r, nil
+}
+
+// OnDeviceChange calls _cb when a device is plugged in or removed.
+func OnDeviceChange(_cb func()) func() {
+	md, err := navigatorGet(navigator__mediaDevices)
+	if err != nil {
+		return func() {}
+	}
+	return listen(md, EVENT__devicechange, func(Event) { _cb() }, ListenerOptions{})
+}
+
+// VideoConstraints are the video constraints of GetUserMedia. Zero values
+// are left to the browser; sizes and frame rates are ideal values.
+type VideoConstraints struct {
+	DeviceID   string
+	FacingMode string
+	Width      int
+	Height     int
+	FrameRate  float64
+}
+
+func (c *VideoConstraints) jsValue() interface{} {
+	if c == nil {
+		return false
+	}
+	m := map[string]interface{}{}
+	if c.DeviceID != "" {
+		m[device__deviceId] = map[string]interface{}{"exact": c.DeviceID}
+	}
+	if c.FacingMode != "" {
+		m["facingMode"] = map[string]interface{}{"ideal": c.FacingMode}
+	}
+	if c.Width > 0 {
+		m["width"] = map[string]interface{}{"ideal": c.Width}
+	}
+	if c.Height > 0 {
+		m["height"] = map[string]interface{}{"ideal": c.Height}
+	}
+	if c.FrameRate > 0 {
+		m["frameRate"] = map[string]interface{}{"ideal": c.FrameRate}
+	}
+	if len(m) == 0 {
+		return true
+	}
+	return m
+}
+
+// AudioConstraints are the audio constraints of GetUserMedia. The processing
+// switches default to the browser's choice when nil.
+type AudioConstraints struct {
+	DeviceID         string
+	EchoCancellation *bool
+	NoiseSuppression *bool
+	AutoGainControl  *bool
+}
+
+func (c *AudioConstraints) jsValue() interface{} {
+	if c == nil {
+		return false
+	}
+	m := map[string]interface{}{}
+	if c.DeviceID != "" {
+		m[device__deviceId] = map[string]interface{}{"exact": c.DeviceID}
+	}
+	for k, v := range map[string]*bool{"echoCancellation": c.EchoCancellation, "noiseSuppression": c.NoiseSuppression, "autoGainControl": c.AutoGainControl} {
+		if v != nil {
+			m[k] = *v
+		}
+	}
+	if len(m) == 0 {
+		return true
+	}
+	return m
+}
+
+// MediaConstraints select what GetUserMedia captures; a nil kind is not
+// requested.
+type MediaConstraints struct {
+	Video *VideoConstraints
+	Audio *AudioConstraints
+}
+
+// MediaStream is a captured stream.
+type MediaStream struct {
+	Value js.Value
+}
+
+// MediaTrack is one audio or video track of a MediaStream.
+type MediaTrack struct {
+	ID    string
+	Kind  string
+	Label string
+	Value js.Value
+}
+
+// GetUserMedia asks for the camera and/or microphone. A refused prompt returns
+// an error wrapping GOWEB_ERROR_MEDIA_DENIED, no matching device
+// GOWEB_ERROR_MEDIA_NOT_FOUND and a device held by another app
+// GOWEB_ERROR_MEDIA_BUSY. Browsers only expose it in a secure context.
+func GetUserMedia(_ctx context.Context, _c MediaConstraints) (*MediaStream, error) {
+	md, err := navigatorGet(navigator__mediaDevices)
+	if err != nil {
+		return nil, fmt.Errorf("[media] [GetUserMedia] [error]: %w", err)
+	}
+	v, err := awaitCall(_ctx, md, function__getUserMedia, map[string]interface{}{
+		"video": _c.Video.jsValue(),
+		"audio": _c.Audio.jsValue(),
+	})
+	if err != nil {
+		var perr *PromiseError
+		if errors.As(err, &perr) {
+			switch perr.Name {
+			case error__notAllowed:
+				return nil, fmt.Errorf("[media] [GetUserMedia] [error]: %w", GOWEB_ERROR_MEDIA_DENIED)
+			case error__notFound, error__overconstrained:
+				return nil, fmt.Errorf("[media] [GetUserMedia] [error]: %w: %v", GOWEB_ERROR_MEDIA_NOT_FOUND, err)
+			case error__notReadable:
+				return nil, fmt.Errorf("[media] [GetUserMedia] [error]: %w: %v", GOWEB_ERROR_MEDIA_BUSY, err)
+			}
+		}
+		return nil, fmt.Errorf("[media] [GetUserMedia] [error]: %v", err)
+	}
+	return &MediaStream{Value: v}, nil
+}
+
+// Tracks ...
+func (s *MediaStream) Tracks() []*MediaTrack {
+	return s.tracks(function__getTracks)
+}
+
+// VideoTracks ...
+func (s *MediaStream) VideoTracks() []*MediaTrack {
+	return s.tracks(function__getVideoTracks)
+}
+
+// AudioTracks ...
+func (s *MediaStream) AudioTracks() []*MediaTrack {
+	return s.tracks(function__getAudioTracks)
+}
+
+// Stop stops every track, releasing the camera and microphone.
+func (s *MediaStream) Stop() {
+	for _, t := range s.Tracks() {
+		t.Stop()
+	}
+}
+
+func (s *MediaStream) tracks(_method string) []*MediaTrack {
+	r := []*MediaTrack{}
+	if ValidJSValue("stream", s.Value) != nil {
+		return r
+	}
+	for _, t := range jsSlice(s.Value.Call(_method)) {
+		r = append(r, &MediaTrack{
+			ID:    t.Get(track__id).String(),
+			Kind:  t.Get(track__kind).String(),
+			Label: t.Get(track__label).String(),
+			Value: t,
+		})
+	}
+	return r
+}
+
+// Stop ...
+func (t *MediaTrack) Stop() {
+	t.Value.Call(function__stop)
+}
+
+// SetEnabled mutes (false) or unmutes the track without releasing the device.
+func (t *MediaTrack) SetEnabled(_on bool) {
+	t.Value.Set(track__enabled, _on)
+}
+
+// Enabled ...
+func (t *MediaTrack) Enabled() bool {
+	return t.Value.Get(track__enabled).Bool()
+}
+
+// Ended reports whether the track was stopped or its device went away.
+func (t *MediaTrack) Ended() bool {
+	return t.Value.Get(track__readyState).String() == track__ended
+}
+
+// Settings returns the values in effect, e.g. the actual width, height and
+// deviceId.
+func (t *MediaTrack) Settings() map[string]interface{} {
+	m := map[string]interface{}{}
+	Unmarshal(t.Value.Call(function__getSettings), &m)
+	return m
+}
+
+// OnEnded calls _cb when the track ends from outside, e.g. the camera is
+// unplugged or the permission revoked.
+func (t *MediaTrack) OnEnded(_cb func()) func() {
+	return listen(t.Value, EVENT__ended, func(Event) { _cb() }, ListenerOptions{})
+}
@@ -0,0 +1,256 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+	"time"
+)
+
+const (
+	tag__video = "video"
+	tag__audio = "audio"
+
+	media__src          = "src"
+	media__srcObject    = "srcObject"
+	media__currentTime  = "currentTime"
+	media__duration     = "duration"
+	media__paused       = "paused"
+	media__ended        = "ended"
+	media__playbackRate = "playbackRate"
+	media__volume       = "volume"
+	media__muted        = "muted"
+	media__loop         = "loop"
+	media__autoplay     = "autoplay"
+	media__playsInline  = "playsInline"
+	media__readyState   = "readyState"
+	media__videoWidth   = "videoWidth"
+	media__videoHeight  = "videoHeight"
+	media__crossOrigin  = "crossOrigin"
+
+	function__play  = "play"
+	function__pause = "pause"
+	function__load  = "load"
+
+	EVENT__play           = "play"
+	EVENT__playing        = "playing"
+	EVENT__pause          = "pause"
+	EVENT__waiting        = "waiting"
+	EVENT__seeked         = "seeked"
+	EVENT__timeupdate     = "timeupdate"
+	EVENT__loadedmetadata = "loadedmetadata"
+	EVENT__canplay        = "canplay"
+	EVENT__ratechange     = "ratechange"
+	EVENT__volumechange   = "volumechange"
+
+	// HAVE_METADATA is the readyState from which duration and the video size
+	// are known.
+	media__haveMetadata = 1
+)
+
+// MediaElement controls a <video> or <audio> element.
+type MediaElement struct {
+	*Element
+}
+
+// NewMediaElement wraps an existing <video> or <audio>.
+func NewMediaElement(_elem *Element) *MediaElement {
+	return &MediaElement{Element: _elem}
+}
+
+// NewVideo creates a detached <video>. It is set to play inline, so a camera
+// preview or a video texture does not go fullscreen on iOS.
+func (w *Window) NewVideo() *MediaElement {
+	m := NewMediaElement(w.NewElementWithTag(tag__video))
+	if ValidJSValue(m.String(), m.Value) == nil {
+		m.Value.Set(media__playsInline, true)
+	}
+	return m
+}
+
+// NewAudio creates a detached <audio>.
+func (w *Window) NewAudio() *MediaElement {
+	return NewMediaElement(w.NewElementWithTag(tag__audio))
+}
+
+// SetSrc loads _url; pass an object URL (see CreateObjectURL) for local files.
+func (m *MediaElement) SetSrc(_url string) {
+	m.Value.Set(media__srcObject, js.Null())
+	m.Value.Set(media__src, _url)
+}
+
+// SetStream plays a captured stream, e.g. a camera preview.
+func (m *MediaElement) SetStream(_s *MediaStream) {
+	m.Value.Set(media__srcObject, _s.Value)
+}
+
+// SetCrossOrigin must be set before SetSrc for a cross origin video used as a
+// texture, otherwise WebGL refuses to read its frames.
+func (m *MediaElement) SetCrossOrigin(_mode string) {
+	m.Value.Set(media__crossOrigin, _mode)
+}
+
+// Play starts playback. Browsers reject it without a user gesture unless the
+// element is muted.
+func (m *MediaElement) Play(_ctx context.Context) error {
+	if err := ValidJSValue(m.String(), m.Value); err != nil {
+		return fmt.Errorf("%s [Play] [error]: %v", m, err)
+	}
+	p, err := callJS(m.Value, function__play)
+	if err != nil {
+		return fmt.Errorf("%s [Play] [error]: %v", m, err)
+	}
+	// old browsers return undefined instead of a promise
+	if p.Type() != js.TypeObject {
+		return nil
+	}
+	if _, err = Await(_ctx, p); err != nil {
+		return fmt.Errorf("%s [Play] [error]: %v", m, err)
+	}
+	return nil
+}
+
+// Pause ...
+func (m *MediaElement) Pause() {
+	m.Value.Call(function__pause)
+}
+
+// Load resets the element and reloads its source.
+func (m *MediaElement) Load() {
+	m.Value.Call(function__load)
+}
+
+// Seek ...
+func (m *MediaElement) Seek(_t time.Duration) {
+	m.Value.Set(media__currentTime, _t.Seconds())
+}
+
+// CurrentTime ...
+func (m *MediaElement) CurrentTime() time.Duration {
+	return secDuration(m.Value.Get(media__currentTime).Float())
+}
+
+// Duration is 0 until the metadata is loaded and for live streams.
+func (m *MediaElement) Duration() time.Duration {
+	d := m.Value.Get(media__duration)
+	if d.Type() != js.TypeNumber || d.IsNaN() || d.Float() > 1e12 {
+		return 0
+	}
+	return secDuration(d.Float())
+}
+
+// Paused ...
+func (m *MediaElement) Paused() bool {
+	return m.Value.Get(media__paused).Bool()
+}
+
+// Ended ...
+func (m *MediaElement) Ended() bool {
+	return m.Value.Get(media__ended).Bool()
+}
+
+// SetPlaybackRate ...
+func (m *MediaElement) SetPlaybackRate(_r float64) {
+	m.Value.Set(media__playbackRate, _r)
+}
+
+// PlaybackRate ...
+func (m *MediaElement) PlaybackRate() float64 {
+	return m.Value.Get(media__playbackRate).Float()
+}
+
+// SetVolume takes 0 to 1.
+func (m *MediaElement) SetVolume(_v float64) {
+	if _v < 0 {
+		_v = 0
+	} else if _v > 1 {
+		_v = 1
+	}
+	m.Value.Set(media__volume, _v)
+}
+
+// Volume ...
+func (m *MediaElement) Volume() float64 {
+	return m.Value.Get(media__volume).Float()
+}
+
+// SetMuted ...
+func (m *MediaElement) SetMuted(_on bool) {
+	m.Value.Set(media__muted, _on)
+}
+
+// Muted ...
+func (m *MediaElement) Muted() bool {
+	return m.Value.Get(media__muted).Bool()
+}
+
+// SetLoop ...
+func (m *MediaElement) SetLoop(_on bool) {
+	m.Value.Set(media__loop, _on)
+}
+
+// SetAutoplay ...
+func (m *MediaElement) SetAutoplay(_on bool) {
+	m.Value.Set(media__autoplay, _on)
+}
+
+// VideoSize is 0, 0 for audio and before the metadata is loaded.
+func (m *MediaElement) VideoSize() (int, int) {
+	w, h := m.Value.Get(media__videoWidth), m.Value.Get(media__videoHeight)
+	if w.Type() != js.TypeNumber || h.Type() != js.TypeNumber {
+		return 0, 0
+	}
+	return w.Int(), h.Int()
+}
+
+// WaitMetadata blocks until duration and the video size are known.
+func (m *MediaElement) WaitMetadata(_ctx context.Context) error {
+	if err := ValidJSValue(m.String(), m.Value); err != nil {
+		return fmt.Errorf("%s [WaitMetadata] [error]: %v", m, err)
+	}
+	if m.Value.Get(media__readyState).Int() >= media__haveMetadata {
+		return nil
+	}
+	ready := make(chan error, 1)
+	signal := func(_err error) {
+		select {
+		case ready <- _err:
+		default:
+		}
+	}
+	offs := []func(){
+		m.On(EVENT__loadedmetadata, func(Event) { signal(nil) }),
+		m.On(EVENT__error, func(Event) { signal(fmt.Errorf("media failed to load")) }),
+	}
+	defer func() {
+		for _, off := range offs {
+			off()
+		}
+	}()
+	select {
+	case err := <-ready:
+		if err != nil {
+			return fmt.Errorf("%s [WaitMetadata] [error]: %v", m, err)
+		}
+		return nil
+	case <-_ctx.Done():
+		return fmt.Errorf("%s [WaitMetadata] [error]: %v", m, _ctx.Err())
+	}
+}
+
+// OnTimeUpdate calls _cb with the position a few times per second while
+// playing.
+func (m *MediaElement) OnTimeUpdate(_cb func(time.Duration)) func() {
+	return m.On(EVENT__timeupdate, func(Event) { _cb(m.CurrentTime()) })
+}
+
+// OnEnded ...
+func (m *MediaElement) OnEnded(_cb func()) func() {
+	return m.On(EVENT__ended, func(Event) { _cb() })
+}
+
+func secDuration(_s float64) time.Duration {
+	return time.Duration(_s * float64(time.Second))
+}
@@ -0,0 +1,135 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+const (
+	notification__constructor = "Notification"
+	notification__permission  = "permission"
+	notification__default     = "default"
+
+	function__requestPermission = "requestPermission"
+
+	PERMISSION__notifications = "notifications"
+)
+
+var GOWEB_ERROR_NOTIFY_DENIED = errors.New("notification permission denied")
+
+// NotifyOptions are the Notification constructor options.
+type NotifyOptions struct {
+	Body               string
+	Icon               string
+	Badge              string
+	Tag                string
+	Lang               string
+	Silent             bool
+	RequireInteraction bool
+	// Data is marshalled onto notification.data.
+	Data interface{}
+}
+
+func (o NotifyOptions) jsValue() map[string]interface{} {
+	m := map[string]interface{}{
+		"silent":             o.Silent,
+		"requireInteraction": o.RequireInteraction,
+	}
+	for k, v := range map[string]string{"body": o.Body, "icon": o.Icon, "badge": o.Badge, "tag": o.Tag, "lang": o.Lang} {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	if o.Data != nil {
+		m["data"] = Marshal(o.Data)
+	}
+	return m
+}
+
+// Notification is a displayed system notification.
+type Notification struct {
+	Title string
+	Value js.Value
+}
+
+// NotifyPermission returns the current notification permission without
+// prompting.
+func NotifyPermission() (PermissionState, error) {
+	ctor := js.Global().Get(notification__constructor)
+	if err := ValidJSValue(notification__constructor, ctor); err != nil {
+		return "", fmt.Errorf("[notify] [NotifyPermission] [error]: %w", GOWEB_ERROR_UNSUPPORTED)
+	}
+	return notifyState(ctor.Get(notification__permission)), nil
+}
+
+// RequestNotifyPermission prompts for permission when it was not decided yet.
+// Browsers only show the prompt from a user gesture.
+func RequestNotifyPermission(_ctx context.Context) (PermissionState, error) {
+	ctor := js.Global().Get(notification__constructor)
+	if err := ValidJSValue(notification__constructor, ctor); err != nil {
+		return "", fmt.Errorf("[notify] [RequestNotifyPermission] [error]: %w", GOWEB_ERROR_UNSUPPORTED)
+	}
+	if s := notifyState(ctor.Get(notification__permission)); s != PermissionPrompt {
+		return s, nil
+	}
+	v, err := awaitCall(_ctx, ctor, function__requestPermission)
+	if err != nil {
+		return "", fmt.Errorf("[notify] [RequestNotifyPermission] [error]: %v", err)
+	}
+	return notifyState(v), nil
+}
+
+// Notify shows a notification, asking for permission first if needed. A
+// denied permission returns an error wrapping GOWEB_ERROR_NOTIFY_DENIED.
+func Notify(_ctx context.Context, _title string, _opts ...NotifyOptions) (*Notification, error) {
+	state, err := RequestNotifyPermission(_ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[notify] [Notify] [error]: %v", err)
+	}
+	if state != PermissionGranted {
+		return nil, fmt.Errorf("[notify] [Notify] [error]: %w", GOWEB_ERROR_NOTIFY_DENIED)
+	}
+
+	var o NotifyOptions
+	if len(_opts) > 0 {
+		o = _opts[0]
+	}
+	// Chrome on Android only allows notifications from a service worker and
+	// throws here
+	v, err := newJS(js.Global().Get(notification__constructor), _title, o.jsValue())
+	if err != nil {
+		return nil, fmt.Errorf("[notify] [Notify] [error]: %v", err)
+	}
+	return &Notification{Title: _title, Value: v}, nil
+}
+
+// OnClick ...
+func (n *Notification) OnClick(_cb func(Event)) func() {
+	return listen(n.Value, EVENT__click, _cb, ListenerOptions{})
+}
+
+// OnClose ...
+func (n *Notification) OnClose(_cb func(Event)) func() {
+	return listen(n.Value, EVENT__close, _cb, ListenerOptions{})
+}
+
+// OnError ...
+func (n *Notification) OnError(_cb func(Event)) func() {
+	return listen(n.Value, EVENT__error, _cb, ListenerOptions{})
+}
+
+// Close ...
+func (n *Notification) Close() {
+	n.Value.Call(function__close)
+}
+
+func notifyState(_v js.Value) PermissionState {
+	if _v.Type() != js.TypeString || _v.String() == notification__default {
+		return PermissionPrompt
+	}
+	return PermissionState(_v.String())
+}
@@ -0,0 +1,436 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"fmt"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	mutationObserver__constructor     = "MutationObserver"
+	resizeObserver__constructor       = "ResizeObserver"
+	intersectionObserver__constructor = "IntersectionObserver"
+
+	function__observe     = "observe"
+	function__unobserve   = "unobserve"
+	function__disconnect  = "disconnect"
+	function__takeRecords = "takeRecords"
+
+	function__getBoundingClientRect = "getBoundingClientRect"
+
+	record__type          = "type"
+	record__target        = "target"
+	record__addedNodes    = "addedNodes"
+	record__removedNodes  = "removedNodes"
+	record__attributeName = "attributeName"
+	record__oldValue      = "oldValue"
+
+	entry__contentRect       = "contentRect"
+	entry__borderBoxSize     = "borderBoxSize"
+	entry__inlineSize        = "inlineSize"
+	entry__blockSize         = "blockSize"
+	entry__isIntersecting    = "isIntersecting"
+	entry__intersectionRatio = "intersectionRatio"
+	entry__boundingRect      = "boundingClientRect"
+	entry__intersectionRect  = "intersectionRect"
+	entry__rootBounds        = "rootBounds"
+	entry__time              = "time"
+
+	rect__x      = "x"
+	rect__y      = "y"
+	rect__width  = "width"
+	rect__height = "height"
+
+	MUTATION__childList     = "childList"
+	MUTATION__attributes    = "attributes"
+	MUTATION__characterData = "characterData"
+
+	RESIZE__contentBox = "content-box"
+	RESIZE__borderBox  = "border-box"
+)
+
+// Rect is a DOMRect.
+type Rect struct {
+	X, Y, Width, Height float64
+}
+
+// Left ...
+func (r Rect) Left() float64 { return r.X }
+
+// Top ...
+func (r Rect) Top() float64 { return r.Y }
+
+// Right ...
+func (r Rect) Right() float64 { return r.X + r.Width }
+
+// Bottom ...
+func (r Rect) Bottom() float64 { return r.Y + r.Height }
+
+// Empty ...
+func (r Rect) Empty() bool { return r.Width <= 0 || r.Height <= 0 }
+
+func rectOf(_v js.Value) Rect {
+	if ValidJSValue("rect", _v) != nil {
+		return Rect{}
+	}
+	return Rect{
+		X:      _v.Get(rect__x).Float(),
+		Y:      _v.Get(rect__y).Float(),
+		Width:  _v.Get(rect__width).Float(),
+		Height: _v.Get(rect__height).Float(),
+	}
+}
+
+// BoundingRect returns the element's getBoundingClientRect.
+func (elem *Element) BoundingRect() Rect {
+	if ValidJSValue(elem.String(), elem.Value) != nil {
+		return Rect{}
+	}
+	return rectOf(elem.Value.Call(function__getBoundingClientRect))
+}
+
+// observer holds what the three observer types share: the JS object and the
+// callback to release on Disconnect.
+type observer struct {
+	name  string
+	value js.Value
+	fn    js.Func
+	once  sync.Once
+}
+
+func newObserver(_name string, _cb func([]js.Value), _args ...interface{}) (*observer, error) {
+	ctor := js.Global().Get(_name)
+	if err := ValidJSValue(_name, ctor); err != nil {
+		return nil, err
+	}
+	o := &observer{name: _name}
+	o.fn = js.FuncOf(func(_this js.Value, _a []js.Value) interface{} {
+		_cb(jsSlice(firstArg(_a)))
+		return nil
+	})
+	v, err := newJS(ctor, append([]interface{}{o.fn}, _args...)...)
+	if err != nil {
+		o.fn.Release()
+		return nil, err
+	}
+	o.value = v
+	return o, nil
+}
+
+func (o *observer) observe(_op string, _elem *Element, _opts ...interface{}) error {
+	if _elem == nil || ValidJSValue(_elem.String(), _elem.Value) != nil {
+		return fmt.Errorf("[%s] [%s] [error]: invalid element", o.name, _op)
+	}
+	if _, err := callJS(o.value, function__observe, append([]interface{}{_elem.Value}, _opts...)...); err != nil {
+		return fmt.Errorf("[%s] [%s] [%s] [error]: %v", o.name, _op, _elem, err)
+	}
+	return nil
+}
+
+func (o *observer) unobserve(_elem *Element) {
+	if _elem != nil && ValidJSValue(_elem.String(), _elem.Value) == nil {
+		o.value.Call(function__unobserve, _elem.Value)
+	}
+}
+
+// disconnect stops observing and releases the callback; it is safe to call
+// more than once.
+func (o *observer) disconnect() {
+	o.once.Do(func() {
+		o.value.Call(function__disconnect)
+		o.fn.Release()
+	})
+}
+
+// MutationOptions select what a MutationObserver reports.
+type MutationOptions struct {
+	ChildList             bool
+	Attributes            bool
+	CharacterData         bool
+	Subtree               bool
+	AttributeOldValue     bool
+	CharacterDataOldValue bool
+	// AttributeFilter limits attribute records to these names.
+	AttributeFilter []string
+}
+
+func (o MutationOptions) jsValue() map[string]interface{} {
+	m := map[string]interface{}{
+		MUTATION__childList:     o.ChildList,
+		MUTATION__attributes:    o.Attributes || o.AttributeOldValue || len(o.AttributeFilter) > 0,
+		MUTATION__characterData: o.CharacterData || o.CharacterDataOldValue,
+		"subtree":               o.Subtree,
+		"attributeOldValue":     o.AttributeOldValue,
+		"characterDataOldValue": o.CharacterDataOldValue,
+	}
+	if len(o.AttributeFilter) > 0 {
+		f := make([]interface{}, len(o.AttributeFilter))
+		for i, a := range o.AttributeFilter {
+			f[i] = a
+		}
+		m["attributeFilter"] = f
+	}
+	return m
+}
+
+// MutationRecord is one change reported by a MutationObserver.
+type MutationRecord struct {
+	// Type is MUTATION__childList, MUTATION__attributes or
+	// MUTATION__characterData.
+	Type   string
+	Target *Element
+	// Added and Removed are the element nodes of a childList change; text
+	// and comment nodes are left out.
+	Added   []*Element
+	Removed []*Element
+	// AttributeName is set for attribute changes; OldValue when the
+	// corresponding *OldValue option is on.
+	AttributeName string
+	OldValue      string
+
+	Value js.Value
+}
+
+// MutationObserver reports DOM changes, e.g. A-Frame adding entities:
+//
+//	mo, _ := web.NewMutationObserver(func(_recs []web.MutationRecord) {
+//		for _, r := range _recs {
+//			for _, el := range r.Added {
+//				...
+//			}
+//		}
+//	})
+//	mo.Observe(scene, web.MutationOptions{ChildList: true, Subtree: true})
+//	defer mo.Disconnect()
+type MutationObserver struct {
+	*observer
+}
+
+// NewMutationObserver ...
+func NewMutationObserver(_cb func([]MutationRecord)) (*MutationObserver, error) {
+	o, err := newObserver(mutationObserver__constructor, func(_recs []js.Value) {
+		_cb(mutationRecords(_recs))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] [NewMutationObserver] [error]: %v", mutationObserver__constructor, err)
+	}
+	return &MutationObserver{observer: o}, nil
+}
+
+// Observe starts watching _elem. The browser throws (returned here) unless at
+// least one of ChildList, Attributes or CharacterData is set.
+func (mo *MutationObserver) Observe(_elem *Element, _opts MutationOptions) error {
+	return mo.observe("Observe", _elem, _opts.jsValue())
+}
+
+// TakeRecords returns and clears the records not yet delivered to the
+// callback, e.g. right before Disconnect.
+func (mo *MutationObserver) TakeRecords() []MutationRecord {
+	return mutationRecords(jsSlice(mo.value.Call(function__takeRecords)))
+}
+
+// Disconnect ...
+func (mo *MutationObserver) Disconnect() {
+	mo.disconnect()
+}
+
+func mutationRecords(_recs []js.Value) []MutationRecord {
+	r := make([]MutationRecord, len(_recs))
+	for i, v := range _recs {
+		rec := MutationRecord{
+			Type:    v.Get(record__type).String(),
+			Target:  NewElement(v.Get(record__target)),
+			Added:   elementNodes(v.Get(record__addedNodes)),
+			Removed: elementNodes(v.Get(record__removedNodes)),
+			Value:   v,
+		}
+		if n := v.Get(record__attributeName); n.Type() == js.TypeString {
+			rec.AttributeName = n.String()
+		}
+		if o := v.Get(record__oldValue); o.Type() == js.TypeString {
+			rec.OldValue = o.String()
+		}
+		r[i] = rec
+	}
+	return r
+}
+
+func elementNodes(_list js.Value) []*Element {
+	r := []*Element{}
+	for _, n := range jsSlice(_list) {
+		if n.Get(node__type).Int() == node__elementType {
+			r = append(r, NewElement(n))
+		}
+	}
+	return r
+}
+
+// Size is a box size in CSS pixels. Inline is the width and Block the
+// height in horizontal writing modes.
+type Size struct {
+	Inline float64
+	Block  float64
+}
+
+// ResizeEntry is one size change reported by a ResizeObserver.
+type ResizeEntry struct {
+	Target      *Element
+	ContentRect Rect
+	// BorderBoxSize is zero where the browser does not report it.
+	BorderBoxSize Size
+
+	Value js.Value
+}
+
+// ResizeObserver reports element size changes, e.g. to keep the A-Frame
+// canvas matched to its container.
+type ResizeObserver struct {
+	*observer
+}
+
+// NewResizeObserver ...
+func NewResizeObserver(_cb func([]ResizeEntry)) (*ResizeObserver, error) {
+	o, err := newObserver(resizeObserver__constructor, func(_entries []js.Value) {
+		r := make([]ResizeEntry, len(_entries))
+		for i, v := range _entries {
+			r[i] = ResizeEntry{
+				Target:      NewElement(v.Get(record__target)),
+				ContentRect: rectOf(v.Get(entry__contentRect)),
+				Value:       v,
+			}
+			// borderBoxSize is an array in current browsers, a single
+			// object in older Firefox
+			if b := v.Get(entry__borderBoxSize); ValidJSValue(entry__borderBoxSize, b) == nil {
+				if b.Get(entry__inlineSize).IsUndefined() && b.Length() > 0 {
+					b = b.Index(0)
+				}
+				r[i].BorderBoxSize = Size{Inline: b.Get(entry__inlineSize).Float(), Block: b.Get(entry__blockSize).Float()}
+			}
+		}
+		_cb(r)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] [NewResizeObserver] [error]: %v", resizeObserver__constructor, err)
+	}
+	return &ResizeObserver{observer: o}, nil
+}
+
+// Observe starts watching _elem. _box is RESIZE__contentBox (default) or
+// RESIZE__borderBox.
+func (ro *ResizeObserver) Observe(_elem *Element, _box ...string) error {
+	if len(_box) > 0 && _box[0] != "" {
+		return ro.observe("Observe", _elem, map[string]interface{}{"box": _box[0]})
+	}
+	return ro.observe("Observe", _elem)
+}
+
+// Unobserve ...
+func (ro *ResizeObserver) Unobserve(_elem *Element) {
+	ro.unobserve(_elem)
+}
+
+// Disconnect ...
+func (ro *ResizeObserver) Disconnect() {
+	ro.disconnect()
+}
+
+// IntersectionOptions configure an IntersectionObserver.
+type IntersectionOptions struct {
+	// Root is the scrolling ancestor to intersect with; nil is the viewport.
+	Root *Element
+	// RootMargin grows or shrinks the root box, CSS margin syntax ("0px 0px
+	// 200px 0px").
+	RootMargin string
+	// Threshold lists the ratios at which the callback fires; empty means 0.
+	Threshold []float64
+}
+
+// IntersectionEntry is one visibility change reported by an
+// IntersectionObserver.
+type IntersectionEntry struct {
+	Target           *Element
+	IsIntersecting   bool
+	Ratio            float64
+	BoundingRect     Rect
+	IntersectionRect Rect
+	// RootBounds is zero for cross-origin roots.
+	RootBounds Rect
+	Time       time.Duration
+
+	Value js.Value
+}
+
+// IntersectionObserver reports elements entering and leaving the viewport
+// (or Root), e.g. to lazily start UI panels when they scroll into view.
+type IntersectionObserver struct {
+	*observer
+}
+
+// NewIntersectionObserver ...
+func NewIntersectionObserver(_cb func([]IntersectionEntry), _opts IntersectionOptions) (*IntersectionObserver, error) {
+	opts := map[string]interface{}{}
+	if _opts.Root != nil {
+		opts["root"] = _opts.Root.Value
+	}
+	if _opts.RootMargin != "" {
+		opts["rootMargin"] = _opts.RootMargin
+	}
+	if len(_opts.Threshold) > 0 {
+		t := make([]interface{}, len(_opts.Threshold))
+		for i, f := range _opts.Threshold {
+			t[i] = f
+		}
+		opts["threshold"] = t
+	}
+
+	o, err := newObserver(intersectionObserver__constructor, func(_entries []js.Value) {
+		r := make([]IntersectionEntry, len(_entries))
+		for i, v := range _entries {
+			r[i] = IntersectionEntry{
+				Target:           NewElement(v.Get(record__target)),
+				IsIntersecting:   v.Get(entry__isIntersecting).Truthy(),
+				Ratio:            v.Get(entry__intersectionRatio).Float(),
+				BoundingRect:     rectOf(v.Get(entry__boundingRect)),
+				IntersectionRect: rectOf(v.Get(entry__intersectionRect)),
+				RootBounds:       rectOf(v.Get(entry__rootBounds)),
+				Time:             msDuration(v.Get(entry__time).Float()),
+				Value:            v,
+			}
+		}
+		_cb(r)
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] [NewIntersectionObserver] [error]: %v", intersectionObserver__constructor, err)
+	}
+	return &IntersectionObserver{observer: o}, nil
+}
+
+// Observe ...
+func (io *IntersectionObserver) Observe(_elem *Element) error {
+	return io.observe("Observe", _elem)
+}
+
+// Unobserve ...
+func (io *IntersectionObserver) Unobserve(_elem *Element) {
+	io.unobserve(_elem)
+}
+
+// Disconnect ...
+func (io *IntersectionObserver) Disconnect() {
+	io.disconnect()
+}
+
+// jsSlice copies an array-like (Array, NodeList, ...) into a Go slice.
+func jsSlice(_v js.Value) []js.Value {
+	if ValidJSValue("array", _v) != nil {
+		return []js.Value{}
+	}
+	r := make([]js.Value, _v.Length())
+	for i := range r {
+		r[i] = _v.Index(i)
+	}
+	return r
+}
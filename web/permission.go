@@ -0,0 +1,79 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+)
+
+const (
+	navigator = "navigator"
+
+	navigator__permissions = "permissions"
+	function__query        = "query"
+	permission__state      = "state"
+	permission__name       = "name"
+)
+
+// PermissionState is the answer of the Permissions API (and of
+// Notification.permission, whose "default" is reported as prompt).
+type PermissionState string
+
+const (
+	PermissionGranted PermissionState = "granted"
+	PermissionDenied  PermissionState = "denied"
+	PermissionPrompt  PermissionState = "prompt"
+)
+
+// QueryPermission asks the Permissions API for the state of _name
+// ("clipboard-read", "notifications", "geolocation", ...). Browsers that do
+// not know _name reject, which is returned as an error wrapping
+// GOWEB_ERROR_UNSUPPORTED.
+func QueryPermission(_ctx context.Context, _name string) (PermissionState, error) {
+	perms, err := navigatorGet(navigator__permissions)
+	if err != nil {
+		return "", fmt.Errorf("[permission] [%s] [error]: %w", _name, GOWEB_ERROR_UNSUPPORTED)
+	}
+	p, err := callJS(perms, function__query, map[string]interface{}{permission__name: _name})
+	if err != nil {
+		return "", fmt.Errorf("[permission] [%s] [error]: %w: %v", _name, GOWEB_ERROR_UNSUPPORTED, err)
+	}
+	status, err := Await(_ctx, p)
+	if err != nil {
+		if _ctx.Err() != nil {
+			return "", fmt.Errorf("[permission] [%s] [error]: %v", _name, err)
+		}
+		return "", fmt.Errorf("[permission] [%s] [error]: %w: %v", _name, GOWEB_ERROR_UNSUPPORTED, err)
+	}
+	return PermissionState(status.Get(permission__state).String()), nil
+}
+
+func navigatorValue() js.Value {
+	return js.Global().Get(navigator)
+}
+
+// navigatorGet returns navigator[_name], or an error wrapping
+// GOWEB_ERROR_UNSUPPORTED.
+func navigatorGet(_name string) (js.Value, error) {
+	nav := navigatorValue()
+	if err := ValidJSValue(navigator, nav); err != nil {
+		return js.Undefined(), fmt.Errorf("%w: %v", GOWEB_ERROR_UNSUPPORTED, err)
+	}
+	v := nav.Get(_name)
+	if err := ValidJSValue(_name, v); err != nil {
+		return js.Undefined(), fmt.Errorf("%w: %v", GOWEB_ERROR_UNSUPPORTED, err)
+	}
+	return v, nil
+}
+
+// awaitCall calls _method on _v and awaits the returned promise, turning a
+// synchronous throw into an error as well.
+func awaitCall(_ctx context.Context, _v js.Value, _method string, _args ...interface{}) (js.Value, error) {
+	p, err := callJS(_v, _method, _args...)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return Await(_ctx, p)
+}
@@ -0,0 +1,253 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"syscall/js"
+)
+
+const (
+	PROMISE        = "promise"
+	PROMISE__then  = "then"
+	PROMISE__catch = "catch"
+
+	promise__constructor = "Promise"
+	function__reject     = "reject"
+	function__resolve    = "resolve"
+	function__race       = "race"
+
+	error__name    = "name"
+	error__code    = "code"
+	error__message = "message"
+)
+
+// PromiseError is the Go side of a rejected JS promise. Name, Code and Message
+// are read from the rejection value when it looks like a JS Error (or a
+// firebase / provider error object); Value always holds the raw rejection.
+type PromiseError struct {
+	Name    string
+	Code    string
+	Message string
+	Value   js.Value
+}
+
+// NewPromiseError converts a JS rejection value into a *PromiseError.
+func NewPromiseError(_v js.Value) *PromiseError {
+	e := &PromiseError{Value: _v}
+
+	switch _v.Type() {
+	case js.TypeObject:
+		if tv := _v.Get(error__name); tv.Type() == js.TypeString {
+			e.Name = tv.String()
+		}
+		switch tv := _v.Get(error__code); tv.Type() {
+		case js.TypeString:
+			e.Code = tv.String()
+		case js.TypeNumber:
+			e.Code = fmt.Sprintf("%d", tv.Int())
+		}
+		if tv := _v.Get(error__message); tv.Type() == js.TypeString {
+			e.Message = tv.String()
+		}
+	case js.TypeString:
+		e.Message = _v.String()
+	case js.TypeUndefined, js.TypeNull:
+		e.Message = "promise rejected"
+	default:
+		e.Message = _v.String()
+	}
+
+	return e
+}
+
+// Error ...
+func (e *PromiseError) Error() string {
+	s := "[promise] [rejected]"
+	if e.Name != "" {
+		s = fmt.Sprintf("%s %s", s, e.Name)
+	}
+	if e.Code != "" {
+		s = fmt.Sprintf("%s {%s}", s, e.Code)
+	}
+	if e.Message != "" {
+		s = fmt.Sprintf("%s: %s", s, e.Message)
+	}
+	return s
+}
+
+// Promise wraps a JS promise (or any thenable). Callbacks handed to Then and
+// Catch are plain Go funcs; the js.Func wrappers created for them are released
+// as soon as the promise settles.
+type Promise struct {
+	js.Value
+}
+
+// NewPromise ...
+func NewPromise(_v js.Value) *Promise {
+	return &Promise{Value: _v}
+}
+
+// ResolvedPromise returns a promise already fulfilled with _val.
+func ResolvedPromise(_val interface{}) *Promise {
+	return NewPromise(js.Global().Get(promise__constructor).Call(function__resolve, _val))
+}
+
+// RejectedPromise returns a promise already rejected with _reason.
+func RejectedPromise(_reason interface{}) *Promise {
+	return NewPromise(js.Global().Get(promise__constructor).Call(function__reject, _reason))
+}
+
+// deferred returns a pending promise together with its resolve and reject
+// functions, for wrapping callback or event based APIs.
+func deferred() (*Promise, js.Value, js.Value) {
+	var resolve, reject js.Value
+	executor := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		resolve, reject = _args[0], _args[1]
+		return nil
+	})
+	defer executor.Release()
+	p := js.Global().Get(promise__constructor).New(executor)
+	return NewPromise(p), resolve, reject
+}
+
+// settle attaches a single fulfilled/rejected pair to the promise and releases
+// both js.Funcs once either of them has run. The returned value is the promise
+// produced by the underlying `then` call.
+func (p *Promise) settle(_ok func(js.Value) interface{}, _fail func(js.Value) interface{}) js.Value {
+	var once sync.Once
+	var okFn, failFn js.Func
+	release := func() {
+		once.Do(func() {
+			okFn.Release()
+			failFn.Release()
+		})
+	}
+
+	okFn = js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		defer release()
+		return _ok(firstArg(_args))
+	})
+	failFn = js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		defer release()
+		return _fail(firstArg(_args))
+	})
+
+	return p.Value.Call(PROMISE__then, okFn, failFn)
+}
+
+// Then calls _cb with the fulfilled value. Rejections pass through untouched so
+// a later Catch still sees them.
+func (p *Promise) Then(_cb func(js.Value)) *Promise {
+	if err := ValidJSValue(PROMISE, p.Value); err != nil {
+		return RejectedPromise(err.Error())
+	}
+	return NewPromise(p.settle(
+		func(_v js.Value) interface{} {
+			_cb(_v)
+			return _v
+		},
+		func(_v js.Value) interface{} {
+			return js.Global().Get(promise__constructor).Call(function__reject, _v)
+		},
+	))
+}
+
+// Catch calls _cb with the rejection converted to a *PromiseError.
+func (p *Promise) Catch(_cb func(error)) *Promise {
+	if err := ValidJSValue(PROMISE, p.Value); err != nil {
+		_cb(fmt.Errorf("[promise] [Catch] [error]: %v", err))
+		return p
+	}
+	return NewPromise(p.settle(
+		func(_v js.Value) interface{} {
+			return _v
+		},
+		func(_v js.Value) interface{} {
+			_cb(NewPromiseError(_v))
+			return nil
+		},
+	))
+}
+
+type promiseResult struct {
+	value js.Value
+	err   error
+}
+
+// Await blocks until the promise settles or _ctx is done. It must be called
+// from a goroutine, never directly inside a js.Func callback, since the JS event
+// loop has to run for the promise to settle.
+func (p *Promise) Await(_ctx context.Context) (js.Value, error) {
+	if err := ValidJSValue(PROMISE, p.Value); err != nil {
+		return js.Undefined(), fmt.Errorf("[promise] [Await] [error]: %v", err)
+	}
+
+	// the handlers wait on a race with a promise that _ctx rejects, so they
+	// run, and are released, even when p never settles
+	stop, _, cancel := deferred()
+	race := js.Global().Get(promise__constructor).Call(function__race, []interface{}{p.Value, stop.Value})
+
+	ch := make(chan promiseResult, 1)
+	NewPromise(race).settle(
+		func(_v js.Value) interface{} {
+			ch <- promiseResult{value: _v}
+			return nil
+		},
+		func(_v js.Value) interface{} {
+			ch <- promiseResult{value: js.Undefined(), err: NewPromiseError(_v)}
+			return nil
+		},
+	)
+
+	select {
+	case r := <-ch:
+		return r.value, r.err
+	case <-_ctx.Done():
+		cancel.Invoke()
+		return js.Undefined(), _ctx.Err()
+	}
+}
+
+// Await is shorthand for NewPromise(_v).Await(_ctx).
+func Await(_ctx context.Context, _v js.Value) (js.Value, error) {
+	return NewPromise(_v).Await(_ctx)
+}
+
+func firstArg(_args []js.Value) js.Value {
+	if len(_args) == 0 {
+		return js.Undefined()
+	}
+	return _args[0]
+}
+
+// callJS calls a method and turns a thrown JS exception (e.g. a SyntaxError
+// from an invalid selector) into an error instead of a panic.
+func callJS(_v js.Value, _method string, _args ...interface{}) (r js.Value, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			if jerr, ok := rec.(js.Error); ok {
+				err = jerr
+				return
+			}
+			panic(rec)
+		}
+	}()
+	return _v.Call(_method, _args...), nil
+}
+
+// newJS calls new on _ctor, turning a thrown exception into an error.
+func newJS(_ctor js.Value, _args ...interface{}) (r js.Value, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			if jerr, ok := rec.(js.Error); ok {
+				err = jerr
+				return
+			}
+			panic(rec)
+		}
+	}()
+	return _ctor.New(_args...), nil
+}
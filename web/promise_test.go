@@ -0,0 +1,49 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+func TestPromiseAwait(t *testing.T) {
+	v, err := web.ResolvedPromise(7).Await(context.Background())
+	if err != nil || v.Int() != 7 {
+		t.Errorf("Await(resolved) = %v, %v", v, err)
+	}
+
+	_, err = web.RejectedPromise("nope").Await(context.Background())
+	var perr *web.PromiseError
+	if !errors.As(err, &perr) || perr.Message != "nope" {
+		t.Errorf("Await(rejected) = %v, want a PromiseError", err)
+	}
+}
+
+func TestPromiseAwaitCancel(t *testing.T) {
+	var resolve js.Value
+	executor := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		resolve = _args[0]
+		return nil
+	})
+	defer executor.Release()
+	p := web.NewPromise(js.Global().Get("Promise").New(executor))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Await(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Await = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// settling after the caller gave up must not reach a released handler
+	resolve.Invoke(1)
+	time.Sleep(10 * time.Millisecond)
+	if v, err := p.Await(context.Background()); err != nil || v.Int() != 1 {
+		t.Errorf("second Await = %v, %v", v, err)
+	}
+}
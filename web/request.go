@@ -3,42 +3,573 @@
 package web
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall/js"
+	"time"
 )
 
 const (
-	xhttp__constructor = "xhttp"
+	fetch                 = "fetch"
+	abortController       = "AbortController"
+	uint8Array__name      = "Uint8Array"
+	arrayBuffer__name     = "ArrayBuffer"
+	function__abort       = "abort"
+	function__getReader   = "getReader"
+	function__read        = "read"
+	function__cancel      = "cancel"
+	function__entries     = "entries"
+	function__next        = "next"
+	function__arrayBuffer = "arrayBuffer"
+	function__enqueue     = "enqueue"
+	function__error       = "error"
 
-	XHTTP__response = "response"
-	XHTTP__error    = "Error"
+	readableStream__name = "ReadableStream"
+	request__name        = "Request"
+	stream__pull         = "pull"
+	stream__cancel       = "cancel"
+	stream__chunk        = 64 << 10
 
-	function__xhttp_open = "open"
-	function__xhttp_send = "send"
+	REQUEST__method      = "method"
+	REQUEST__headers     = "headers"
+	REQUEST__body        = "body"
+	REQUEST__mode        = "mode"
+	REQUEST__credentials = "credentials"
+	REQUEST__signal      = "signal"
+	REQUEST__duplex      = "duplex"
 
-	PROMISE        = "promise"
-	PROMISE__then  = "then"
-	PROMISE__catch = "catch"
+	RESPONSE__status     = "status"
+	RESPONSE__statusText = "statusText"
+	RESPONSE__ok         = "ok"
+	RESPONSE__url        = "url"
+	RESPONSE__redirected = "redirected"
+	RESPONSE__headers    = "headers"
+	RESPONSE__body       = "body"
+
+	iterator__done  = "done"
+	iterator__value = "value"
+
+	property__signal     = "signal"
+	property__buffer     = "buffer"
+	property__byteOffset = "byteOffset"
+	property__byteLength = "byteLength"
+
+	header__contentType = "Content-Type"
+	mime__json          = "application/json"
 )
 
-var (
-	xhttp js.Value
+// RequestMode maps to the fetch `mode` option.
+type RequestMode string
+
+const (
+	ModeDefault    RequestMode = ""
+	ModeCORS       RequestMode = "cors"
+	ModeNoCORS     RequestMode = "no-cors"
+	ModeSameOrigin RequestMode = "same-origin"
 )
 
-func init() {
-	xhttp = js.Global().Get(xhttp__constructor)
+// CredentialsMode maps to the fetch `credentials` option.
+type CredentialsMode string
+
+const (
+	CredentialsDefault    CredentialsMode = ""
+	CredentialsOmit       CredentialsMode = "omit"
+	CredentialsSameOrigin CredentialsMode = "same-origin"
+	CredentialsInclude    CredentialsMode = "include"
+)
+
+// Request describes a single fetch call. Body takes precedence over BodyReader.
+type Request struct {
+	Method string
+	URL    string
+	Header http.Header
+
+	Body []byte
+	// BodyReader is read to the end into memory before the request is sent,
+	// so the whole upload has to fit in the wasm heap, unless StreamBody is
+	// set.
+	BodyReader io.Reader
+	// StreamBody pipes BodyReader into the request as a ReadableStream
+	// instead of buffering it. Streaming uploads need a Chromium based
+	// browser and an HTTP/2 server; elsewhere Do returns an error wrapping
+	// GOWEB_ERROR_UNSUPPORTED.
+	StreamBody bool
+
+	Mode        RequestMode
+	Credentials CredentialsMode
+}
+
+// NewRequest ...
+func NewRequest(_method, _url string, _body []byte) *Request {
+	return &Request{
+		Method: _method,
+		URL:    _url,
+		Header: http.Header{},
+		Body:   _body,
+	}
+}
+
+// NewJSONRequest encodes _v as the request body and sets the Content-Type.
+func NewJSONRequest(_method, _url string, _v interface{}) (*Request, error) {
+	b, err := json.Marshal(_v)
+	if err != nil {
+		return nil, fmt.Errorf("[fetch] [NewJSONRequest] [error]: %v", err)
+	}
+	r := NewRequest(_method, _url, b)
+	r.Header.Set(header__contentType, mime__json)
+	return r, nil
+}
+
+// Response is the Go side of a fetch Response. Body streams from
+// `response.body` where the browser supports it and must be closed.
+type Response struct {
+	Status     int
+	StatusText string
+	OK         bool
+	URL        string
+	Redirected bool
+	Header     http.Header
+	Body       io.ReadCloser
+
+	Value js.Value
+}
+
+// Bytes reads and closes the response body.
+func (r *Response) Bytes() ([]byte, error) {
+	defer r.Body.Close()
+	return ioutil.ReadAll(r.Body)
+}
+
+// Text ...
+func (r *Response) Text() (string, error) {
+	b, err := r.Bytes()
+	return string(b), err
+}
+
+// JSON decodes the response body into _v.
+func (r *Response) JSON(_v interface{}) error {
+	defer r.Body.Close()
+	return json.NewDecoder(r.Body).Decode(_v)
+}
+
+// Client issues requests through the browser's fetch API. Timeout, Mode,
+// Credentials, Header and StreamBody apply to every request unless the
+// Request overrides them; set StreamBody to stream http.Request bodies sent
+// through RoundTrip.
+type Client struct {
+	Timeout     time.Duration
+	Mode        RequestMode
+	Credentials CredentialsMode
+	Header      http.Header
+	StreamBody  bool
+}
+
+// NewClient ...
+func NewClient() *Client {
+	return &Client{Header: http.Header{}}
 }
 
-func NewXHTTPRequest(_type string, _url string, _async bool) (js.Value, error) {
-	err := ValidJSValue(xhttp__constructor, xhttp)
+// Do sends _req and returns once the response headers are in. _ctx (and the
+// client Timeout) stay attached to the request through an AbortController
+// until the response body is closed.
+func (c *Client) Do(_ctx context.Context, _req *Request) (*Response, error) {
+	f := js.Global().Get(fetch)
+	if err := ValidJSValue(fetch, f); err != nil {
+		return nil, fmt.Errorf("[fetch] [Do] [error]: %v", err)
+	}
+
+	init, release, err := c.requestInit(_req)
 	if err != nil {
-		return js.ValueOf(nil), err
+		return nil, fmt.Errorf("[fetch] [Do] [error]: %w", err)
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if c.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(_ctx, c.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(_ctx)
 	}
 
-	xhttp.Call(function__xhttp_open, _type, _url, _async)
-	resp := xhttp.Call(function__xhttp_send)
-	if err = ValidJSValue(XHTTP__response, resp); err != nil {
-		return js.ValueOf(nil), err
+	done := make(chan struct{})
+	var once sync.Once
+	finish := func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+			release()
+		})
 	}
 
+	if ac := js.Global().Get(abortController); ValidJSValue(abortController, ac) == nil {
+		ctrl := ac.New()
+		init[REQUEST__signal] = ctrl.Get(property__signal)
+		go func() {
+			select {
+			case <-ctx.Done():
+				ctrl.Call(function__abort)
+			case <-done:
+			}
+		}()
+	}
+
+	tv, err := Await(ctx, f.Invoke(_req.URL, init))
+	if err != nil {
+		finish()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, fmt.Errorf("[fetch] [Do] %s %s [error]: %v", init[REQUEST__method], _req.URL, err)
+	}
+
+	resp := &Response{
+		Status:     tv.Get(RESPONSE__status).Int(),
+		StatusText: tv.Get(RESPONSE__statusText).String(),
+		OK:         tv.Get(RESPONSE__ok).Bool(),
+		URL:        tv.Get(RESPONSE__url).String(),
+		Redirected: tv.Get(RESPONSE__redirected).Bool(),
+		Header:     readHeaders(tv.Get(RESPONSE__headers)),
+		Value:      tv,
+	}
+
+	if body := tv.Get(RESPONSE__body); ValidJSValue(RESPONSE__body, body) == nil {
+		resp.Body = &streamReader{
+			ctx:     ctx,
+			reader:  body.Call(function__getReader),
+			onClose: finish,
+		}
+		return resp, nil
+	}
+
+	// no streaming support (or a body-less response): buffer it up front
+	defer finish()
+	buf, err := Await(ctx, tv.Call(function__arrayBuffer))
+	if err != nil {
+		return nil, fmt.Errorf("[fetch] [Do] %s %s [error]: %v", init[REQUEST__method], _req.URL, err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(bytesOf(buf)))
 	return resp, nil
 }
+
+// Get ...
+func (c *Client) Get(_ctx context.Context, _url string) (*Response, error) {
+	return c.Do(_ctx, NewRequest(http.MethodGet, _url, nil))
+}
+
+// Post ...
+func (c *Client) Post(_ctx context.Context, _url, _contentType string, _body []byte) (*Response, error) {
+	r := NewRequest(http.MethodPost, _url, _body)
+	if _contentType != "" {
+		r.Header.Set(header__contentType, _contentType)
+	}
+	return c.Do(_ctx, r)
+}
+
+// PostJSON encodes _v and posts it as application/json.
+func (c *Client) PostJSON(_ctx context.Context, _url string, _v interface{}) (*Response, error) {
+	r, err := NewJSONRequest(http.MethodPost, _url, _v)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(_ctx, r)
+}
+
+// RoundTrip implements http.RoundTripper, so the client can back a regular
+// http.Client: &http.Client{Transport: web.NewClient()}.
+func (c *Client) RoundTrip(_req *http.Request) (*http.Response, error) {
+	r := &Request{
+		Method: _req.Method,
+		URL:    _req.URL.String(),
+		Header: _req.Header,
+	}
+	if _req.Body != nil && _req.Body != http.NoBody {
+		defer _req.Body.Close()
+		r.BodyReader = _req.Body
+	}
+
+	resp, err := c.Do(_req.Context(), r)
+	if err != nil {
+		return nil, err
+	}
+
+	contentLength := int64(-1)
+	if cl, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		contentLength = cl
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.Status, resp.StatusText),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        resp.Header,
+		Body:          resp.Body,
+		ContentLength: contentLength,
+		Request:       _req,
+	}, nil
+}
+
+// requestInit builds the fetch options; the returned func releases the body
+// stream, if any, once the request is over.
+func (c *Client) requestInit(_req *Request) (map[string]interface{}, func(), error) {
+	method := strings.ToUpper(_req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	headers := map[string]interface{}{}
+	for k, v := range c.Header {
+		headers[k] = strings.Join(v, ", ")
+	}
+	for k, v := range _req.Header {
+		headers[k] = strings.Join(v, ", ")
+	}
+
+	init := map[string]interface{}{
+		REQUEST__method:  method,
+		REQUEST__headers: headers,
+	}
+
+	release := func() {}
+	body := _req.Body
+	if body == nil && _req.BodyReader != nil {
+		if _req.StreamBody || c.StreamBody {
+			if !requestStreams() {
+				return nil, nil, GOWEB_ERROR_UNSUPPORTED
+			}
+			init[REQUEST__body], release = bodyStream(_req.BodyReader)
+			init[REQUEST__duplex] = "half"
+		} else {
+			b, err := ioutil.ReadAll(_req.BodyReader)
+			if err != nil {
+				return nil, nil, err
+			}
+			body = b
+		}
+	}
+	if len(body) > 0 {
+		init[REQUEST__body] = uint8ArrayOf(body)
+	}
+
+	if mode := _req.Mode; mode != ModeDefault {
+		init[REQUEST__mode] = string(mode)
+	} else if c.Mode != ModeDefault {
+		init[REQUEST__mode] = string(c.Mode)
+	}
+	if cred := _req.Credentials; cred != CredentialsDefault {
+		init[REQUEST__credentials] = string(cred)
+	} else if c.Credentials != CredentialsDefault {
+		init[REQUEST__credentials] = string(c.Credentials)
+	}
+
+	return init, release, nil
+}
+
+// GOWEB_ERROR_UNSUPPORTED is wrapped by the errors of browser APIs that are
+// missing, e.g. outside a secure context.
+var GOWEB_ERROR_UNSUPPORTED = errors.New("not supported by this browser")
+
+var (
+	requestStreamsOnce sync.Once
+	requestStreamsOK   bool
+)
+
+// requestStreams reports whether fetch accepts a ReadableStream body: such
+// browsers read the duplex option and do not stringify the stream into a
+// text/plain body.
+func requestStreams() bool {
+	requestStreamsOnce.Do(func() {
+		rs, req := js.Global().Get(readableStream__name), js.Global().Get(request__name)
+		if rs.Type() != js.TypeFunction || req.Type() != js.TypeFunction {
+			return
+		}
+		read := false
+		get := js.FuncOf(func(js.Value, []js.Value) interface{} {
+			read = true
+			return "half"
+		})
+		defer get.Release()
+
+		init := js.Global().Get("Object").New()
+		init.Set(REQUEST__method, http.MethodPost)
+		init.Set(REQUEST__body, rs.New())
+		js.Global().Get("Object").Call("defineProperty", init, REQUEST__duplex, map[string]interface{}{"get": get})
+
+		r, err := newJS(req, "data:,", init)
+		requestStreamsOK = err == nil && read && !r.Get(REQUEST__headers).Call("has", header__contentType).Bool()
+	})
+	return requestStreamsOK
+}
+
+// bodyStream pipes _r into a ReadableStream. Each pull reads one chunk in a
+// goroutine, so a slow reader never blocks the browser. The returned func
+// releases the stream callbacks.
+func bodyStream(_r io.Reader) (js.Value, func()) {
+	var pull, cancel js.Func
+	pull = js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		ctrl := _args[0]
+		p, resolve, _ := deferred()
+		go func() {
+			buf := make([]byte, stream__chunk)
+			n, err := _r.Read(buf)
+			// the controller throws once fetch has cancelled the stream
+			if n > 0 {
+				callJS(ctrl, function__enqueue, uint8ArrayOf(buf[:n]))
+			}
+			switch {
+			case err == io.EOF:
+				callJS(ctrl, function__close)
+			case err != nil:
+				callJS(ctrl, function__error, js.Global().Get("Error").New(err.Error()))
+			}
+			resolve.Invoke()
+		}()
+		return p.Value
+	})
+	cancel = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		if c, ok := _r.(io.Closer); ok {
+			go c.Close()
+		}
+		return nil
+	})
+
+	src := map[string]interface{}{stream__pull: pull, stream__cancel: cancel}
+	stream := js.Global().Get(readableStream__name).New(src)
+	return stream, func() {
+		pull.Release()
+		cancel.Release()
+	}
+}
+
+func readHeaders(_v js.Value) http.Header {
+	h := http.Header{}
+	if err := ValidJSValue(RESPONSE__headers, _v); err != nil {
+		return h
+	}
+	it := _v.Call(function__entries)
+	for {
+		n := it.Call(function__next)
+		if n.Get(iterator__done).Bool() {
+			break
+		}
+		pair := n.Get(iterator__value)
+		h.Add(pair.Index(0).String(), pair.Index(1).String())
+	}
+	return h
+}
+
+// streamReader reads a ReadableStream chunk by chunk through its default reader.
+type streamReader struct {
+	ctx     context.Context
+	reader  js.Value
+	onClose func()
+
+	// rmu serializes Read, which keeps the rest of a chunk in buf.
+	rmu sync.Mutex
+	buf []byte
+
+	// mu guards eof and closed, which Close sets while a Read may be waiting.
+	mu     sync.Mutex
+	eof    bool
+	closed bool
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	s.rmu.Lock()
+	defer s.rmu.Unlock()
+	for len(s.buf) == 0 {
+		s.mu.Lock()
+		eof := s.eof
+		s.mu.Unlock()
+		if eof {
+			return 0, io.EOF
+		}
+		chunk, err := Await(s.ctx, s.reader.Call(function__read))
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return 0, io.EOF
+			}
+			if s.ctx.Err() != nil {
+				return 0, s.ctx.Err()
+			}
+			return 0, err
+		}
+		if chunk.Get(iterator__done).Bool() {
+			s.mu.Lock()
+			s.eof = true
+			s.mu.Unlock()
+			continue
+		}
+		s.buf = bytesOf(chunk.Get(iterator__value))
+	}
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		s.buf = nil
+		return 0, io.EOF
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// Close cancels the stream, which ends a Read waiting on it with io.EOF.
+func (s *streamReader) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	cancel := !s.eof
+	s.eof = true
+	s.mu.Unlock()
+	if cancel {
+		s.reader.Call(function__cancel)
+	}
+	if s.onClose != nil {
+		s.onClose()
+	}
+	return nil
+}
+
+// uint8ArrayOf copies _b into a new JS Uint8Array.
+func uint8ArrayOf(_b []byte) js.Value {
+	a := js.Global().Get(uint8Array__name).New(len(_b))
+	js.CopyBytesToJS(a, _b)
+	return a
+}
+
+// bytesOf copies an ArrayBuffer or any typed array view into a Go slice.
+func bytesOf(_v js.Value) []byte {
+	if ValidJSValue(arrayBuffer__name, _v) != nil {
+		return nil
+	}
+	u8 := js.Global().Get(uint8Array__name)
+	var a js.Value
+	switch {
+	case _v.InstanceOf(u8):
+		a = _v
+	case _v.InstanceOf(js.Global().Get(arrayBuffer__name)):
+		a = u8.New(_v)
+	default:
+		a = u8.New(_v.Get(property__buffer), _v.Get(property__byteOffset), _v.Get(property__byteLength))
+	}
+	b := make([]byte, a.Length())
+	js.CopyBytesToGo(b, a)
+	return b
+}
@@ -0,0 +1,191 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+// fakeFetch replaces the global fetch for the rest of the test. _serve gets
+// the url and init of every call and returns a Response or a promise of one.
+func fakeFetch(t *testing.T, _serve func(_url string, _init js.Value) js.Value) {
+	t.Helper()
+	old := js.Global().Get("fetch")
+	f := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		v := _serve(_args[0].String(), _args[1])
+		return js.Global().Get("Promise").Call("resolve", v)
+	})
+	js.Global().Set("fetch", f)
+	t.Cleanup(func() {
+		js.Global().Set("fetch", old)
+		f.Release()
+	})
+}
+
+// echo answers with the request body and headers.
+func echo(_url string, _init js.Value) js.Value {
+	body := _init.Get("body")
+	if body.IsUndefined() {
+		body = js.Null()
+	}
+	return js.Global().Get("Response").New(body, map[string]interface{}{
+		"status":  201,
+		"headers": _init.Get("headers"),
+	})
+}
+
+func TestClientDo(t *testing.T) {
+	var got js.Value
+	fakeFetch(t, func(_url string, _init js.Value) js.Value {
+		got = _init
+		return echo(_url, _init)
+	})
+
+	c := web.NewClient()
+	c.Header.Set("X-App", "test")
+	c.Mode = web.ModeCORS
+	req, err := web.NewJSONRequest("put", "/api/items/1", map[string]int{"n": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Credentials = web.CredentialsInclude
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m := got.Get("method").String(); m != "PUT" {
+		t.Errorf("method = %q, want PUT", m)
+	}
+	if m := got.Get("mode").String(); m != "cors" {
+		t.Errorf("mode = %q, want cors", m)
+	}
+	if c := got.Get("credentials").String(); c != "include" {
+		t.Errorf("credentials = %q, want include", c)
+	}
+	if resp.Status != 201 || !resp.OK {
+		t.Errorf("Status = %d OK = %v, want 201 true", resp.Status, resp.OK)
+	}
+	if h := resp.Header.Get("X-App"); h != "test" {
+		t.Errorf("X-App = %q, want the client header", h)
+	}
+	if h := resp.Header.Get("Content-Type"); h != "application/json" {
+		t.Errorf("Content-Type = %q", h)
+	}
+	var out map[string]int
+	if err := resp.JSON(&out); err != nil || out["n"] != 1 {
+		t.Errorf("JSON = %v, %v", out, err)
+	}
+}
+
+func TestClientBodyReader(t *testing.T) {
+	fakeFetch(t, echo)
+	c := web.NewClient()
+
+	want := strings.Repeat("0123456789", 10000)
+	resp, err := c.Do(context.Background(), &web.Request{
+		Method:     http.MethodPost,
+		URL:        "/upload",
+		BodyReader: strings.NewReader(want),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := resp.Text(); err != nil || s != want {
+		t.Errorf("Text = %d bytes, %v; want %d bytes", len(s), err, len(want))
+	}
+
+	resp, err = c.Do(context.Background(), &web.Request{
+		Method:     http.MethodPost,
+		URL:        "/upload",
+		BodyReader: strings.NewReader(want),
+		StreamBody: true,
+	})
+	if errors.Is(err, web.GOWEB_ERROR_UNSUPPORTED) {
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := resp.Text(); err != nil || s != want {
+		t.Errorf("streamed Text = %d bytes, %v; want %d bytes", len(s), err, len(want))
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	var signal js.Value
+	never := js.FuncOf(func(js.Value, []js.Value) interface{} { return nil })
+	defer never.Release()
+	fakeFetch(t, func(_url string, _init js.Value) js.Value {
+		signal = _init.Get("signal")
+		return js.Global().Get("Promise").New(never)
+	})
+
+	c := web.NewClient()
+	c.Timeout = 10 * time.Millisecond
+	if _, err := c.Get(context.Background(), "/slow"); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Get = %v, want a deadline error", err)
+	}
+	waitFor(t, "abort", func() bool { return signal.Get("aborted").Bool() })
+}
+
+func TestClientCloseWhileReading(t *testing.T) {
+	fakeFetch(t, func(_url string, _init js.Value) js.Value {
+		stream := js.Global().Get("ReadableStream").New(map[string]interface{}{})
+		return js.Global().Get("Response").New(stream)
+	})
+
+	resp, err := web.NewClient().Get(context.Background(), "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := make(chan error, 1)
+	go func() {
+		_, err := resp.Body.Read(make([]byte, 8))
+		read <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-read:
+		if err != io.EOF {
+			t.Errorf("Read after Close = %v, want io.EOF", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not end the waiting Read")
+	}
+	if n, err := resp.Body.Read(make([]byte, 8)); n != 0 || err != io.EOF {
+		t.Errorf("Read on closed body = %d, %v", n, err)
+	}
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	fakeFetch(t, echo)
+	hc := &http.Client{Transport: web.NewClient()}
+
+	resp, err := hc.Post("https://example.com/echo", "text/plain", bytes.NewReader([]byte("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil || string(b) != "hello" {
+		t.Errorf("body = %q, %v", b, err)
+	}
+	if resp.StatusCode != 201 || resp.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("StatusCode = %d, Content-Type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
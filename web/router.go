@@ -0,0 +1,543 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall/js"
+)
+
+const (
+	WINDOW__history = "history"
+
+	EVENT__popstate   = "popstate"
+	EVENT__hashchange = "hashchange"
+
+	function__pushState    = "pushState"
+	function__replaceState = "replaceState"
+	function__back         = "back"
+	function__forward      = "forward"
+
+	location__pathname = "pathname"
+	location__search   = "search"
+	location__hash     = "hash"
+
+	anchor__href     = "href"
+	anchor__target   = "target"
+	anchor__download = "download"
+
+	function__hasAttribute = "hasAttribute"
+
+	history__state = "state"
+
+	router__maxRedirects = 8
+)
+
+var (
+	GOWEB_ERROR_ROUTER_REDIRECT_LOOP = errors.New("too many guard redirects")
+	GOWEB_ERROR_QUERY_TARGET         = errors.New("query target must be a non-nil pointer to a struct")
+)
+
+// RouterMode selects where the route path lives in the URL.
+type RouterMode int
+
+const (
+	// HistoryMode routes on location.pathname (minus Router.Base).
+	HistoryMode RouterMode = iota
+	// HashMode routes on the fragment, e.g. "/#/users/1", for static hosting
+	// without server side fallbacks.
+	HashMode
+)
+
+// RouteMatch describes the location a route handler (or guard) runs for.
+type RouteMatch struct {
+	Path    string
+	Pattern string
+	Params  map[string]string
+	Query   url.Values
+	Hash    string
+	State   js.Value
+
+	raw string
+}
+
+// Param ...
+func (m *RouteMatch) Param(_name string) string {
+	return m.Params[_name]
+}
+
+// RequestURI returns the escaped path and query string of the location, as
+// in an HTTP request line, e.g. for a "next" parameter.
+func (m *RouteMatch) RequestURI() string {
+	if i := strings.Index(m.raw, "#"); i >= 0 {
+		return m.raw[:i]
+	}
+	return m.raw
+}
+
+// DecodeQuery decodes the query string into the struct pointed to by _v; see
+// DecodeQuery.
+func (m *RouteMatch) DecodeQuery(_v interface{}) error {
+	return DecodeQuery(m.Query, _v)
+}
+
+// Guard runs before a route handler. It returns "" to let navigation continue
+// or a path to redirect to instead.
+type Guard func(*RouteMatch) string
+
+type route struct {
+	pattern  string
+	segments []string
+	handler  func(*RouteMatch)
+	guards   []Guard
+}
+
+// Router maps URL paths to handlers using the History API. Patterns are
+// slash separated; a ":name" segment captures one segment and a trailing
+// "*name" captures the rest of the path. Routes are tried in the order they
+// were added.
+//
+// Handlers and guards run synchronously, inside popstate / click callbacks
+// when the browser drives navigation, so they must not block.
+type Router struct {
+	Mode RouterMode
+	// Base is stripped from location.pathname in HistoryMode, for apps served
+	// from a sub path. Paths outside Base are matched as they are.
+	Base string
+	// NotFound runs when no route matches.
+	NotFound func(*RouteMatch)
+
+	win     *Window
+	routes  []*route
+	guards  []Guard
+	current *RouteMatch
+	off     []func()
+}
+
+// NewRouter ...
+func NewRouter(_win *Window, _mode RouterMode) *Router {
+	return &Router{Mode: _mode, win: _win}
+}
+
+// Handle registers _handler for _pattern, with guards that run after the
+// router wide ones.
+func (r *Router) Handle(_pattern string, _handler func(*RouteMatch), _guards ...Guard) *Router {
+	r.routes = append(r.routes, &route{
+		pattern:  _pattern,
+		segments: splitPath(_pattern),
+		handler:  _handler,
+		guards:   _guards,
+	})
+	return r
+}
+
+// Use adds a guard that runs for every route.
+func (r *Router) Use(_g Guard) *Router {
+	r.guards = append(r.guards, _g)
+	return r
+}
+
+// Start listens for back/forward navigation and hash edits and dispatches the
+// current location.
+func (r *Router) Start() error {
+	if _, err := r.win.GetGlobal(WINDOW__history); err != nil {
+		return fmt.Errorf("[router] [Start] [error]: %v", err)
+	}
+	r.Stop()
+	onChange := func(Event) {
+		if err := r.dispatch(false); err != nil {
+			r.win.Error(err)
+		}
+	}
+	r.off = append(r.off, r.win.On(EVENT__popstate, onChange), r.win.On(EVENT__hashchange, onChange))
+	return r.dispatch(true)
+}
+
+// Stop removes the router's listeners.
+func (r *Router) Stop() {
+	for _, off := range r.off {
+		off()
+	}
+	r.off = nil
+}
+
+// Current returns the last dispatched match, or nil before Start.
+func (r *Router) Current() *RouteMatch {
+	return r.current
+}
+
+// Navigate pushes a history entry for _path (which may carry a query string
+// and fragment) and dispatches it.
+func (r *Router) Navigate(_path string) error {
+	return r.navigate(function__pushState, _path, nil)
+}
+
+// NavigateState is Navigate with a history state value, read back from
+// RouteMatch.State.
+func (r *Router) NavigateState(_path string, _state interface{}) error {
+	return r.navigate(function__pushState, _path, _state)
+}
+
+// Replace swaps the current history entry for _path and dispatches it.
+func (r *Router) Replace(_path string) error {
+	return r.navigate(function__replaceState, _path, nil)
+}
+
+// Back ...
+func (r *Router) Back() {
+	if h, err := r.win.GetGlobal(WINDOW__history); err == nil {
+		h.Call(function__back)
+	}
+}
+
+// Forward ...
+func (r *Router) Forward() {
+	if h, err := r.win.GetGlobal(WINDOW__history); err == nil {
+		h.Call(function__forward)
+	}
+}
+
+// InterceptLinks routes clicks on same-origin <a href> links inside _root
+// through Navigate instead of reloading the page. Relative hrefs are resolved
+// against the current location; in HashMode only "#" links are routes. Links
+// with a target, a download attribute or a modified click, and links outside
+// Base, are left to the browser.
+func (r *Router) InterceptLinks(_root *Element) func() {
+	return _root.Delegate(EVENT__click, "a[href]", func(_e Event) {
+		m := _e.Mouse()
+		if m.Button() != 0 || m.Modifiers() != (Modifiers{}) || _e.DefaultPrevented() {
+			return
+		}
+		a := _e.DelegateTarget()
+		if t := a.Value.Call(function__getAttribute, anchor__target); t.Truthy() && t.String() != "_self" {
+			return
+		}
+		if a.Value.Call(function__hasAttribute, anchor__download).Truthy() {
+			return
+		}
+		p, ok := r.linkPath(a.Value.Call(function__getAttribute, anchor__href).String())
+		if !ok {
+			return
+		}
+		_e.PreventDefault()
+		if err := r.Navigate(p); err != nil {
+			r.win.Error(err)
+		}
+	})
+}
+
+// linkPath resolves a link's href into a route path, reporting false for
+// links the router does not handle.
+func (r *Router) linkPath(_href string) (string, bool) {
+	if r.Mode == HashMode {
+		if !strings.HasPrefix(_href, "#") {
+			return "", false
+		}
+		ref, err := url.Parse(_href[1:])
+		if err != nil {
+			return "", false
+		}
+		cur, err := r.location()
+		if err != nil {
+			return "", false
+		}
+		base, err := url.Parse(cur)
+		if err != nil {
+			return "", false
+		}
+		return base.ResolveReference(ref).String(), true
+	}
+
+	// a bare fragment is an in-page anchor
+	if strings.HasPrefix(_href, "#") {
+		return "", false
+	}
+	loc, err := r.win.GetGlobal(WINDOW__location)
+	if err != nil {
+		return "", false
+	}
+	base, err := url.Parse(loc.Get(anchor__href).String())
+	if err != nil {
+		return "", false
+	}
+	ref, err := url.Parse(_href)
+	if err != nil {
+		return "", false
+	}
+	u := base.ResolveReference(ref)
+	if u.Scheme != base.Scheme || u.Host != base.Host {
+		return "", false
+	}
+	p := u.EscapedPath()
+	if b := strings.TrimSuffix(r.Base, "/"); b != "" {
+		if p != b && !strings.HasPrefix(p, b+"/") {
+			return "", false
+		}
+		p = strings.TrimPrefix(p, b)
+	}
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	if u.Fragment != "" {
+		p += "#" + u.EscapedFragment()
+	}
+	return p, true
+}
+
+func (r *Router) navigate(_method, _path string, _state interface{}) error {
+	h, err := r.win.GetGlobal(WINDOW__history)
+	if err != nil {
+		return fmt.Errorf("[router] [%s] [error]: %v", _method, err)
+	}
+	if _, err = callJS(h, _method, Marshal(_state), "", r.href(_path)); err != nil {
+		return fmt.Errorf("[router] [%s] [%s] [error]: %v", _method, _path, err)
+	}
+	return r.dispatch(true)
+}
+
+// href turns a route path into the URL written to the address bar.
+func (r *Router) href(_path string) string {
+	if !strings.HasPrefix(_path, "/") {
+		_path = "/" + _path
+	}
+	if r.Mode == HashMode {
+		return "#" + _path
+	}
+	return strings.TrimSuffix(r.Base, "/") + _path
+}
+
+// location reads the route path, query and fragment from window.location.
+func (r *Router) location() (string, error) {
+	loc, err := r.win.GetGlobal(WINDOW__location)
+	if err != nil {
+		return "", err
+	}
+	if r.Mode == HashMode {
+		p := strings.TrimPrefix(loc.Get(location__hash).String(), "#")
+		if p == "" {
+			p = "/"
+		}
+		return p, nil
+	}
+	p := loc.Get(location__pathname).String()
+	if b := strings.TrimSuffix(r.Base, "/"); b != "" && (p == b || strings.HasPrefix(p, b+"/")) {
+		p = strings.TrimPrefix(p, b)
+	}
+	return p + loc.Get(location__search).String() + loc.Get(location__hash).String(), nil
+}
+
+// dispatch matches the current location and runs guards and the handler. A
+// guard redirect replaces the history entry. Unless _force is set, a location
+// equal to the current match is ignored, so popstate and hashchange firing for
+// the same change dispatch once.
+func (r *Router) dispatch(_force bool) error {
+	for i := 0; i < router__maxRedirects; i++ {
+		full, err := r.location()
+		if err != nil {
+			return fmt.Errorf("[router] [dispatch] [error]: %v", err)
+		}
+		if !_force && r.current != nil && r.current.raw == full {
+			return nil
+		}
+
+		m, rt := r.match(full)
+		if h, err := r.win.GetGlobal(WINDOW__history); err == nil {
+			m.State = h.Get(history__state)
+		}
+
+		guards := r.guards
+		if rt != nil {
+			guards = append(append([]Guard{}, r.guards...), rt.guards...)
+		}
+		redirect := ""
+		for _, g := range guards {
+			if redirect = g(m); redirect != "" {
+				break
+			}
+		}
+		if redirect != "" {
+			hist, err := r.win.GetGlobal(WINDOW__history)
+			if err != nil {
+				return fmt.Errorf("[router] [dispatch] [error]: %v", err)
+			}
+			if _, err = callJS(hist, function__replaceState, js.Null(), "", r.href(redirect)); err != nil {
+				return fmt.Errorf("[router] [dispatch] [%s] [error]: %v", redirect, err)
+			}
+			_force = true
+			continue
+		}
+
+		r.current = m
+		switch {
+		case rt != nil:
+			rt.handler(m)
+		case r.NotFound != nil:
+			r.NotFound(m)
+		}
+		return nil
+	}
+	return fmt.Errorf("[router] [dispatch] [error]: %w", GOWEB_ERROR_ROUTER_REDIRECT_LOOP)
+}
+
+func (r *Router) match(_full string) (*RouteMatch, *route) {
+	m := &RouteMatch{Params: map[string]string{}, Query: url.Values{}, raw: _full}
+	p := _full
+	if i := strings.Index(p, "#"); i >= 0 {
+		m.Hash = p[i+1:]
+		p = p[:i]
+	}
+	if i := strings.Index(p, "?"); i >= 0 {
+		m.Query, _ = url.ParseQuery(p[i+1:])
+		p = p[:i]
+	}
+	if p == "" {
+		p = "/"
+	}
+	m.Path = unescapePath(p)
+
+	// split before unescaping, so an escaped "/" stays inside its segment
+	segs := splitPath(p)
+	for i := range segs {
+		segs[i] = unescapePath(segs[i])
+	}
+	for _, rt := range r.routes {
+		if params, ok := rt.match(segs); ok {
+			m.Pattern = rt.pattern
+			m.Params = params
+			return m, rt
+		}
+	}
+	return m, nil
+}
+
+func (rt *route) match(_segs []string) (map[string]string, bool) {
+	params := map[string]string{}
+	for i, s := range rt.segments {
+		if strings.HasPrefix(s, "*") {
+			params[s[1:]] = strings.Join(_segs[i:], "/")
+			return params, true
+		}
+		if i >= len(_segs) {
+			return nil, false
+		}
+		if strings.HasPrefix(s, ":") {
+			params[s[1:]] = _segs[i]
+			continue
+		}
+		if s != _segs[i] {
+			return nil, false
+		}
+	}
+	return params, len(rt.segments) == len(_segs)
+}
+
+func unescapePath(_p string) string {
+	if dec, err := url.PathUnescape(_p); err == nil {
+		return dec
+	}
+	return _p
+}
+
+func splitPath(_p string) []string {
+	_p = strings.Trim(_p, "/")
+	if _p == "" {
+		return []string{}
+	}
+	return strings.Split(_p, "/")
+}
+
+// DecodeQuery fills the struct pointed to by _v from query values. Fields are
+// matched by their `query:"name"` tag (or field name); "-" skips a field.
+// Strings, bools, ints, uints, floats and slices of them are supported; a
+// scalar field takes the first value.
+func DecodeQuery(_q url.Values, _v interface{}) error {
+	rv := reflect.ValueOf(_v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return GOWEB_ERROR_QUERY_TARGET
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("query"); ok {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		vals, ok := _q[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Slice {
+			s := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+			for j, v := range vals {
+				if err := setQueryValue(s.Index(j), v); err != nil {
+					return fmt.Errorf("[router] [DecodeQuery] [%s] [error]: %v", name, err)
+				}
+			}
+			fv.Set(s)
+			continue
+		}
+		if err := setQueryValue(fv, vals[0]); err != nil {
+			return fmt.Errorf("[router] [DecodeQuery] [%s] [error]: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func setQueryValue(_fv reflect.Value, _s string) error {
+	switch _fv.Kind() {
+	case reflect.String:
+		_fv.SetString(_s)
+	case reflect.Bool:
+		if _s == "" {
+			_fv.SetBool(true)
+			return nil
+		}
+		b, err := strconv.ParseBool(_s)
+		if err != nil {
+			return err
+		}
+		_fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(_s, 10, _fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		_fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(_s, 10, _fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		_fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(_s, _fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		_fv.SetFloat(n)
+	case reflect.Ptr:
+		p := reflect.New(_fv.Type().Elem())
+		if err := setQueryValue(p.Elem(), _s); err != nil {
+			return err
+		}
+		_fv.Set(p)
+	default:
+		return fmt.Errorf("unsupported field type %s", _fv.Type())
+	}
+	return nil
+}
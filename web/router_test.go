@@ -0,0 +1,280 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+// routes records which pattern handled each dispatch.
+type routes struct {
+	hits []*web.RouteMatch
+}
+
+func (r *routes) handle(_m *web.RouteMatch) { r.hits = append(r.hits, _m) }
+
+func (r *routes) last(t *testing.T) *web.RouteMatch {
+	t.Helper()
+	if len(r.hits) == 0 {
+		t.Fatal("no route dispatched")
+	}
+	return r.hits[len(r.hits)-1]
+}
+
+func TestRouterParams(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	var got routes
+	var missed []string
+	r := web.NewRouter(web.NewWindow(), web.HistoryMode).
+		Handle("/", got.handle).
+		Handle("/users/:id", got.handle).
+		Handle("/users/:id/posts/:post", got.handle).
+		Handle("/files/*rest", got.handle)
+	r.NotFound = func(_m *web.RouteMatch) { missed = append(missed, _m.Path) }
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if m := got.last(t); m.Pattern != "/" {
+		t.Errorf("Start matched %q, want /", m.Pattern)
+	}
+
+	for _, c := range []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/users/42", "/users/:id", map[string]string{"id": "42"}},
+		{"/users/a%2Fb", "/users/:id", map[string]string{"id": "a/b"}},
+		{"/users/7/posts/hello%20world", "/users/:id/posts/:post", map[string]string{"id": "7", "post": "hello world"}},
+		{"/files/docs/a.txt", "/files/*rest", map[string]string{"rest": "docs/a.txt"}},
+	} {
+		if err := r.Navigate(c.path); err != nil {
+			t.Fatal(err)
+		}
+		m := got.last(t)
+		if m.Pattern != c.pattern {
+			t.Errorf("Navigate(%s) matched %q, want %q", c.path, m.Pattern, c.pattern)
+			continue
+		}
+		for k, v := range c.params {
+			if m.Param(k) != v {
+				t.Errorf("Navigate(%s): %s = %q, want %q", c.path, k, m.Param(k), v)
+			}
+		}
+	}
+
+	if err := r.Navigate("/users"); err != nil {
+		t.Fatal(err)
+	}
+	if len(missed) != 1 || missed[0] != "/users" {
+		t.Errorf("NotFound saw %v, want [/users]", missed)
+	}
+}
+
+func TestRouterHistoryEvents(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	var got routes
+	r := web.NewRouter(web.NewWindow(), web.HistoryMode).
+		Handle("/", got.handle).
+		Handle("/a", got.handle).
+		Handle("/b", got.handle)
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+	if err := r.Navigate("/a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.NavigateState("/b", map[string]interface{}{"n": 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	n := len(got.hits)
+	r.Back()
+	if len(got.hits) != n+1 || got.last(t).Path != "/a" {
+		t.Fatalf("Back dispatched %d times to %q, want once to /a", len(got.hits)-n, got.last(t).Path)
+	}
+	r.Forward()
+	if m := got.last(t); m.Path != "/b" || m.State.Get("n").Int() != 2 {
+		t.Errorf("Forward dispatched %q with state %v", m.Path, m.State)
+	}
+
+	// after Stop the browser's navigation is no longer routed
+	r.Stop()
+	n = len(got.hits)
+	r.Back()
+	if len(got.hits) != n {
+		t.Errorf("popstate dispatched after Stop")
+	}
+}
+
+func TestRouterHashMode(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	var got routes
+	r := web.NewRouter(web.NewWindow(), web.HashMode).
+		Handle("/", got.handle).
+		Handle("/users/:id", got.handle)
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	// editing the fragment fires popstate and hashchange; it dispatches once
+	n := len(got.hits)
+	h.SetURL("#/users/3?tab=posts")
+	if len(got.hits) != n+1 {
+		t.Fatalf("fragment edit dispatched %d times, want 1", len(got.hits)-n)
+	}
+	if m := got.last(t); m.Param("id") != "3" || m.Query.Get("tab") != "posts" {
+		t.Errorf("hashchange matched %+v", m)
+	}
+
+	if err := r.Navigate("/users/4"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(h.URL(), "/#/users/4") {
+		t.Errorf("URL = %q, want the route in the fragment", h.URL())
+	}
+}
+
+func TestRouterBase(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	var got routes
+	r := web.NewRouter(web.NewWindow(), web.HistoryMode).
+		Handle("/", got.handle).
+		Handle("/users/:id", got.handle)
+	r.Base = "/app/"
+	r.NotFound = got.handle
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	if err := r.Navigate("/users/1"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(h.URL(), "/app/users/1") {
+		t.Errorf("URL = %q, want it under Base", h.URL())
+	}
+	if m := got.last(t); m.Pattern != "/users/:id" {
+		t.Errorf("matched %q", m.Pattern)
+	}
+
+	// a path that merely starts with the Base string is outside it
+	h.SetURL("/apple/users/1")
+	if m := got.last(t); m.Path != "/apple/users/1" || m.Pattern != "" {
+		t.Errorf("outside Base matched %q as %q", m.Path, m.Pattern)
+	}
+	h.SetURL("/app")
+	if m := got.last(t); m.Pattern != "/" {
+		t.Errorf("Base itself matched %q, want /", m.Pattern)
+	}
+}
+
+func TestRouterGuards(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	var got routes
+	signedIn := false
+	var seen []string
+	r := web.NewRouter(web.NewWindow(), web.HistoryMode).
+		Use(func(_m *web.RouteMatch) string {
+			seen = append(seen, _m.Path)
+			return ""
+		}).
+		Handle("/", got.handle).
+		Handle("/login", got.handle).
+		Handle("/admin", got.handle, func(_m *web.RouteMatch) string {
+			if signedIn {
+				return ""
+			}
+			return "/login?next=" + url.QueryEscape(_m.RequestURI())
+		}).
+		Handle("/loop", got.handle, func(*web.RouteMatch) string { return "/loop" })
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Navigate("/admin?tab=users"); err != nil {
+		t.Fatal(err)
+	}
+	m := got.last(t)
+	if m.Pattern != "/login" || m.Query.Get("next") != "/admin?tab=users" {
+		t.Errorf("guarded route went to %q next=%q", m.Pattern, m.Query.Get("next"))
+	}
+	if len(h.CallsTo(webtest.TargetHistory, "replaceState")) != 1 {
+		t.Errorf("redirect did not replace the history entry")
+	}
+	if len(seen) != 3 || seen[1] != "/admin" || seen[2] != "/login" {
+		t.Errorf("router guard saw %v", seen)
+	}
+
+	signedIn = true
+	if err := r.Navigate("/admin"); err != nil {
+		t.Fatal(err)
+	}
+	if m := got.last(t); m.Pattern != "/admin" {
+		t.Errorf("signed in route went to %q", m.Pattern)
+	}
+
+	if err := r.Navigate("/loop"); !errors.Is(err, web.GOWEB_ERROR_ROUTER_REDIRECT_LOOP) {
+		t.Errorf("Navigate(/loop) = %v, want GOWEB_ERROR_ROUTER_REDIRECT_LOOP", err)
+	}
+}
+
+func TestDecodeQuery(t *testing.T) {
+	type search struct {
+		Term  string   `query:"q"`
+		Page  int      `query:"page"`
+		Tags  []string `query:"tag"`
+		Exact bool     `query:"exact"`
+		Limit *uint    `query:"limit"`
+		Skip  string   `query:"-"`
+		Sort  string
+	}
+
+	var s search
+	q, _ := url.ParseQuery("q=go+wasm&page=2&tag=a&tag=b&exact&limit=10&Sort=new&-=x")
+	if err := web.DecodeQuery(q, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Term != "go wasm" || s.Page != 2 || len(s.Tags) != 2 || s.Tags[1] != "b" || !s.Exact ||
+		s.Limit == nil || *s.Limit != 10 || s.Skip != "" || s.Sort != "new" {
+		t.Errorf("DecodeQuery = %+v", s)
+	}
+
+	q, _ = url.ParseQuery("page=two")
+	if err := web.DecodeQuery(q, &s); err == nil {
+		t.Error("DecodeQuery(page=two) succeeded")
+	}
+	if err := web.DecodeQuery(q, s); !errors.Is(err, web.GOWEB_ERROR_QUERY_TARGET) {
+		t.Errorf("DecodeQuery(non-pointer) = %v", err)
+	}
+
+	var m *web.RouteMatch
+	h := webtest.Install()
+	defer h.Uninstall()
+	r := web.NewRouter(web.NewWindow(), web.HistoryMode).Handle("/s", func(_m *web.RouteMatch) { m = _m })
+	if err := r.Navigate("/s?q=x&page=3"); err != nil {
+		t.Fatal(err)
+	}
+	s = search{}
+	if err := m.DecodeQuery(&s); err != nil || s.Term != "x" || s.Page != 3 {
+		t.Errorf("RouteMatch.DecodeQuery = %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,234 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	deviceOrientationEvent__constructor = "DeviceOrientationEvent"
+	deviceMotionEvent__constructor      = "DeviceMotionEvent"
+
+	EVENT__deviceorientation         = "deviceorientation"
+	EVENT__deviceorientationabsolute = "deviceorientationabsolute"
+	EVENT__devicemotion              = "devicemotion"
+
+	orientation__alpha          = "alpha"
+	orientation__beta           = "beta"
+	orientation__gamma          = "gamma"
+	orientation__absolute       = "absolute"
+	orientation__compassHeading = "webkitCompassHeading"
+
+	motion__acceleration                 = "acceleration"
+	motion__accelerationIncludingGravity = "accelerationIncludingGravity"
+	motion__rotationRate                 = "rotationRate"
+	motion__interval                     = "interval"
+
+	PERMISSION__accelerometer = "accelerometer"
+	PERMISSION__gyroscope     = "gyroscope"
+	PERMISSION__magnetometer  = "magnetometer"
+)
+
+// Orientation is a deviceorientation reading in degrees; fields the device
+// does not report are NaN.
+type Orientation struct {
+	// Alpha is the rotation around z, 0 to 360.
+	Alpha float64
+	// Beta is the front to back tilt, -180 to 180.
+	Beta float64
+	// Gamma is the left to right tilt, -90 to 90.
+	Gamma float64
+	// Absolute is set when Alpha is relative to north rather than to the
+	// orientation at page load.
+	Absolute bool
+	// Heading is the compass heading iOS reports instead of an absolute
+	// Alpha.
+	Heading float64
+}
+
+// Vector3 ...
+type Vector3 struct {
+	X, Y, Z float64
+}
+
+// RotationRate is in degrees per second.
+type RotationRate struct {
+	Alpha, Beta, Gamma float64
+}
+
+// Motion is a devicemotion reading. Acceleration is in m/s² without gravity
+// and is NaN on devices without a gyroscope, where only
+// AccelerationIncludingGravity is measured.
+type Motion struct {
+	Acceleration                 Vector3
+	AccelerationIncludingGravity Vector3
+	RotationRate                 RotationRate
+	// Interval is how often the device samples.
+	Interval time.Duration
+}
+
+// SensorOptions ...
+type SensorOptions struct {
+	// Interval is the minimum time between the readings delivered; 0
+	// delivers every event, usually 60 per second.
+	Interval time.Duration
+	// Absolute listens for deviceorientationabsolute where supported, so
+	// Alpha is relative to north.
+	Absolute bool
+}
+
+// RequestSensorPermission asks for motion and orientation access. Only iOS
+// Safari prompts, and only from a user gesture, e.g. a "start AR" button;
+// elsewhere it checks the Permissions API and returns nil unless the sensors
+// were blocked. A refusal returns an error wrapping GOWEB_ERROR_SENSOR_DENIED.
+func RequestSensorPermission(_ctx context.Context) error {
+	asked := false
+	for _, name := range []string{deviceOrientationEvent__constructor, deviceMotionEvent__constructor} {
+		ctor := js.Global().Get(name)
+		if ctor.Type() != js.TypeFunction || ctor.Get(function__requestPermission).Type() != js.TypeFunction {
+			continue
+		}
+		asked = true
+		v, err := awaitCall(_ctx, ctor, function__requestPermission)
+		if err != nil {
+			// without a user gesture Safari rejects instead of prompting
+			return fmt.Errorf("[sensor] [RequestSensorPermission] [error]: %w: %v", GOWEB_ERROR_SENSOR_DENIED, err)
+		}
+		if PermissionState(v.String()) != PermissionGranted {
+			return fmt.Errorf("[sensor] [RequestSensorPermission] [error]: %w", GOWEB_ERROR_SENSOR_DENIED)
+		}
+	}
+	if asked {
+		return nil
+	}
+	for _, name := range []string{PERMISSION__accelerometer, PERMISSION__gyroscope} {
+		if s, err := QueryPermission(_ctx, name); err == nil && s == PermissionDenied {
+			return fmt.Errorf("[sensor] [RequestSensorPermission] [error]: %w", GOWEB_ERROR_SENSOR_DENIED)
+		}
+	}
+	return nil
+}
+
+// DeviceOrientation streams orientation readings until _ctx is done or the
+// returned func is called, then closes the channel. The channel keeps only
+// the latest reading. On iOS the permission has to be granted first (see
+// RequestSensorPermission).
+func (w *Window) DeviceOrientation(_ctx context.Context, _opts SensorOptions) (<-chan Orientation, func(), error) {
+	if js.Global().Get(deviceOrientationEvent__constructor).Type() != js.TypeFunction {
+		return nil, func() {}, fmt.Errorf("[window] [DeviceOrientation] [error]: %w", GOWEB_ERROR_UNSUPPORTED)
+	}
+	if err := RequestSensorPermission(_ctx); err != nil {
+		return nil, func() {}, fmt.Errorf("[window] [DeviceOrientation] [error]: %v", err)
+	}
+	event := EVENT__deviceorientation
+	if _opts.Absolute && js.Global().Get("on"+EVENT__deviceorientationabsolute).Type() != js.TypeUndefined {
+		event = EVENT__deviceorientationabsolute
+	}
+
+	ch := make(chan Orientation, 1)
+	th := throttle{interval: _opts.Interval}
+	stop := w.stream(_ctx, event, func() { close(ch) }, func(_e Event) {
+		if !th.ready() {
+			return
+		}
+		o := Orientation{
+			Alpha:    floatOrNaN(_e.Get(orientation__alpha)),
+			Beta:     floatOrNaN(_e.Get(orientation__beta)),
+			Gamma:    floatOrNaN(_e.Get(orientation__gamma)),
+			Absolute: _e.Get(orientation__absolute).Truthy(),
+			Heading:  floatOrNaN(_e.Get(orientation__compassHeading)),
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- o
+	})
+	return ch, stop, nil
+}
+
+// DeviceMotion streams motion readings like DeviceOrientation.
+func (w *Window) DeviceMotion(_ctx context.Context, _opts SensorOptions) (<-chan Motion, func(), error) {
+	if js.Global().Get(deviceMotionEvent__constructor).Type() != js.TypeFunction {
+		return nil, func() {}, fmt.Errorf("[window] [DeviceMotion] [error]: %w", GOWEB_ERROR_UNSUPPORTED)
+	}
+	if err := RequestSensorPermission(_ctx); err != nil {
+		return nil, func() {}, fmt.Errorf("[window] [DeviceMotion] [error]: %v", err)
+	}
+
+	ch := make(chan Motion, 1)
+	th := throttle{interval: _opts.Interval}
+	stop := w.stream(_ctx, EVENT__devicemotion, func() { close(ch) }, func(_e Event) {
+		if !th.ready() {
+			return
+		}
+		r := _e.Get(motion__rotationRate)
+		m := Motion{
+			Acceleration:                 vector3Of(_e.Get(motion__acceleration)),
+			AccelerationIncludingGravity: vector3Of(_e.Get(motion__accelerationIncludingGravity)),
+			Interval:                     msDuration(floatOrZero(_e.Get(motion__interval))),
+		}
+		if r.Type() == js.TypeObject {
+			m.RotationRate = RotationRate{
+				Alpha: floatOrNaN(r.Get(orientation__alpha)),
+				Beta:  floatOrNaN(r.Get(orientation__beta)),
+				Gamma: floatOrNaN(r.Get(orientation__gamma)),
+			}
+		} else {
+			m.RotationRate = RotationRate{math.NaN(), math.NaN(), math.NaN()}
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- m
+	})
+	return ch, stop, nil
+}
+
+// stream listens for _event on the window until _ctx is done or the returned
+// func is called, then removes the listener and calls _done.
+func (w *Window) stream(_ctx context.Context, _event string, _done func(), _cb func(Event)) func() {
+	off := w.On(_event, _cb, ListenerOptions{Passive: true})
+	var once sync.Once
+	stopped := make(chan struct{})
+	stop := func() {
+		once.Do(func() {
+			off()
+			_done()
+			close(stopped)
+		})
+	}
+	go func() {
+		select {
+		case <-_ctx.Done():
+			stop()
+		case <-stopped:
+		}
+	}()
+	return stop
+}
+
+func vector3Of(_v js.Value) Vector3 {
+	if _v.Type() != js.TypeObject {
+		return Vector3{math.NaN(), math.NaN(), math.NaN()}
+	}
+	return Vector3{
+		X: floatOrNaN(_v.Get("x")),
+		Y: floatOrNaN(_v.Get("y")),
+		Z: floatOrNaN(_v.Get("z")),
+	}
+}
+
+func floatOrZero(_v js.Value) float64 {
+	if _v.Type() != js.TypeNumber {
+		return 0
+	}
+	return _v.Float()
+}
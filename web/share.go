@@ -0,0 +1,115 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall/js"
+)
+
+const (
+	function__share    = "share"
+	function__canShare = "canShare"
+
+	share__title = "title"
+	share__text  = "text"
+	share__url   = "url"
+	share__files = "files"
+
+	error__abort = "AbortError"
+)
+
+var GOWEB_ERROR_SHARE_CANCELED = errors.New("share canceled")
+
+// ShareData is what Share hands to the OS share sheet.
+type ShareData struct {
+	Title string
+	Text  string
+	URL   string
+	Files []*File
+}
+
+func (d ShareData) jsValue() map[string]interface{} {
+	m := map[string]interface{}{}
+	if d.Title != "" {
+		m[share__title] = d.Title
+	}
+	if d.Text != "" {
+		m[share__text] = d.Text
+	}
+	if d.URL != "" {
+		m[share__url] = d.URL
+	}
+	if len(d.Files) > 0 {
+		f := make([]interface{}, len(d.Files))
+		for i, file := range d.Files {
+			f[i] = file.Value
+		}
+		m[share__files] = f
+	}
+	return m
+}
+
+// text is what the clipboard fallback copies.
+func (d ShareData) text() string {
+	parts := []string{}
+	for _, s := range []string{d.Text, d.URL} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		return d.Title
+	}
+	return strings.Join(parts, " ")
+}
+
+// ShareResult tells how Share delivered the data.
+type ShareResult int
+
+const (
+	// SharedNative means the OS share sheet was used.
+	SharedNative ShareResult = iota + 1
+	// SharedClipboard means the Web Share API was missing (or could not
+	// share the files) and the text and URL were copied instead.
+	SharedClipboard
+)
+
+// CanShare reports whether the Web Share API accepts _d natively.
+func CanShare(_d ShareData) bool {
+	nav := navigatorValue()
+	if ValidJSValue(navigator, nav) != nil || nav.Get(function__share).Type() != js.TypeFunction {
+		return false
+	}
+	if nav.Get(function__canShare).Type() != js.TypeFunction {
+		// share without canShare predates file sharing
+		return len(_d.Files) == 0
+	}
+	ok, err := callJS(nav, function__canShare, _d.jsValue())
+	return err == nil && ok.Truthy()
+}
+
+// Share opens the OS share sheet for _d and falls back to copying the text and
+// URL to the clipboard. The native sheet needs a user gesture; a user
+// dismissing it returns an error wrapping GOWEB_ERROR_SHARE_CANCELED.
+func (w *Window) Share(_ctx context.Context, _d ShareData) (ShareResult, error) {
+	if CanShare(_d) {
+		_, err := awaitCall(_ctx, navigatorValue(), function__share, _d.jsValue())
+		if err == nil {
+			return SharedNative, nil
+		}
+		var perr *PromiseError
+		if errors.As(err, &perr) && perr.Name == error__abort {
+			return 0, fmt.Errorf("[window] [Share] [error]: %w", GOWEB_ERROR_SHARE_CANCELED)
+		}
+		return 0, fmt.Errorf("[window] [Share] [error]: %v", err)
+	}
+
+	if err := w.Clipboard().WriteText(_ctx, _d.text()); err != nil {
+		return 0, fmt.Errorf("[window] [Share] [error]: %v", err)
+	}
+	return SharedClipboard, nil
+}
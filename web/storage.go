@@ -0,0 +1,158 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+)
+
+const (
+	localStorage   = "localStorage"
+	sessionStorage = "sessionStorage"
+
+	function__getItem    = "getItem"
+	function__setItem    = "setItem"
+	function__removeItem = "removeItem"
+	function__clear      = "clear"
+	function__key        = "key"
+
+	property__length = "length"
+
+	EVENT__storage = "storage"
+
+	storageEvent__key         = "key"
+	storageEvent__oldValue    = "oldValue"
+	storageEvent__newValue    = "newValue"
+	storageEvent__url         = "url"
+	storageEvent__storageArea = "storageArea"
+)
+
+// Storage wraps localStorage or sessionStorage.
+type Storage struct {
+	value js.Value
+	name  string
+
+	win *Window
+}
+
+// StorageEvent is fired in other documents of the same origin when a storage
+// area changes. Cleared is set when the change was a clear(); OldValue and
+// NewValue are "" when the key did not / no longer exists.
+type StorageEvent struct {
+	Key      string
+	OldValue string
+	NewValue string
+	URL      string
+	Cleared  bool
+}
+
+// LocalStorage ...
+func (w *Window) LocalStorage() (*Storage, error) {
+	return w.storage(localStorage)
+}
+
+// SessionStorage ...
+func (w *Window) SessionStorage() (*Storage, error) {
+	return w.storage(sessionStorage)
+}
+
+func (w *Window) storage(_name string) (*Storage, error) {
+	tv, err := w.GetGlobal(_name)
+	if err != nil {
+		return nil, fmt.Errorf("[window] [%s] [error]: %v", _name, err)
+	}
+	return &Storage{value: tv, name: _name, win: w}, nil
+}
+
+// Get returns the stored string and whether the key exists.
+func (s *Storage) Get(_key string) (string, bool) {
+	tv := s.value.Call(function__getItem, _key)
+	if ValidJSValue(_key, tv) != nil {
+		return "", false
+	}
+	return tv.String(), true
+}
+
+// Set stores a string. It fails when the storage quota is exceeded.
+func (s *Storage) Set(_key, _val string) error {
+	if _, err := callJS(s.value, function__setItem, _key, _val); err != nil {
+		return fmt.Errorf("[%s] [Set] [%s] [error]: %v", s.name, _key, err)
+	}
+	return nil
+}
+
+// GetJSON decodes the JSON stored under _key into _v. It reports false when
+// the key does not exist.
+func (s *Storage) GetJSON(_key string, _v interface{}) (bool, error) {
+	str, ok := s.Get(_key)
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(str), _v); err != nil {
+		return true, fmt.Errorf("[%s] [GetJSON] [%s] [error]: %v", s.name, _key, err)
+	}
+	return true, nil
+}
+
+// SetJSON stores _v encoded as JSON.
+func (s *Storage) SetJSON(_key string, _v interface{}) error {
+	b, err := json.Marshal(_v)
+	if err != nil {
+		return fmt.Errorf("[%s] [SetJSON] [%s] [error]: %v", s.name, _key, err)
+	}
+	return s.Set(_key, string(b))
+}
+
+// Remove ...
+func (s *Storage) Remove(_key string) {
+	s.value.Call(function__removeItem, _key)
+}
+
+// Clear ...
+func (s *Storage) Clear() {
+	s.value.Call(function__clear)
+}
+
+// Len ...
+func (s *Storage) Len() int {
+	return s.value.Get(property__length).Int()
+}
+
+// Keys ...
+func (s *Storage) Keys() []string {
+	n := s.Len()
+	r := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if k := s.value.Call(function__key, i); ValidJSValue(function__key, k) == nil {
+			r = append(r, k.String())
+		}
+	}
+	return r
+}
+
+// OnChange calls _cb for `storage` events that concern this storage area.
+// Browsers only fire them for changes made by other documents.
+func (s *Storage) OnChange(_cb func(StorageEvent)) func() {
+	return s.win.On(EVENT__storage, func(_e Event) {
+		if area := _e.Value.Get(storageEvent__storageArea); ValidJSValue(storageEvent__storageArea, area) == nil && !area.Equal(s.value) {
+			return
+		}
+		se := StorageEvent{
+			URL: _e.Value.Get(storageEvent__url).String(),
+		}
+		if k := _e.Value.Get(storageEvent__key); ValidJSValue(storageEvent__key, k) == nil {
+			se.Key = k.String()
+		} else {
+			se.Cleared = true
+		}
+		if v := _e.Value.Get(storageEvent__oldValue); ValidJSValue(storageEvent__oldValue, v) == nil {
+			se.OldValue = v.String()
+		}
+		if v := _e.Value.Get(storageEvent__newValue); ValidJSValue(storageEvent__newValue, v) == nil {
+			se.NewValue = v.String()
+		}
+		_cb(se)
+	})
+}
@@ -0,0 +1,222 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+)
+
+const (
+	element__classList = "classList"
+	element__style     = "style"
+	document__head     = "head"
+	document__body     = "body"
+
+	function__toggle           = "toggle"
+	function__replace          = "replace"
+	function__setProperty      = "setProperty"
+	function__getPropertyValue = "getPropertyValue"
+	function__removeProperty   = "removeProperty"
+	function__getComputedStyle = "getComputedStyle"
+
+	style__tag       = "style"
+	style__important = "important"
+	css__varPrefix   = "--"
+)
+
+// ClassList edits an element's classes one at a time, leaving the others
+// alone (unlike SetClass, which replaces className).
+type ClassList struct {
+	elem  *Element
+	value js.Value
+}
+
+// ClassList ...
+func (elem *Element) ClassList() *ClassList {
+	cl := &ClassList{elem: elem, value: js.Undefined()}
+	if ValidJSValue(elem.String(), elem.Value) == nil {
+		cl.value = elem.Value.Get(element__classList)
+	}
+	return cl
+}
+
+// Add ...
+func (cl *ClassList) Add(_names ...string) error {
+	return cl.call("Add", function__add, _names...)
+}
+
+// Remove ...
+func (cl *ClassList) Remove(_names ...string) error {
+	return cl.call("Remove", function__remove, _names...)
+}
+
+// Toggle flips _name and reports whether it is now present. With _force the
+// class is added (true) or removed (false) instead.
+func (cl *ClassList) Toggle(_name string, _force ...bool) bool {
+	if ValidJSValue(element__classList, cl.value) != nil {
+		return false
+	}
+	if len(_force) > 0 {
+		return cl.value.Call(function__toggle, _name, _force[0]).Bool()
+	}
+	return cl.value.Call(function__toggle, _name).Bool()
+}
+
+// Contains ...
+func (cl *ClassList) Contains(_name string) bool {
+	if ValidJSValue(element__classList, cl.value) != nil {
+		return false
+	}
+	return cl.value.Call(function__contains, _name).Bool()
+}
+
+// Replace swaps _old for _new and reports whether _old was present.
+func (cl *ClassList) Replace(_old, _new string) bool {
+	if ValidJSValue(element__classList, cl.value) != nil {
+		return false
+	}
+	return cl.value.Call(function__replace, _old, _new).Bool()
+}
+
+// List returns the classes in order.
+func (cl *ClassList) List() []string {
+	if ValidJSValue(element__classList, cl.value) != nil {
+		return []string{}
+	}
+	return strings.Fields(cl.value.Get(PROPERTY__value).String())
+}
+
+func (cl *ClassList) call(_op, _method string, _names ...string) error {
+	if err := ValidJSValue(element__classList, cl.value); err != nil {
+		return fmt.Errorf("%s [ClassList] [%s] [error]: %v", cl.elem, _op, err)
+	}
+	args := make([]interface{}, len(_names))
+	for i, n := range _names {
+		args[i] = n
+	}
+	// classList throws on empty or whitespace names
+	if _, err := callJS(cl.value, _method, args...); err != nil {
+		return fmt.Errorf("%s [ClassList] [%s] [error]: %v", cl.elem, _op, err)
+	}
+	return nil
+}
+
+// SetStyle sets one inline style property. _prop is the CSS name
+// ("background-color", "--accent"); an empty _value removes it. Pass
+// important to add !important.
+func (elem *Element) SetStyle(_prop, _value string, _important ...bool) error {
+	style, err := elem.style("SetStyle")
+	if err != nil {
+		return err
+	}
+	if _value == "" {
+		style.Call(function__removeProperty, _prop)
+		return nil
+	}
+	priority := ""
+	if len(_important) > 0 && _important[0] {
+		priority = style__important
+	}
+	style.Call(function__setProperty, _prop, _value, priority)
+	return nil
+}
+
+// SetStyles sets several inline style properties.
+func (elem *Element) SetStyles(_props map[string]string) error {
+	for p, v := range _props {
+		if err := elem.SetStyle(p, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Style returns an inline style property, or "" when it is not set inline.
+func (elem *Element) Style(_prop string) string {
+	style, err := elem.style("Style")
+	if err != nil {
+		return ""
+	}
+	return style.Call(function__getPropertyValue, _prop).String()
+}
+
+// RemoveStyle ...
+func (elem *Element) RemoveStyle(_prop string) error {
+	return elem.SetStyle(_prop, "")
+}
+
+// GetComputedStyle returns the resolved value of _prop after stylesheets and
+// inheritance are applied.
+func (elem *Element) GetComputedStyle(_prop string) (string, error) {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return "", fmt.Errorf("%s [GetComputedStyle] [error]: %v", elem, err)
+	}
+	cs, err := callJS(js.Global(), function__getComputedStyle, elem.Value)
+	if err != nil {
+		return "", fmt.Errorf("%s [GetComputedStyle] [error]: %v", elem, err)
+	}
+	return strings.TrimSpace(cs.Call(function__getPropertyValue, _prop).String()), nil
+}
+
+// SetCSSVar sets the custom property --_name on the element; descendants and
+// stylesheets read it with var(--_name).
+func (elem *Element) SetCSSVar(_name, _value string) error {
+	return elem.SetStyle(cssVarName(_name), _value)
+}
+
+// CSSVar returns the custom property --_name as inherited by the element.
+func (elem *Element) CSSVar(_name string) (string, error) {
+	return elem.GetComputedStyle(cssVarName(_name))
+}
+
+func (elem *Element) style(_op string) (js.Value, error) {
+	if err := ValidJSValue(elem.String(), elem.Value); err != nil {
+		return js.Undefined(), fmt.Errorf("%s [%s] [error]: %v", elem, _op, err)
+	}
+	style := elem.Value.Get(element__style)
+	if err := ValidJSValue(element__style, style); err != nil {
+		return js.Undefined(), fmt.Errorf("%s [%s] [error]: %v", elem, _op, err)
+	}
+	return style, nil
+}
+
+func cssVarName(_name string) string {
+	if strings.HasPrefix(_name, css__varPrefix) {
+		return _name
+	}
+	return css__varPrefix + _name
+}
+
+// InjectStyle adds a <style> sheet with _css to the document head. _id makes
+// it idempotent: injecting the same id again replaces the sheet's text, so a
+// component can call it on every mount. Remove the returned element to drop
+// the sheet.
+func (w *Window) InjectStyle(_id, _css string) (*Element, error) {
+	if err := ValidJSValue(document, w.document); err != nil {
+		return nil, fmt.Errorf("[window] [InjectStyle] [error]: %v", err)
+	}
+	if _id != "" {
+		if v := w.document.Call(function__getElementById, _id); ValidJSValue(_id, v) == nil {
+			v.Set(node__textContent, _css)
+			return NewElement(v), nil
+		}
+	}
+
+	parent := w.document.Get(document__head)
+	if ValidJSValue(document__head, parent) != nil {
+		parent = w.document.Get(document__body)
+	}
+	if err := ValidJSValue(document__body, parent); err != nil {
+		return nil, fmt.Errorf("[window] [InjectStyle] [error]: %v", err)
+	}
+
+	el := w.NewElementWithTag(style__tag)
+	if _id != "" {
+		el.SetID(_id)
+	}
+	el.Value.Set(node__textContent, _css)
+	parent.Call(FUNCTION__appendChild, el.Value)
+	return el, nil
+}
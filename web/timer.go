@@ -0,0 +1,182 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	function__setTimeout          = "setTimeout"
+	function__clearTimeout        = "clearTimeout"
+	function__setInterval         = "setInterval"
+	function__clearInterval       = "clearInterval"
+	function__requestIdleCallback = "requestIdleCallback"
+	function__cancelIdleCallback  = "cancelIdleCallback"
+	function__timeRemaining       = "timeRemaining"
+	function__now                 = "now"
+
+	idle__didTimeout = "didTimeout"
+	idle__timeout    = "timeout"
+
+	performance = "performance"
+)
+
+// SetTimeout calls _fn once after _d on the JS event loop. The returned func
+// cancels it; the js.Func is released either way.
+func SetTimeout(_d time.Duration, _fn func()) func() {
+	var once sync.Once
+	var fn js.Func
+	var id js.Value
+	release := func() {
+		once.Do(fn.Release)
+	}
+	fn = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		release()
+		_fn()
+		return nil
+	})
+	id = js.Global().Call(function__setTimeout, fn, durationMs(_d))
+	return func() {
+		js.Global().Call(function__clearTimeout, id)
+		release()
+	}
+}
+
+// SetInterval calls _fn every _d until the returned func is called.
+func SetInterval(_d time.Duration, _fn func()) func() {
+	var once sync.Once
+	fn := js.FuncOf(func(js.Value, []js.Value) interface{} {
+		_fn()
+		return nil
+	})
+	id := js.Global().Call(function__setInterval, fn, durationMs(_d))
+	return func() {
+		once.Do(func() {
+			js.Global().Call(function__clearInterval, id)
+			fn.Release()
+		})
+	}
+}
+
+// IdleDeadline is passed to RequestIdleCallback funcs.
+type IdleDeadline struct {
+	js.Value
+}
+
+// TimeRemaining is how long the browser expects to stay idle.
+func (d IdleDeadline) TimeRemaining() time.Duration {
+	if ValidJSValue(function__timeRemaining, d.Value) != nil {
+		return 0
+	}
+	return msDuration(d.Value.Call(function__timeRemaining).Float())
+}
+
+// DidTimeout reports whether the callback ran because _timeout expired rather
+// than because the browser went idle.
+func (d IdleDeadline) DidTimeout() bool {
+	if ValidJSValue(idle__didTimeout, d.Value) != nil {
+		return false
+	}
+	return d.Value.Get(idle__didTimeout).Bool()
+}
+
+// RequestIdleCallback calls _fn when the browser is idle, or after _timeout
+// at the latest when given. Where requestIdleCallback is missing (Safari,
+// workers in some browsers) it falls back to a zero-delay timeout.
+func RequestIdleCallback(_fn func(IdleDeadline), _timeout ...time.Duration) func() {
+	g := js.Global()
+	if g.Get(function__requestIdleCallback).Type() != js.TypeFunction {
+		return SetTimeout(0, func() { _fn(IdleDeadline{Value: js.Undefined()}) })
+	}
+
+	var once sync.Once
+	var fn js.Func
+	release := func() {
+		once.Do(fn.Release)
+	}
+	fn = js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		release()
+		_fn(IdleDeadline{Value: firstArg(_args)})
+		return nil
+	})
+	opts := map[string]interface{}{}
+	if len(_timeout) > 0 && _timeout[0] > 0 {
+		opts[idle__timeout] = durationMs(_timeout[0])
+	}
+	id := g.Call(function__requestIdleCallback, fn, opts)
+	return func() {
+		g.Call(function__cancelIdleCallback, id)
+		release()
+	}
+}
+
+// Ticker is time.Ticker driven by setInterval, so ticks follow the browser's
+// timer throttling (e.g. in background tabs). Like time.Ticker it drops ticks
+// for a slow reader.
+type Ticker struct {
+	C <-chan time.Time
+
+	c    chan time.Time
+	mu   sync.Mutex
+	stop func()
+}
+
+// NewTicker ...
+func NewTicker(_d time.Duration) *Ticker {
+	c := make(chan time.Time, 1)
+	t := &Ticker{C: c, c: c}
+	t.Reset(_d)
+	return t
+}
+
+// Reset stops the ticker and restarts it with period _d.
+func (t *Ticker) Reset(_d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		t.stop()
+	}
+	t.stop = SetInterval(_d, func() {
+		select {
+		case t.c <- time.Now():
+		default:
+		}
+	})
+}
+
+// Stop ...
+func (t *Ticker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		t.stop()
+		t.stop = nil
+	}
+}
+
+// After is time.After driven by setTimeout.
+func After(_d time.Duration) <-chan time.Time {
+	c := make(chan time.Time, 1)
+	SetTimeout(_d, func() { c <- time.Now() })
+	return c
+}
+
+// Now returns performance.now() as a duration since the page's time origin, or
+// the wall clock since the epoch where performance is missing.
+func Now() time.Duration {
+	if p := js.Global().Get(performance); p.Type() == js.TypeObject {
+		return msDuration(p.Call(function__now).Float())
+	}
+	return time.Duration(time.Now().UnixNano())
+}
+
+func durationMs(_d time.Duration) float64 {
+	return float64(_d) / float64(time.Millisecond)
+}
+
+func msDuration(_ms float64) time.Duration {
+	return time.Duration(_ms * float64(time.Millisecond))
+}
@@ -0,0 +1,285 @@
+//+build tinygo wasm,js
+
+package vdom
+
+import (
+	"fmt"
+	"reflect"
+	"syscall/js"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+const (
+	property__nodeValue = "nodeValue"
+	attribute__id       = "id"
+)
+
+// binding keeps one DOM listener per event name for the lifetime of a DOM
+// node; the handlers it dispatches to are swapped on every patch.
+type binding struct {
+	handlers On
+	off      map[string]func()
+}
+
+// same reports whether _b can be patched onto the DOM node rendered for _a.
+func same(_a, _b *Node) bool {
+	return _a.Tag == _b.Tag && _a.Key == _b.Key
+}
+
+// create builds the DOM for _n and its children, detached from the document.
+func (r *Root) create(_n *Node) error {
+	if _n.Tag == "" {
+		_n.elem = r.win.NewTextNode(_n.Text)
+		if err := web.ValidJSValue(_n.String(), _n.elem.Value); err != nil {
+			return fmt.Errorf("[vdom] [create] %s [error]: %v", _n, err)
+		}
+		return nil
+	}
+
+	_n.elem = r.win.NewElementWithTag(_n.Tag)
+	if err := web.ValidJSValue(_n.String(), _n.elem.Value); err != nil {
+		return fmt.Errorf("[vdom] [create] %s [error]: %v", _n, err)
+	}
+	if err := setAttrs(_n.elem, nil, _n.Attrs); err != nil {
+		return err
+	}
+	if err := setProps(_n.elem, nil, _n.Props); err != nil {
+		return err
+	}
+	bindEvents(_n)
+
+	for _, c := range _n.Children {
+		if err := r.create(c); err != nil {
+			return err
+		}
+		if err := _n.elem.InsertBefore(c.elem, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patch moves the DOM node of _old onto _n and applies the differences.
+func (r *Root) patch(_old, _n *Node) error {
+	_n.elem, _n.bind = _old.elem, _old.bind
+
+	if _n.Tag == "" {
+		if _old.Text != _n.Text {
+			return _n.elem.SetProperty(_n.Text, property__nodeValue)
+		}
+		return nil
+	}
+
+	if err := setAttrs(_n.elem, _old.Attrs, _n.Attrs); err != nil {
+		return err
+	}
+	if err := setProps(_n.elem, _old.Props, _n.Props); err != nil {
+		return err
+	}
+	bindEvents(_n)
+	return r.patchChildren(_n.elem, _old.Children, _n.Children)
+}
+
+// patchChildren matches _next against _old (by key, then by position among
+// unkeyed children with the same tag), patches the matches, creates the rest,
+// removes what is left over and finally moves DOM nodes into render order.
+func (r *Root) patchChildren(_parent *web.Element, _old, _next []*Node) error {
+	keyed := map[string]int{}
+	for i, o := range _old {
+		if o.Key != "" {
+			keyed[o.Key] = i
+		}
+	}
+	used := make([]bool, len(_old))
+	j := 0
+
+	for _, n := range _next {
+		match := -1
+		if n.Key != "" {
+			if i, ok := keyed[n.Key]; ok && !used[i] && same(_old[i], n) {
+				match = i
+			}
+		} else {
+			for j < len(_old) && (_old[j].Key != "" || used[j]) {
+				j++
+			}
+			if j < len(_old) && same(_old[j], n) {
+				match = j
+				j++
+			}
+		}
+
+		if match < 0 {
+			if err := r.create(n); err != nil {
+				return err
+			}
+			continue
+		}
+		used[match] = true
+		if err := r.patch(_old[match], n); err != nil {
+			return err
+		}
+	}
+
+	cur := make([]*web.Element, 0, len(_old))
+	for i, o := range _old {
+		if !used[i] {
+			if err := r.destroy(_parent, o); err != nil {
+				return err
+			}
+			continue
+		}
+		cur = append(cur, o.elem)
+	}
+
+	for i, n := range _next {
+		if i < len(cur) && cur[i].Value.Equal(n.elem.Value) {
+			continue
+		}
+		var ref *web.Element
+		if i < len(cur) {
+			ref = cur[i]
+		}
+		if err := _parent.InsertBefore(n.elem, ref); err != nil {
+			return err
+		}
+		for k := i + 1; k < len(cur); k++ {
+			if cur[k].Value.Equal(n.elem.Value) {
+				cur = append(cur[:k], cur[k+1:]...)
+				break
+			}
+		}
+		cur = append(cur, nil)
+		copy(cur[i+1:], cur[i:])
+		cur[i] = n.elem
+	}
+	return nil
+}
+
+// destroy removes the listeners under _n and detaches it from _parent.
+func (r *Root) destroy(_parent *web.Element, _n *Node) error {
+	unbind(_n)
+	return _parent.RemoveChild(_n.elem)
+}
+
+func setAttrs(_elem *web.Element, _old, _next Attrs) error {
+	for k, v := range _old {
+		if nv, ok := _next[k]; (!ok || nv == nil || nv == false) && v != nil && v != false {
+			if err := _elem.RemoveAttribute(k); err != nil {
+				return err
+			}
+		}
+	}
+	for k, v := range _next {
+		if ov, ok := _old[k]; ok && reflect.DeepEqual(ov, v) {
+			continue
+		}
+		switch val := v.(type) {
+		case nil:
+			continue
+		case bool:
+			if !val {
+				continue
+			}
+			v = ""
+		}
+		if k == attribute__id {
+			if err := _elem.SetID(fmt.Sprint(v)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := _elem.SetAttribute(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setProps(_elem *web.Element, _old, _next Props) error {
+	for k, v := range _old {
+		if _, ok := _next[k]; ok {
+			continue
+		}
+		if err := setProp(_elem, k, web.Marshal(clearedProp(v))); err != nil {
+			return err
+		}
+	}
+	for k, v := range _next {
+		jv := web.Marshal(v)
+		if _elem.Value.Get(k).Equal(jv) {
+			continue
+		}
+		if err := setProp(_elem, k, jv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setProp assigns one property, going through SetID for "id" so the
+// element's ID field follows it.
+func setProp(_elem *web.Element, _k string, _v js.Value) error {
+	if _k == attribute__id {
+		id := ""
+		if _v.Type() == js.TypeString {
+			id = _v.String()
+		}
+		return _elem.SetID(id)
+	}
+	return _elem.SetProperty(_v, _k)
+}
+
+// clearedProp is what a property dropped from Props is reset to: false for
+// flags such as checked or disabled, "" for values and null for the rest
+// (srcObject, ...).
+func clearedProp(_v interface{}) interface{} {
+	switch _v.(type) {
+	case bool:
+		return false
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return ""
+	}
+	return nil
+}
+
+func bindEvents(_n *Node) {
+	if _n.bind == nil {
+		if len(_n.Events) == 0 {
+			return
+		}
+		_n.bind = &binding{off: map[string]func(){}}
+	}
+	b := _n.bind
+	for name, off := range b.off {
+		if _, ok := _n.Events[name]; !ok {
+			off()
+			delete(b.off, name)
+		}
+	}
+	for name := range _n.Events {
+		if _, ok := b.off[name]; ok {
+			continue
+		}
+		name := name
+		b.off[name] = _n.elem.On(name, func(_e web.Event) {
+			if h := b.handlers[name]; h != nil {
+				h(_e)
+			}
+		})
+	}
+	b.handlers = _n.Events
+}
+
+func unbind(_n *Node) {
+	if _n.bind != nil {
+		for name, off := range _n.bind.off {
+			off()
+			delete(_n.bind.off, name)
+		}
+	}
+	for _, c := range _n.Children {
+		unbind(c)
+	}
+}
@@ -0,0 +1,262 @@
+//+build tinygo wasm,js
+
+// Package vdom is a small declarative layer over web.Element. Components
+// render a tree of Nodes from their state; Root diffs each render against the
+// previous one and patches only the DOM nodes that changed.
+//
+//	type counter struct{ n int; root *vdom.Root }
+//
+//	func (c *counter) Render() *vdom.Node {
+//		return vdom.H("button",
+//			vdom.On{web.EVENT__click: func(web.Event) { c.n++; c.root.Update() }},
+//			fmt.Sprintf("clicked %d times", c.n),
+//		)
+//	}
+//
+//	c := &counter{}
+//	c.root, _ = vdom.Mount(win, win.ElementById("app"), c)
+//
+// Children with a Key keep their DOM node (and its state: focus, scroll, A-Frame
+// components) when the list is reordered; unkeyed children are matched by
+// position and tag.
+package vdom
+
+import (
+	"fmt"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+// Attrs are set with setAttribute. true sets an empty attribute, false or nil
+// removes it; maps are passed to web.Element.SetAttribute as A-Frame
+// component values.
+type Attrs map[string]interface{}
+
+// Props are assigned as DOM properties (value, checked, ...). They are
+// compared against the live DOM so user input is overwritten only when the
+// rendered value changes. A prop dropped between renders is reset to false,
+// "" or null.
+type Props map[string]interface{}
+
+// On maps event names to handlers. Handlers can change between renders
+// without re-adding DOM listeners.
+type On map[string]func(web.Event)
+
+// Key identifies a child among its siblings across renders.
+type Key string
+
+// Node is one element or text node of a rendered tree. Text nodes have an
+// empty Tag.
+type Node struct {
+	Tag      string
+	Key      string
+	Text     string
+	Attrs    Attrs
+	Props    Props
+	Events   On
+	Children []*Node
+
+	elem *web.Element
+	bind *binding
+}
+
+// H builds an element node. Each argument may be an Attrs, Props, On or Key
+// (merged into the node), a *Node or []*Node (appended as children), a
+// string (appended as a text node) or nil (skipped), which makes optional
+// children easy to express inline.
+func H(_tag string, _args ...interface{}) *Node {
+	n := &Node{Tag: _tag}
+	for _, a := range _args {
+		switch v := a.(type) {
+		case nil:
+		case Attrs:
+			if n.Attrs == nil {
+				n.Attrs = Attrs{}
+			}
+			for k, val := range v {
+				n.Attrs[k] = val
+			}
+		case Props:
+			if n.Props == nil {
+				n.Props = Props{}
+			}
+			for k, val := range v {
+				n.Props[k] = val
+			}
+		case On:
+			if n.Events == nil {
+				n.Events = On{}
+			}
+			for k, val := range v {
+				n.Events[k] = val
+			}
+		case Key:
+			n.Key = string(v)
+		case *Node:
+			if v != nil {
+				n.Children = append(n.Children, v)
+			}
+		case []*Node:
+			for _, c := range v {
+				if c != nil {
+					n.Children = append(n.Children, c)
+				}
+			}
+		case string:
+			n.Children = append(n.Children, Text(v))
+		case fmt.Stringer:
+			n.Children = append(n.Children, Text(v.String()))
+		default:
+			n.Children = append(n.Children, Text(fmt.Sprint(v)))
+		}
+	}
+	return n
+}
+
+// Text builds a text node.
+func Text(_s string) *Node {
+	return &Node{Text: _s}
+}
+
+// Textf builds a text node from a format string.
+func Textf(_format string, _args ...interface{}) *Node {
+	return Text(fmt.Sprintf(_format, _args...))
+}
+
+// Map renders one node per item, for list children.
+func Map(_n int, _fn func(int) *Node) []*Node {
+	r := make([]*Node, 0, _n)
+	for i := 0; i < _n; i++ {
+		r = append(r, _fn(i))
+	}
+	return r
+}
+
+// Element returns the DOM element backing a mounted node, or nil before it is
+// mounted.
+func (n *Node) Element() *web.Element {
+	return n.elem
+}
+
+// String ...
+func (n *Node) String() string {
+	if n.Tag == "" {
+		return fmt.Sprintf("text[%q]", n.Text)
+	}
+	if n.Key != "" {
+		return fmt.Sprintf("[%s]node[%s]", n.Tag, n.Key)
+	}
+	return fmt.Sprintf("[%s]node", n.Tag)
+}
+
+// Component renders a tree from its current state.
+type Component interface {
+	Render() *Node
+}
+
+// RenderFunc adapts a plain func to Component.
+type RenderFunc func() *Node
+
+// Render ...
+func (f RenderFunc) Render() *Node {
+	return f()
+}
+
+// Root owns the DOM rendered by one component under a parent element.
+type Root struct {
+	win    *web.Window
+	parent *web.Element
+	comp   Component
+	tree   *Node
+
+	patching bool
+	dirty    bool
+}
+
+// Mount renders _comp and appends the result to _parent.
+func Mount(_win *web.Window, _parent *web.Element, _comp Component) (*Root, error) {
+	if _parent == nil {
+		return nil, fmt.Errorf("[vdom] [Mount] [error]: nil parent")
+	}
+	r := &Root{win: _win, parent: _parent, comp: _comp}
+	tree := r.render()
+	if err := r.create(tree); err != nil {
+		return nil, fmt.Errorf("[vdom] [Mount] [error]: %v", err)
+	}
+	if err := _parent.InsertBefore(tree.elem, nil); err != nil {
+		return nil, fmt.Errorf("[vdom] [Mount] [error]: %v", err)
+	}
+	r.tree = tree
+	return r, nil
+}
+
+// Update re-renders the component and patches the DOM with the differences.
+// Calling Update from an event handler while a patch is running schedules one
+// more pass instead of patching re-entrantly.
+func (r *Root) Update() error {
+	if r.tree == nil {
+		return fmt.Errorf("[vdom] [Update] [error]: root is not mounted")
+	}
+	if r.patching {
+		r.dirty = true
+		return nil
+	}
+	r.patching = true
+	defer func() { r.patching = false }()
+
+	for {
+		r.dirty = false
+		if err := r.update(); err != nil {
+			return fmt.Errorf("[vdom] [Update] [error]: %v", err)
+		}
+		if !r.dirty {
+			return nil
+		}
+	}
+}
+
+func (r *Root) update() error {
+	next := r.render()
+	if same(r.tree, next) {
+		if err := r.patch(r.tree, next); err != nil {
+			return err
+		}
+		r.tree = next
+		return nil
+	}
+	if err := r.create(next); err != nil {
+		return err
+	}
+	if err := r.parent.InsertBefore(next.elem, r.tree.elem); err != nil {
+		return err
+	}
+	old := r.tree
+	r.tree = next
+	return r.destroy(r.parent, old)
+}
+
+// Unmount removes the rendered DOM and its event listeners.
+func (r *Root) Unmount() error {
+	if r.tree == nil {
+		return nil
+	}
+	old := r.tree
+	r.tree = nil
+	if err := r.destroy(r.parent, old); err != nil {
+		return fmt.Errorf("[vdom] [Unmount] [error]: %v", err)
+	}
+	return nil
+}
+
+// Tree returns the last rendered tree.
+func (r *Root) Tree() *Node {
+	return r.tree
+}
+
+func (r *Root) render() *Node {
+	n := r.comp.Render()
+	if n == nil {
+		n = Text("")
+	}
+	return n
+}
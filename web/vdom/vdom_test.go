@@ -0,0 +1,230 @@
+//+build tinygo wasm,js
+
+package vdom_test
+
+import (
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/vdom"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+// mount renders _fn under a fresh #app element.
+func mount(t *testing.T, _h *webtest.Harness, _fn vdom.RenderFunc) (*vdom.Root, js.Value) {
+	t.Helper()
+	app := _h.AddElement("div", "app")
+	win := web.NewWindow()
+	r, err := vdom.Mount(win, win.ElementById("app"), _fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, app
+}
+
+func update(t *testing.T, _r *vdom.Root) {
+	t.Helper()
+	if err := _r.Update(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// texts lists the text of each child element of _el.
+func texts(_el js.Value) string {
+	kids := _el.Get("children")
+	r := make([]string, kids.Length())
+	for i := range r {
+		r[i] = kids.Index(i).Get("children").Index(0).Get("textContent").String()
+	}
+	return strings.Join(r, ",")
+}
+
+func TestKeyedChildren(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	items := []string{"a", "b", "c", "d"}
+	r, _ := mount(t, h, func() *vdom.Node {
+		return vdom.H("ul", vdom.Map(len(items), func(i int) *vdom.Node {
+			return vdom.H("li", vdom.Key(items[i]), items[i])
+		}))
+	})
+	ul := r.Tree().Element().Value
+	nodes := map[string]js.Value{}
+	for _, c := range r.Tree().Children {
+		nodes[c.Key] = c.Element().Value
+	}
+
+	for _, c := range []struct {
+		name  string
+		items []string
+	}{
+		{"reorder", []string{"d", "b", "a", "c"}},
+		{"insert", []string{"d", "x", "b", "a", "c", "y"}},
+		{"delete", []string{"x", "a", "y"}},
+		{"reverse", []string{"y", "a", "x"}},
+	} {
+		before := len(h.CallsTo(webtest.TargetDocument, "createElement"))
+		items = c.items
+		update(t, r)
+
+		if got := texts(ul); got != strings.Join(c.items, ",") {
+			t.Errorf("%s: DOM order = %s, want %v", c.name, got, c.items)
+		}
+		created := 0
+		for i, k := range c.items {
+			el := ul.Get("children").Index(i)
+			if old, ok := nodes[k]; ok {
+				if !old.Equal(el) {
+					t.Errorf("%s: keyed child %s was recreated", c.name, k)
+				}
+				continue
+			}
+			nodes[k] = el
+			created++
+		}
+		if n := len(h.CallsTo(webtest.TargetDocument, "createElement")) - before; n != created {
+			t.Errorf("%s: created %d elements, want %d", c.name, n, created)
+		}
+	}
+
+	for _, k := range []string{"b", "c", "d"} {
+		if !nodes[k].Get("parentNode").IsNull() {
+			t.Errorf("deleted child %s is still attached", k)
+		}
+	}
+}
+
+func TestUnkeyedChildren(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	tags := []string{"p", "p", "span"}
+	r, app := mount(t, h, func() *vdom.Node {
+		return vdom.H("div", vdom.Map(len(tags), func(i int) *vdom.Node {
+			return vdom.H(tags[i], tags[i])
+		}))
+	})
+	div := app.Get("children").Index(0)
+	first := div.Get("children").Index(0)
+
+	tags = []string{"p", "span", "span"}
+	update(t, r)
+	kids := div.Get("children")
+	if kids.Length() != 3 || !kids.Index(0).Equal(first) {
+		t.Errorf("the first <p> was not kept in place")
+	}
+	for i, tag := range tags {
+		if got := kids.Index(i).Get("tagName").String(); got != strings.ToUpper(tag) {
+			t.Errorf("child %d is %s, want %s", i, got, tag)
+		}
+	}
+}
+
+func TestPropsAndAttrsReset(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	full := true
+	r, _ := mount(t, h, func() *vdom.Node {
+		if !full {
+			return vdom.H("input")
+		}
+		return vdom.H("input",
+			vdom.Attrs{"id": "name", "title": "Name", "required": true},
+			vdom.Props{"value": "Ada", "checked": true, "tabIndex": 3},
+		)
+	})
+	n := r.Tree()
+	el := n.Element()
+	if el.ID != "name" || el.Value.Get("value").String() != "Ada" || !el.Value.Get("checked").Bool() {
+		t.Fatalf("initial render: id %q, value %v, checked %v", el.ID, el.Value.Get("value"), el.Value.Get("checked"))
+	}
+
+	full = false
+	update(t, r)
+	if r.Tree().Element() != el {
+		t.Fatal("the input was recreated")
+	}
+	if el.ID != "" || el.Value.Get("id").String() != "" {
+		t.Errorf("ID = %q, id = %q after the id attr was dropped", el.ID, el.Value.Get("id"))
+	}
+	for _, a := range []string{"title", "required"} {
+		if !el.Value.Call("getAttribute", a).IsNull() {
+			t.Errorf("attribute %s still set", a)
+		}
+	}
+	if v := el.Value.Get("value"); v.String() != "" {
+		t.Errorf("value = %v, want \"\"", v)
+	}
+	if el.Value.Get("checked").Bool() {
+		t.Error("checked still true")
+	}
+}
+
+func TestIdProp(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	id := "main"
+	r, _ := mount(t, h, func() *vdom.Node {
+		if id == "" {
+			return vdom.H("section")
+		}
+		return vdom.H("section", vdom.Props{"id": id})
+	})
+	el := r.Tree().Element()
+	if el.ID != "main" {
+		t.Errorf("ID = %q, want main", el.ID)
+	}
+	id = "other"
+	update(t, r)
+	if el.ID != "other" || el.Value.Get("id").String() != "other" {
+		t.Errorf("ID = %q after the id prop changed", el.ID)
+	}
+	id = ""
+	update(t, r)
+	if el.ID != "" || el.Value.Get("id").String() != "" {
+		t.Errorf("ID = %q after the id prop was removed", el.ID)
+	}
+}
+
+func TestEventsAndUnmount(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	var got []string
+	label := "first"
+	r, app := mount(t, h, func() *vdom.Node {
+		l := label
+		return vdom.H("button", vdom.On{web.EVENT__click: func(web.Event) { got = append(got, l) }})
+	})
+	btn := r.Tree().Element().Value
+	click := func() {
+		btn.Call("emit", web.EVENT__click, js.Null(), true)
+	}
+
+	click()
+	label = "second"
+	update(t, r)
+	click()
+	if strings.Join(got, ",") != "first,second" {
+		t.Errorf("handlers ran %v, want [first second]", got)
+	}
+	if n := len(h.CallsTo(webtest.TargetElement, "addEventListener")); n != 1 {
+		t.Errorf("addEventListener called %d times, want 1", n)
+	}
+
+	if err := r.Unmount(); err != nil {
+		t.Fatal(err)
+	}
+	click()
+	if len(got) != 2 {
+		t.Errorf("handler ran after Unmount")
+	}
+	if app.Get("children").Length() != 0 {
+		t.Errorf("Unmount left %d children", app.Get("children").Length())
+	}
+}
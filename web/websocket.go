@@ -0,0 +1,604 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+const (
+	websocket__constructor = "WebSocket"
+
+	websocket__binaryType     = "binaryType"
+	websocket__bufferedAmount = "bufferedAmount"
+	websocket__protocol       = "protocol"
+	websocket__arraybuffer    = "arraybuffer"
+	websocket__readyState     = "readyState"
+	websocket__connecting     = 0
+	websocket__closed         = 3
+
+	function__send = "send"
+
+	EVENT__open  = "open"
+	EVENT__close = "close"
+
+	close__code     = "code"
+	close__reason   = "reason"
+	close__wasClean = "wasClean"
+
+	// Close codes from RFC 6455.
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseAbnormal        = 1006
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseTooBig          = 1009
+	CloseInternalError   = 1011
+
+	websocket__highWaterMark = 1 << 20
+	websocket__drainPoll     = 10 * time.Millisecond
+)
+
+// MessageType is the kind of a WebSocket data frame.
+type MessageType int
+
+const (
+	TextMessage MessageType = iota + 1
+	BinaryMessage
+)
+
+// String ...
+func (t MessageType) String() string {
+	switch t {
+	case TextMessage:
+		return "text"
+	case BinaryMessage:
+		return "binary"
+	}
+	return fmt.Sprintf("MessageType(%d)", int(t))
+}
+
+// WebSocketState ...
+type WebSocketState int
+
+const (
+	WebSocketConnecting WebSocketState = iota
+	WebSocketOpen
+	WebSocketReconnecting
+	WebSocketClosed
+)
+
+var GOWEB_ERROR_WEBSOCKET_RECONNECT = errors.New("websocket gave up reconnecting")
+
+// CloseError is returned once the connection is closed for good.
+type CloseError struct {
+	Code   int
+	Reason string
+	Clean  bool
+}
+
+// Error ...
+func (e *CloseError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("[websocket] [close] [%d]: %s", e.Code, e.Reason)
+	}
+	return fmt.Sprintf("[websocket] [close] [%d]", e.Code)
+}
+
+// WebSocketOptions tune DialWebSocket. The zero value never reconnects.
+type WebSocketOptions struct {
+	// Reconnect re-dials after the connection drops with anything but a
+	// normal closure. Messages received before and after a reconnect arrive
+	// in order on the same WebSocket; writes wait until it is open again.
+	Reconnect bool
+	// MinBackoff and MaxBackoff bound the doubling delay between attempts
+	// (default 250ms and 30s).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxRetries is the number of consecutive failed attempts before giving
+	// up; 0 retries forever.
+	MaxRetries int
+	// HighWaterMark is the bufferedAmount above which writes wait for the
+	// socket to drain (default 1 MiB).
+	HighWaterMark int
+	// OnReconnect is called after every successful reconnect, e.g. to
+	// resubscribe.
+	OnReconnect func()
+}
+
+type wsFrame struct {
+	typ  MessageType
+	data []byte
+}
+
+// WebSocket is a client connection. ReadMessage and WriteMessage exchange
+// whole frames; it also implements net.Conn, where Read streams the payloads
+// of incoming frames and Write sends binary frames, so existing Go protocol
+// code can run over it:
+//
+//	ws, err := web.DialWebSocket(ctx, "wss://game.example/ws", nil, web.WebSocketOptions{Reconnect: true})
+//	if err != nil {
+//		return err
+//	}
+//	defer ws.Close()
+//	enc, dec := gob.NewEncoder(ws), gob.NewDecoder(ws)
+//
+// Frames are queued as they arrive, so the JS event loop is never blocked by a
+// slow reader.
+type WebSocket struct {
+	URL string
+
+	protocols []string
+	opts      WebSocketOptions
+
+	mu      sync.Mutex
+	value   js.Value
+	offs    []func()
+	state   WebSocketState
+	changed chan struct{}
+	queue   []wsFrame
+	err     error
+	retries int
+
+	// rmu serializes Read, which keeps the rest of a frame in rbuf.
+	rmu  sync.Mutex
+	rbuf []byte
+
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+// DialWebSocket connects to _url and returns once the connection is open.
+// The first attempt is not retried: a failure is returned to the caller.
+func DialWebSocket(_ctx context.Context, _url string, _protocols []string, _opts ...WebSocketOptions) (*WebSocket, error) {
+	ws := &WebSocket{
+		URL:       _url,
+		protocols: _protocols,
+		changed:   make(chan struct{}),
+	}
+	if len(_opts) > 0 {
+		ws.opts = _opts[0]
+	}
+	if ws.opts.MinBackoff <= 0 {
+		ws.opts.MinBackoff = 250 * time.Millisecond
+	}
+	if ws.opts.MaxBackoff <= 0 {
+		ws.opts.MaxBackoff = 30 * time.Second
+	}
+	if ws.opts.HighWaterMark <= 0 {
+		ws.opts.HighWaterMark = websocket__highWaterMark
+	}
+
+	if err := ws.connect(); err != nil {
+		return nil, fmt.Errorf("[websocket] [DialWebSocket] [%s] [error]: %v", _url, err)
+	}
+	for {
+		ws.mu.Lock()
+		state, err, changed := ws.state, ws.err, ws.changed
+		ws.mu.Unlock()
+		switch {
+		case state == WebSocketOpen:
+			return ws, nil
+		case err != nil:
+			return nil, fmt.Errorf("[websocket] [DialWebSocket] [%s] [error]: %v", _url, err)
+		}
+		select {
+		case <-changed:
+		case <-_ctx.Done():
+			ws.shutdown(&CloseError{Code: CloseNormal}, CloseNormal, "")
+			return nil, fmt.Errorf("[websocket] [DialWebSocket] [%s] [error]: %v", _url, _ctx.Err())
+		}
+	}
+}
+
+// State ...
+func (ws *WebSocket) State() WebSocketState {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.state
+}
+
+// Protocol returns the subprotocol the server selected.
+func (ws *WebSocket) Protocol() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ValidJSValue(websocket__constructor, ws.value) != nil {
+		return ""
+	}
+	return ws.value.Get(websocket__protocol).String()
+}
+
+// BufferedAmount is the number of bytes queued by send but not yet written to
+// the network.
+func (ws *WebSocket) BufferedAmount() int {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ValidJSValue(websocket__constructor, ws.value) != nil {
+		return 0
+	}
+	return ws.value.Get(websocket__bufferedAmount).Int()
+}
+
+// ReadMessage returns the next frame, waiting for one until _ctx is done or
+// the read deadline passes; a deadline set while waiting applies at once.
+// After the connection is closed for good the queued frames are still
+// returned, then the *CloseError.
+func (ws *WebSocket) ReadMessage(_ctx context.Context) (MessageType, []byte, error) {
+	for {
+		ws.mu.Lock()
+		if len(ws.queue) > 0 {
+			f := ws.queue[0]
+			ws.queue = ws.queue[1:]
+			ws.mu.Unlock()
+			return f.typ, f.data, nil
+		}
+		err, changed, dl := ws.err, ws.changed, ws.readDeadline
+		ws.mu.Unlock()
+		if err != nil {
+			return 0, nil, err
+		}
+		if err = waitChange(_ctx, changed, dl, 0); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// WriteMessage sends one frame. It waits while the socket is reconnecting or
+// its bufferedAmount is above the high-water mark, so a fast producer cannot
+// grow the browser's send buffer without bound.
+func (ws *WebSocket) WriteMessage(_ctx context.Context, _typ MessageType, _data []byte) error {
+	for {
+		ws.mu.Lock()
+		if ws.err != nil {
+			err := ws.err
+			ws.mu.Unlock()
+			return err
+		}
+		wait, dl := ws.changed, ws.writeDeadline
+		if ws.state == WebSocketOpen {
+			if ws.value.Get(websocket__bufferedAmount).Int() <= ws.opts.HighWaterMark {
+				err := ws.send(_typ, _data)
+				ws.mu.Unlock()
+				if err != nil {
+					return fmt.Errorf("[websocket] [WriteMessage] [error]: %v", err)
+				}
+				return nil
+			}
+			wait = nil
+		}
+		ws.mu.Unlock()
+
+		// a full send buffer has no event to wait for, so poll it
+		poll := time.Duration(0)
+		if wait == nil {
+			poll = websocket__drainPoll
+		}
+		if err := waitChange(_ctx, wait, dl, poll); err != nil {
+			return err
+		}
+	}
+}
+
+// WriteText ...
+func (ws *WebSocket) WriteText(_ctx context.Context, _s string) error {
+	return ws.WriteMessage(_ctx, TextMessage, []byte(_s))
+}
+
+// WriteBinary ...
+func (ws *WebSocket) WriteBinary(_ctx context.Context, _b []byte) error {
+	return ws.WriteMessage(_ctx, BinaryMessage, _b)
+}
+
+// CloseWithCode closes the connection with a close code and reason and stops
+// any reconnecting.
+func (ws *WebSocket) CloseWithCode(_code int, _reason string) error {
+	ws.shutdown(&CloseError{Code: _code, Reason: _reason, Clean: true}, _code, _reason)
+	return nil
+}
+
+// Close closes the connection normally (net.Conn).
+func (ws *WebSocket) Close() error {
+	return ws.CloseWithCode(CloseNormal, "")
+}
+
+// Read reads frame payloads as a byte stream (net.Conn). A normal closure
+// reads as io.EOF. Concurrent Reads are serialized.
+func (ws *WebSocket) Read(_b []byte) (int, error) {
+	ws.rmu.Lock()
+	defer ws.rmu.Unlock()
+	if len(ws.rbuf) == 0 {
+		_, data, err := ws.ReadMessage(context.Background())
+		if err != nil {
+			var ce *CloseError
+			if errors.As(err, &ce) && ce.Code == CloseNormal {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		ws.rbuf = data
+	}
+	n := copy(_b, ws.rbuf)
+	ws.rbuf = ws.rbuf[n:]
+	return n, nil
+}
+
+// Write sends _b as one binary frame (net.Conn).
+func (ws *WebSocket) Write(_b []byte) (int, error) {
+	if err := ws.WriteMessage(context.Background(), BinaryMessage, _b); err != nil {
+		return 0, err
+	}
+	return len(_b), nil
+}
+
+// LocalAddr ...
+func (ws *WebSocket) LocalAddr() net.Addr {
+	return wsAddr("")
+}
+
+// RemoteAddr ...
+func (ws *WebSocket) RemoteAddr() net.Addr {
+	return wsAddr(ws.URL)
+}
+
+// SetDeadline ...
+func (ws *WebSocket) SetDeadline(_t time.Time) error {
+	ws.mu.Lock()
+	ws.readDeadline, ws.writeDeadline = _t, _t
+	ws.wake()
+	ws.mu.Unlock()
+	return nil
+}
+
+// SetReadDeadline also applies to a ReadMessage or Read already waiting.
+func (ws *WebSocket) SetReadDeadline(_t time.Time) error {
+	ws.mu.Lock()
+	ws.readDeadline = _t
+	ws.wake()
+	ws.mu.Unlock()
+	return nil
+}
+
+// SetWriteDeadline also applies to a WriteMessage or Write already waiting.
+func (ws *WebSocket) SetWriteDeadline(_t time.Time) error {
+	ws.mu.Lock()
+	ws.writeDeadline = _t
+	ws.wake()
+	ws.mu.Unlock()
+	return nil
+}
+
+// connect opens a new JS socket and wires its events.
+func (ws *WebSocket) connect() error {
+	ctor := js.Global().Get(websocket__constructor)
+	if err := ValidJSValue(websocket__constructor, ctor); err != nil {
+		return err
+	}
+	protocols := make([]interface{}, len(ws.protocols))
+	for i, p := range ws.protocols {
+		protocols[i] = p
+	}
+	v, err := newJS(ctor, ws.URL, protocols)
+	if err != nil {
+		return err
+	}
+	v.Set(websocket__binaryType, websocket__arraybuffer)
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.err != nil {
+		v.Call(function__close, CloseNormal)
+		return ws.err
+	}
+	ws.value = v
+	ws.offs = []func(){
+		listen(v, EVENT__open, func(Event) { ws.opened(v) }, ListenerOptions{}),
+		listen(v, EVENT__message, func(_e Event) { ws.received(_e) }, ListenerOptions{}),
+		listen(v, EVENT__close, func(_e Event) {
+			ws.closed(v, &CloseError{
+				Code:   _e.Value.Get(close__code).Int(),
+				Reason: _e.Value.Get(close__reason).String(),
+				Clean:  _e.Value.Get(close__wasClean).Bool(),
+			})
+		}, ListenerOptions{}),
+		// browsers follow a failed connect with close 1006, but not every
+		// runtime does; treat an error before open as that close.
+		listen(v, EVENT__error, func(Event) {
+			if rs := v.Get(websocket__readyState).Int(); rs == websocket__connecting || rs == websocket__closed {
+				ws.closed(v, &CloseError{Code: CloseAbnormal})
+			}
+		}, ListenerOptions{}),
+	}
+	return nil
+}
+
+func (ws *WebSocket) opened(_v js.Value) {
+	ws.mu.Lock()
+	if !ws.value.Equal(_v) {
+		ws.mu.Unlock()
+		return
+	}
+	reconnected := ws.state == WebSocketReconnecting
+	ws.state = WebSocketOpen
+	ws.retries = 0
+	ws.wake()
+	ws.mu.Unlock()
+
+	if reconnected && ws.opts.OnReconnect != nil {
+		go ws.opts.OnReconnect()
+	}
+}
+
+func (ws *WebSocket) received(_e Event) {
+	data := _e.Value.Get(message__data)
+	f := wsFrame{typ: BinaryMessage}
+	if data.Type() == js.TypeString {
+		f = wsFrame{typ: TextMessage, data: []byte(data.String())}
+	} else {
+		f.data = bytesOf(data)
+	}
+	ws.mu.Lock()
+	ws.queue = append(ws.queue, f)
+	ws.wake()
+	ws.mu.Unlock()
+}
+
+func (ws *WebSocket) closed(_v js.Value, _ce *CloseError) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if !ws.value.Equal(_v) || ws.err != nil {
+		return
+	}
+	ws.detach()
+
+	// the first dial is never retried, and a normal closure is final
+	if !ws.opts.Reconnect || ws.state == WebSocketConnecting || _ce.Code == CloseNormal {
+		ws.state = WebSocketClosed
+		ws.err = _ce
+		ws.wake()
+		return
+	}
+	if ws.opts.MaxRetries > 0 && ws.retries >= ws.opts.MaxRetries {
+		ws.state = WebSocketClosed
+		ws.err = &reconnectError{last: _ce}
+		ws.wake()
+		return
+	}
+
+	ws.state = WebSocketReconnecting
+	delay := ws.opts.MinBackoff << uint(ws.retries)
+	if delay > ws.opts.MaxBackoff || delay <= 0 {
+		delay = ws.opts.MaxBackoff
+	}
+	ws.retries++
+	ws.wake()
+	go ws.redial(delay)
+}
+
+func (ws *WebSocket) redial(_delay time.Duration) {
+	time.Sleep(_delay)
+	if err := ws.connect(); err != nil {
+		ws.mu.Lock()
+		if ws.err == nil {
+			ws.state = WebSocketClosed
+			ws.err = fmt.Errorf("[websocket] [redial] [error]: %v", err)
+			ws.wake()
+		}
+		ws.mu.Unlock()
+	}
+}
+
+func (ws *WebSocket) shutdown(_err error, _code int, _reason string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.err != nil {
+		return
+	}
+	ws.err = _err
+	ws.state = WebSocketClosed
+	if ValidJSValue(websocket__constructor, ws.value) == nil {
+		if _, err := callJS(ws.value, function__close, _code, _reason); err != nil {
+			ws.value.Call(function__close)
+		}
+	}
+	ws.detach()
+	ws.wake()
+}
+
+// send must be called with mu held.
+func (ws *WebSocket) send(_typ MessageType, _data []byte) error {
+	var err error
+	if _typ == TextMessage {
+		_, err = callJS(ws.value, function__send, string(_data))
+	} else {
+		_, err = callJS(ws.value, function__send, uint8ArrayOf(_data))
+	}
+	return err
+}
+
+// detach must be called with mu held.
+func (ws *WebSocket) detach() {
+	for _, off := range ws.offs {
+		off()
+	}
+	ws.offs = nil
+}
+
+// wake tells every waiter that the state or queue changed; mu must be held.
+func (ws *WebSocket) wake() {
+	close(ws.changed)
+	ws.changed = make(chan struct{})
+}
+
+// reconnectError is GOWEB_ERROR_WEBSOCKET_RECONNECT carrying the close that
+// ended the last attempt, so both errors.Is and errors.As see through it.
+type reconnectError struct {
+	last *CloseError
+}
+
+func (e *reconnectError) Error() string {
+	return fmt.Sprintf("%v: %v", GOWEB_ERROR_WEBSOCKET_RECONNECT, e.last)
+}
+
+func (e *reconnectError) Is(_target error) bool {
+	return _target == GOWEB_ERROR_WEBSOCKET_RECONNECT
+}
+
+func (e *reconnectError) Unwrap() error {
+	return e.last
+}
+
+type wsAddr string
+
+func (a wsAddr) Network() string { return "websocket" }
+func (a wsAddr) String() string  { return string(a) }
+
+// waitChange waits for _changed, for _poll (when non-zero) or until _dl. It
+// returns early with nil so the caller re-reads its state and deadline, and
+// fails once _dl has passed or _ctx is done.
+func waitChange(_ctx context.Context, _changed <-chan struct{}, _dl time.Time, _poll time.Duration) error {
+	wait := _poll
+	if !_dl.IsZero() {
+		until := time.Until(_dl)
+		if until <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+		if wait == 0 || until < wait {
+			wait = until
+		}
+	}
+	var timeout <-chan time.Time
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case <-_changed:
+	case <-timeout:
+	case <-_ctx.Done():
+		return deadlineErr(_ctx)
+	}
+	return nil
+}
+
+// deadlineErr reports a missed deadline as os.ErrDeadlineExceeded, which is
+// a net.Error with Timeout() true.
+func deadlineErr(_ctx context.Context) error {
+	if _ctx.Err() == context.DeadlineExceeded {
+		return os.ErrDeadlineExceeded
+	}
+	return _ctx.Err()
+}
+
+var _ net.Conn = (*WebSocket)(nil)
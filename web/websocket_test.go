@@ -0,0 +1,427 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+const testSocketURL = "ws://webtest.local/ws"
+
+// waitFor polls _ok until it holds or a second has passed.
+func waitFor(t *testing.T, _what string, _ok func() bool) {
+	t.Helper()
+	for end := time.Now().Add(time.Second); time.Now().Before(end); time.Sleep(2 * time.Millisecond) {
+		if _ok() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", _what)
+}
+
+// waitSocket waits for the _n-th fake WebSocket (1-based) to be constructed.
+func waitSocket(t *testing.T, _h *webtest.Harness, _n int) js.Value {
+	t.Helper()
+	waitFor(t, "socket", func() bool { return len(_h.Sockets()) >= _n })
+	return _h.Sockets()[_n-1]
+}
+
+// dialSocket dials the fake server and completes the handshake.
+func dialSocket(t *testing.T, _h *webtest.Harness, _opts web.WebSocketOptions) (*web.WebSocket, js.Value) {
+	t.Helper()
+	type result struct {
+		ws  *web.WebSocket
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ws, err := web.DialWebSocket(context.Background(), testSocketURL, []string{"v1"}, _opts)
+		done <- result{ws, err}
+	}()
+	s := waitSocket(t, _h, len(_h.Sockets())+1)
+	_h.OpenSocket(s, "v1")
+	r := <-done
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	return r.ws, s
+}
+
+func sentData(_c webtest.Call) []byte {
+	a := _c.Arg(0)
+	b := make([]byte, a.Get("byteLength").Int())
+	js.CopyBytesToGo(b, a)
+	return b
+}
+
+func TestDialWebSocket(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	ws, s := dialSocket(t, h, web.WebSocketOptions{})
+	defer ws.Close()
+
+	calls := h.CallsTo(webtest.TargetWebSocket, "constructor")
+	if len(calls) != 1 || calls[0].Arg(0).String() != testSocketURL || calls[0].Arg(1).Index(0).String() != "v1" {
+		t.Fatalf("constructor calls = %v", calls)
+	}
+	if got := s.Get("binaryType").String(); got != "arraybuffer" {
+		t.Errorf("binaryType = %q, want arraybuffer", got)
+	}
+	if ws.State() != web.WebSocketOpen {
+		t.Errorf("State = %v, want open", ws.State())
+	}
+	if ws.Protocol() != "v1" {
+		t.Errorf("Protocol = %q, want v1", ws.Protocol())
+	}
+}
+
+func TestDialWebSocketFailure(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := web.DialWebSocket(context.Background(), testSocketURL, nil, web.WebSocketOptions{Reconnect: true})
+		done <- err
+	}()
+	h.CloseSocket(waitSocket(t, h, 1), web.CloseAbnormal, "", false)
+
+	if err := <-done; err == nil {
+		t.Fatal("DialWebSocket succeeded after the handshake failed")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := len(h.Sockets()); n != 1 {
+		t.Errorf("%d sockets, want the first dial not to be retried", n)
+	}
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	ws, s := dialSocket(t, h, web.WebSocketOptions{})
+	defer ws.Close()
+
+	h.SocketMessage(s, "hello")
+	h.SocketMessage(s, []byte{1, 2, 3})
+
+	ctx := context.Background()
+	typ, data, err := ws.ReadMessage(ctx)
+	if err != nil || typ != web.TextMessage || string(data) != "hello" {
+		t.Errorf("ReadMessage = %v %q %v, want text hello", typ, data, err)
+	}
+	typ, data, err = ws.ReadMessage(ctx)
+	if err != nil || typ != web.BinaryMessage || string(data) != "\x01\x02\x03" {
+		t.Errorf("ReadMessage = %v %v %v, want binary [1 2 3]", typ, data, err)
+	}
+
+	if err := ws.WriteText(ctx, "ping"); err != nil {
+		t.Fatal(err)
+	}
+	calls := h.CallsTo(webtest.TargetWebSocket, "send")
+	if len(calls) != 1 || calls[0].Arg(0).String() != "ping" {
+		t.Errorf("send calls = %v, want ping", calls)
+	}
+}
+
+func TestWebSocketCloseCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   int
+		reason string
+		eof    bool
+	}{
+		{"normal", web.CloseNormal, "", true},
+		{"going away", web.CloseGoingAway, "restart", false},
+		{"internal error", web.CloseInternalError, "boom", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := webtest.Install()
+			defer h.Uninstall()
+
+			ws, s := dialSocket(t, h, web.WebSocketOptions{})
+			h.SocketMessage(s, "last")
+			h.CloseSocket(s, tt.code, tt.reason, true)
+
+			// frames queued before the close are still delivered
+			if _, data, err := ws.ReadMessage(context.Background()); err != nil || string(data) != "last" {
+				t.Fatalf("ReadMessage = %q %v, want the queued frame", data, err)
+			}
+			_, _, err := ws.ReadMessage(context.Background())
+			var ce *web.CloseError
+			if !errors.As(err, &ce) || ce.Code != tt.code || ce.Reason != tt.reason || !ce.Clean {
+				t.Fatalf("ReadMessage error = %v, want close %d %q", err, tt.code, tt.reason)
+			}
+			if ws.State() != web.WebSocketClosed {
+				t.Errorf("State = %v, want closed", ws.State())
+			}
+
+			_, err = ws.Read(make([]byte, 8))
+			if tt.eof != (err == io.EOF) {
+				t.Errorf("Read error = %v, want io.EOF only for a normal closure", err)
+			}
+			if err := ws.WriteText(context.Background(), "late"); !errors.As(err, &ce) {
+				t.Errorf("WriteText after close = %v, want *CloseError", err)
+			}
+		})
+	}
+}
+
+func TestWebSocketCloseWithCode(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	ws, _ := dialSocket(t, h, web.WebSocketOptions{Reconnect: true})
+	if err := ws.CloseWithCode(4000, "bye"); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := h.CallsTo(webtest.TargetWebSocket, "close")
+	if len(calls) != 1 || calls[0].Arg(0).Int() != 4000 || calls[0].Arg(1).String() != "bye" {
+		t.Fatalf("close calls = %v, want close(4000, bye)", calls)
+	}
+	_, _, err := ws.ReadMessage(context.Background())
+	var ce *web.CloseError
+	if !errors.As(err, &ce) || ce.Code != 4000 {
+		t.Errorf("ReadMessage error = %v, want close 4000", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := len(h.Sockets()); n != 1 {
+		t.Errorf("%d sockets, want no reconnect after CloseWithCode", n)
+	}
+}
+
+func TestWebSocketReconnect(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	const min, max = 20 * time.Millisecond, 40 * time.Millisecond
+	var reconnects int32
+	ws, s1 := dialSocket(t, h, web.WebSocketOptions{
+		Reconnect:   true,
+		MinBackoff:  min,
+		MaxBackoff:  max,
+		MaxRetries:  3,
+		OnReconnect: func() { atomic.AddInt32(&reconnects, 1) },
+	})
+	defer ws.Close()
+
+	h.SocketMessage(s1, "before")
+	start := time.Now()
+	h.CloseSocket(s1, web.CloseAbnormal, "", false)
+	if ws.State() != web.WebSocketReconnecting {
+		t.Fatalf("State = %v, want reconnecting", ws.State())
+	}
+
+	// writes wait for the new connection
+	wrote := make(chan error, 1)
+	go func() { wrote <- ws.WriteText(context.Background(), "queued") }()
+
+	s2 := waitSocket(t, h, 2)
+	if d := time.Since(start); d < min {
+		t.Errorf("redialed after %v, want at least %v", d, min)
+	}
+	if n := len(h.CallsTo(webtest.TargetWebSocket, "send")); n != 0 {
+		t.Fatalf("%d sends before the socket reopened", n)
+	}
+	h.OpenSocket(s2, "v1")
+	if err := <-wrote; err != nil {
+		t.Fatal(err)
+	}
+	if calls := h.CallsTo(webtest.TargetWebSocket, "send"); len(calls) != 1 || !calls[0].This.Equal(s2) {
+		t.Errorf("send calls = %v, want one on the new socket", calls)
+	}
+	waitFor(t, "OnReconnect", func() bool { return atomic.LoadInt32(&reconnects) == 1 })
+
+	h.SocketMessage(s2, "after")
+	for _, want := range []string{"before", "after"} {
+		if _, data, err := ws.ReadMessage(context.Background()); err != nil || string(data) != want {
+			t.Errorf("ReadMessage = %q %v, want %q", data, err, want)
+		}
+	}
+
+	// the backoff doubles per failed attempt, capped at MaxBackoff, and
+	// starts over after a successful open
+	prev := s2
+	for i, want := range []time.Duration{min, 2 * min, max} {
+		start = time.Now()
+		h.CloseSocket(prev, web.CloseAbnormal, "", false)
+		prev = waitSocket(t, h, 3+i)
+		if d := time.Since(start); d < want {
+			t.Errorf("attempt %d after %v, want at least %v", i+1, d, want)
+		}
+	}
+
+	h.CloseSocket(prev, web.CloseAbnormal, "", false)
+	_, _, err := ws.ReadMessage(context.Background())
+	var ce *web.CloseError
+	if !errors.Is(err, web.GOWEB_ERROR_WEBSOCKET_RECONNECT) || !errors.As(err, &ce) || ce.Code != web.CloseAbnormal {
+		t.Fatalf("ReadMessage error = %v, want to give up after MaxRetries", err)
+	}
+	if n := len(h.Sockets()); n != 5 {
+		t.Errorf("%d sockets, want 5", n)
+	}
+}
+
+func TestWebSocketBackpressure(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	ws, s := dialSocket(t, h, web.WebSocketOptions{HighWaterMark: 16})
+	defer ws.Close()
+
+	s.Set("bufferedAmount", 32)
+	if ws.BufferedAmount() != 32 {
+		t.Errorf("BufferedAmount = %d, want 32", ws.BufferedAmount())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := ws.WriteBinary(ctx, []byte("x")); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("WriteBinary above the high-water mark = %v, want a deadline error", err)
+	}
+
+	wrote := make(chan error, 1)
+	go func() { wrote <- ws.WriteBinary(context.Background(), []byte("y")) }()
+	time.Sleep(30 * time.Millisecond)
+	if n := len(h.CallsTo(webtest.TargetWebSocket, "send")); n != 0 {
+		t.Fatalf("%d sends while bufferedAmount was above the high-water mark", n)
+	}
+
+	s.Set("bufferedAmount", 0)
+	if err := <-wrote; err != nil {
+		t.Fatal(err)
+	}
+	calls := h.CallsTo(webtest.TargetWebSocket, "send")
+	if len(calls) != 1 || string(sentData(calls[0])) != "y" {
+		t.Errorf("send calls = %v, want y once drained", calls)
+	}
+}
+
+func TestWebSocketConn(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	ws, s := dialSocket(t, h, web.WebSocketOptions{})
+	var conn net.Conn = ws
+
+	if conn.RemoteAddr().String() != testSocketURL || conn.RemoteAddr().Network() != "websocket" {
+		t.Errorf("RemoteAddr = %v", conn.RemoteAddr())
+	}
+
+	// frame boundaries disappear in the byte stream
+	h.SocketMessage(s, "hello")
+	h.SocketMessage(s, []byte("world"))
+	var got []byte
+	buf := make([]byte, 3)
+	for len(got) < 10 {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != "helloworld" {
+		t.Errorf("Read = %q, want helloworld", got)
+	}
+
+	if n, err := conn.Write([]byte{1, 2, 3}); err != nil || n != 3 {
+		t.Fatalf("Write = %d %v", n, err)
+	}
+	calls := h.CallsTo(webtest.TargetWebSocket, "send")
+	if len(calls) != 1 || string(sentData(calls[0])) != "\x01\x02\x03" {
+		t.Errorf("send calls = %v, want one binary frame", calls)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err := conn.Read(buf)
+	if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("Read past the deadline = %v, want a timeout", err)
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	h.CloseSocket(s, web.CloseNormal, "", true)
+	if _, err := conn.Read(buf); err != io.EOF {
+		t.Errorf("Read after a normal closure = %v, want io.EOF", err)
+	}
+}
+
+func TestWebSocketDeadlineWhileWaiting(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	ws, s := dialSocket(t, h, web.WebSocketOptions{HighWaterMark: 16})
+	defer ws.Close()
+
+	// a Read blocked with no deadline gives up once one is set
+	read := make(chan error, 1)
+	go func() {
+		_, err := ws.Read(make([]byte, 8))
+		read <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ws.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	select {
+	case err := <-read:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("Read = %v, want a deadline error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read ignored a deadline set while it waited")
+	}
+
+	// an already passed deadline wakes a waiting reader at once
+	ws.SetReadDeadline(time.Time{})
+	go func() {
+		_, _, err := ws.ReadMessage(context.Background())
+		read <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ws.SetDeadline(time.Now().Add(-time.Second))
+	select {
+	case err := <-read:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("ReadMessage = %v, want a deadline error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ReadMessage ignored a passed deadline")
+	}
+
+	// clearing the deadline lets a read wait for data again
+	ws.SetDeadline(time.Time{})
+	h.SocketMessage(s, "late")
+	if _, b, err := ws.ReadMessage(context.Background()); err != nil || string(b) != "late" {
+		t.Errorf("ReadMessage after clearing the deadline = %q, %v", b, err)
+	}
+
+	// a Write stuck behind a full send buffer gives up too
+	s.Set("bufferedAmount", 32)
+	wrote := make(chan error, 1)
+	go func() {
+		_, err := ws.Write([]byte("x"))
+		wrote <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ws.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+	select {
+	case err := <-wrote:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("Write = %v, want a deadline error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write ignored a deadline set while it waited")
+	}
+}
@@ -0,0 +1,679 @@
+//+build tinygo wasm,js
+
+package webtest
+
+import (
+	"strings"
+	"syscall/js"
+	"time"
+)
+
+func (h *Harness) newDocument() js.Value {
+	doc := object()
+	doc.Set("title", DefaultTitle)
+	doc.Set("nodeType", 9)
+
+	h.Head = h.newElement("head")
+	h.Body = h.newElement("body")
+	doc.Set("head", h.Head)
+	doc.Set("body", h.Body)
+	doc.Set("documentElement", h.Body)
+
+	h.method(doc, TargetDocument, "createElement", func(_this js.Value, _args []js.Value) interface{} {
+		return h.newElement(arg(_args, 0).String())
+	})
+	h.method(doc, TargetDocument, "createTextNode", func(_this js.Value, _args []js.Value) interface{} {
+		n := object()
+		n.Set("nodeType", 3)
+		n.Set("textContent", arg(_args, 0))
+		n.Set("data", arg(_args, 0))
+		n.Set("parentNode", js.Null())
+		h.accessor(n, "nodeValue", func() interface{} { return n.Get("data") }, func(_v js.Value) {
+			n.Set("data", _v)
+			n.Set("textContent", _v)
+		})
+		h.eventTarget(n, TargetElement)
+		return n
+	})
+	h.method(doc, TargetDocument, "getElementById", func(_this js.Value, _args []js.Value) interface{} {
+		id := arg(_args, 0).String()
+		for _, el := range append(descendants(h.Head), descendants(h.Body)...) {
+			if el.Get("id").String() == id {
+				return el
+			}
+		}
+		return js.Null()
+	})
+	h.method(doc, TargetDocument, "querySelector", func(_this js.Value, _args []js.Value) interface{} {
+		return querySelector(h.Body, arg(_args, 0).String(), true)
+	})
+	h.method(doc, TargetDocument, "querySelectorAll", func(_this js.Value, _args []js.Value) interface{} {
+		return querySelectorAll(h.Body, arg(_args, 0).String(), true)
+	})
+
+	h.accessor(doc, "cookie", h.getCookie, h.setCookie)
+
+	return doc
+}
+
+func (h *Harness) getCookie() interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pairs := make([]string, len(h.cookies))
+	for i, c := range h.cookies {
+		pairs[i] = c.name + "=" + c.value
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// setCookie follows the document.cookie setter: one cookie per write, with an
+// expired Expires or a non-positive Max-Age deleting it.
+func (h *Harness) setCookie(_v js.Value) {
+	parts := strings.Split(_v.String(), ";")
+	kv := strings.SplitN(parts[0], "=", 2)
+	name := strings.TrimSpace(kv[0])
+	value := ""
+	if len(kv) == 2 {
+		value = strings.TrimSpace(kv[1])
+	}
+
+	del := false
+	for _, p := range parts[1:] {
+		akv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(akv) != 2 {
+			continue
+		}
+		switch strings.ToLower(akv[0]) {
+		case "max-age":
+			if strings.HasPrefix(akv[1], "-") || akv[1] == "0" {
+				del = true
+			}
+		case "expires":
+			if t, err := time.Parse(time.RFC1123, akv[1]); err == nil && t.Before(time.Now()) {
+				del = true
+			}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, c := range h.cookies {
+		if c.name == name {
+			if del {
+				h.cookies = append(h.cookies[:i], h.cookies[i+1:]...)
+			} else {
+				h.cookies[i].value = value
+			}
+			return
+		}
+	}
+	if !del {
+		h.cookies = append(h.cookies, cookie{name: name, value: value})
+	}
+}
+
+func (h *Harness) newElement(_tag string) js.Value {
+	el := object()
+	tag := strings.ToUpper(_tag)
+	el.Set("tagName", tag)
+	el.Set("nodeName", tag)
+	el.Set("nodeType", 1)
+	el.Set("id", "")
+	el.Set("className", "")
+	el.Set("innerText", "")
+	el.Set("textContent", "")
+	el.Set("value", "")
+	el.Set("parentNode", js.Null())
+	el.Set("parentElement", js.Null())
+	children := array()
+	el.Set("children", children)
+	el.Set("childNodes", children)
+	el.Set("style", h.newStyle())
+	el.Set("classList", h.newClassList(el))
+	el.Set("dataset", object())
+	el.Set("__attrs", object())
+
+	h.accessor(el, "nextElementSibling", func() interface{} { return sibling(el, 1) }, func(js.Value) {})
+	h.accessor(el, "previousElementSibling", func() interface{} { return sibling(el, -1) }, func(js.Value) {})
+
+	h.formControl(el, tag)
+
+	if strings.HasPrefix(strings.ToLower(_tag), "a-") {
+		el.Set("isEntity", true)
+		el.Set("components", object())
+		el.Set("object3D", h.newObject3D())
+		h.method(el, TargetElement, "destroy", nil)
+	}
+
+	h.method(el, TargetElement, "setAttribute", func(_this js.Value, _args []js.Value) interface{} {
+		name, val := arg(_args, 0).String(), arg(_args, 1)
+		_this.Get("__attrs").Set(name, val)
+		switch name {
+		case "id":
+			_this.Set("id", val.String())
+		case "class":
+			_this.Set("className", val.String())
+		case "name", "value":
+			_this.Set(name, val.String())
+		case "type":
+			if _this.Get("tagName").String() == "INPUT" {
+				typ := strings.ToLower(val.String())
+				_this.Set("type", typ)
+				// like a browser, a box without a value attribute submits "on"
+				if (typ == "checkbox" || typ == "radio") && _this.Get("__attrs").Get("value").IsUndefined() {
+					_this.Set("value", "on")
+				}
+			}
+		case "checked", "selected", "disabled":
+			_this.Set(name, true)
+		case "multiple":
+			_this.Set("multiple", true)
+			if _this.Get("tagName").String() == "SELECT" {
+				_this.Set("type", "select-multiple")
+			}
+		}
+		return nil
+	})
+	h.method(el, TargetElement, "getAttribute", func(_this js.Value, _args []js.Value) interface{} {
+		v := _this.Get("__attrs").Get(arg(_args, 0).String())
+		if v.IsUndefined() {
+			return js.Null()
+		}
+		return v
+	})
+	h.method(el, TargetElement, "hasAttribute", func(_this js.Value, _args []js.Value) interface{} {
+		return !_this.Get("__attrs").Get(arg(_args, 0).String()).IsUndefined()
+	})
+	h.method(el, TargetElement, "removeAttribute", func(_this js.Value, _args []js.Value) interface{} {
+		name := arg(_args, 0).String()
+		_this.Get("__attrs").Delete(name)
+		switch name {
+		case "id":
+			_this.Set("id", "")
+		case "class":
+			_this.Set("className", "")
+		}
+		return nil
+	})
+	h.method(el, TargetElement, "appendChild", func(_this js.Value, _args []js.Value) interface{} {
+		appendChild(_this, arg(_args, 0))
+		return arg(_args, 0)
+	})
+	h.method(el, TargetElement, "insertBefore", func(_this js.Value, _args []js.Value) interface{} {
+		insertBefore(_this, arg(_args, 0), arg(_args, 1))
+		return arg(_args, 0)
+	})
+	h.method(el, TargetElement, "removeChild", func(_this js.Value, _args []js.Value) interface{} {
+		detach(arg(_args, 0))
+		return arg(_args, 0)
+	})
+	h.method(el, TargetElement, "remove", func(_this js.Value, _args []js.Value) interface{} {
+		detach(_this)
+		return nil
+	})
+	h.method(el, TargetElement, "querySelector", func(_this js.Value, _args []js.Value) interface{} {
+		return querySelector(_this, arg(_args, 0).String(), false)
+	})
+	h.method(el, TargetElement, "querySelectorAll", func(_this js.Value, _args []js.Value) interface{} {
+		return querySelectorAll(_this, arg(_args, 0).String(), false)
+	})
+	h.method(el, TargetElement, "matches", func(_this js.Value, _args []js.Value) interface{} {
+		return matches(_this, arg(_args, 0).String())
+	})
+	h.method(el, TargetElement, "closest", func(_this js.Value, _args []js.Value) interface{} {
+		for n := _this; n.Type() == js.TypeObject && n.Get("nodeType").Int() == 1; n = n.Get("parentNode") {
+			if matches(n, arg(_args, 0).String()) {
+				return n
+			}
+		}
+		return js.Null()
+	})
+	h.method(el, TargetElement, "contains", func(_this js.Value, _args []js.Value) interface{} {
+		for n := arg(_args, 0); n.Type() == js.TypeObject; n = n.Get("parentNode") {
+			if n.Equal(_this) {
+				return true
+			}
+		}
+		return false
+	})
+	h.eventTarget(el, TargetElement)
+	h.method(el, TargetElement, "emit", func(_this js.Value, _args []js.Value) interface{} {
+		evt := object()
+		evt.Set("type", arg(_args, 0))
+		evt.Set("detail", arg(_args, 1))
+		evt.Set("bubbles", arg(_args, 2).Truthy())
+		dispatch(_this, evt)
+		return nil
+	})
+
+	return el
+}
+
+// newStyle returns a CSSStyleDeclaration-like object keeping properties by
+// their CSS name.
+func (h *Harness) newStyle() js.Value {
+	st := object()
+	props := object()
+	h.method(st, TargetElement, "setProperty", func(_this js.Value, _args []js.Value) interface{} {
+		props.Set(arg(_args, 0).String(), arg(_args, 1).String())
+		return nil
+	})
+	h.method(st, TargetElement, "getPropertyValue", func(_this js.Value, _args []js.Value) interface{} {
+		if v := props.Get(arg(_args, 0).String()); v.Type() == js.TypeString {
+			return v
+		}
+		return ""
+	})
+	h.method(st, TargetElement, "removeProperty", func(_this js.Value, _args []js.Value) interface{} {
+		props.Delete(arg(_args, 0).String())
+		return nil
+	})
+	return st
+}
+
+// newClassList returns a DOMTokenList-like view of _el.className.
+func (h *Harness) newClassList(_el js.Value) js.Value {
+	cl := object()
+	classes := func() []string { return strings.Fields(_el.Get("className").String()) }
+	set := func(_c []string) { _el.Set("className", strings.Join(_c, " ")) }
+	without := func(_c []string, _name string) []string {
+		r := []string{}
+		for _, c := range _c {
+			if c != _name {
+				r = append(r, c)
+			}
+		}
+		return r
+	}
+
+	h.accessor(cl, "value", func() interface{} { return _el.Get("className") }, func(_v js.Value) { _el.Set("className", _v) })
+	h.accessor(cl, "length", func() interface{} { return len(classes()) }, func(js.Value) {})
+	h.method(cl, TargetElement, "add", func(_this js.Value, _args []js.Value) interface{} {
+		c := classes()
+		for _, a := range _args {
+			if !hasClass(_el, a.String()) {
+				c = append(c, a.String())
+			}
+		}
+		set(c)
+		return nil
+	})
+	h.method(cl, TargetElement, "remove", func(_this js.Value, _args []js.Value) interface{} {
+		c := classes()
+		for _, a := range _args {
+			c = without(c, a.String())
+		}
+		set(c)
+		return nil
+	})
+	h.method(cl, TargetElement, "contains", func(_this js.Value, _args []js.Value) interface{} {
+		return hasClass(_el, arg(_args, 0).String())
+	})
+	h.method(cl, TargetElement, "toggle", func(_this js.Value, _args []js.Value) interface{} {
+		name := arg(_args, 0).String()
+		on := !hasClass(_el, name)
+		if f := arg(_args, 1); f.Type() == js.TypeBoolean {
+			on = f.Bool()
+		}
+		c := without(classes(), name)
+		if on {
+			c = append(c, name)
+		}
+		set(c)
+		return on
+	})
+	h.method(cl, TargetElement, "replace", func(_this js.Value, _args []js.Value) interface{} {
+		old, nw := arg(_args, 0).String(), arg(_args, 1).String()
+		if !hasClass(_el, old) {
+			return false
+		}
+		c := classes()
+		for i := range c {
+			if c[i] == old {
+				c[i] = nw
+			}
+		}
+		set(c)
+		return true
+	})
+	return cl
+}
+
+// formControl adds the properties and methods of form, input, select,
+// textarea and option elements that the web form helpers read.
+func (h *Harness) formControl(_el js.Value, _tag string) {
+	switch _tag {
+	case "FORM":
+		h.accessor(_el, "elements", func() interface{} {
+			r := array()
+			for _, c := range descendants(_el) {
+				switch c.Get("tagName").String() {
+				case "INPUT", "SELECT", "TEXTAREA", "BUTTON":
+					r.Call("push", c)
+				}
+			}
+			return r
+		}, func(js.Value) {})
+		h.method(_el, TargetElement, "requestSubmit", func(_this js.Value, _args []js.Value) interface{} {
+			dispatch(_this, h.newEvent("submit", true))
+			return nil
+		})
+		h.method(_el, TargetElement, "reset", nil)
+		return
+	case "INPUT":
+		_el.Set("type", "text")
+		_el.Set("checked", false)
+		_el.Set("files", js.Null())
+		h.method(_el, TargetElement, "select", nil)
+	case "SELECT":
+		_el.Set("type", "select-one")
+		_el.Set("multiple", false)
+		h.accessor(_el, "options", func() interface{} {
+			r := array()
+			for _, c := range descendants(_el) {
+				if c.Get("tagName").String() == "OPTION" {
+					r.Call("push", c)
+				}
+			}
+			return r
+		}, func(js.Value) {})
+	case "TEXTAREA":
+		_el.Set("type", "textarea")
+		h.method(_el, TargetElement, "select", nil)
+	case "BUTTON":
+		_el.Set("type", "submit")
+	case "OPTION":
+		_el.Set("selected", false)
+		return
+	default:
+		return
+	}
+	_el.Set("name", "")
+	_el.Set("disabled", false)
+	_el.Set("validationMessage", "")
+	h.method(_el, TargetElement, "setCustomValidity", func(_this js.Value, _args []js.Value) interface{} {
+		_this.Set("validationMessage", arg(_args, 0))
+		return nil
+	})
+}
+
+// newEvent returns a plain event object whose preventDefault sets
+// defaultPrevented.
+func (h *Harness) newEvent(_typ string, _bubbles bool) js.Value {
+	evt := object()
+	evt.Set("type", _typ)
+	evt.Set("bubbles", _bubbles)
+	evt.Set("defaultPrevented", false)
+	h.method(evt, TargetElement, "preventDefault", func(js.Value, []js.Value) interface{} {
+		evt.Set("defaultPrevented", true)
+		return nil
+	})
+	h.method(evt, TargetElement, "stopPropagation", nil)
+	return evt
+}
+
+// eventTarget adds addEventListener, removeEventListener and dispatchEvent to
+// _obj, keeping listeners in its __listeners object.
+func (h *Harness) eventTarget(_obj js.Value, _target string) {
+	_obj.Set("__listeners", object())
+	h.method(_obj, _target, "addEventListener", func(_this js.Value, _args []js.Value) interface{} {
+		typ := arg(_args, 0).String()
+		ls := _this.Get("__listeners")
+		if ls.Get(typ).IsUndefined() {
+			ls.Set(typ, array())
+		}
+		ls.Get(typ).Call("push", arg(_args, 1))
+		return nil
+	})
+	h.method(_obj, _target, "removeEventListener", func(_this js.Value, _args []js.Value) interface{} {
+		list := _this.Get("__listeners").Get(arg(_args, 0).String())
+		if list.IsUndefined() {
+			return nil
+		}
+		for i := 0; i < list.Length(); i++ {
+			if list.Index(i).Equal(arg(_args, 1)) {
+				list.Call("splice", i, 1)
+				break
+			}
+		}
+		return nil
+	})
+	h.method(_obj, _target, "dispatchEvent", func(_this js.Value, _args []js.Value) interface{} {
+		dispatch(_this, arg(_args, 0))
+		return true
+	})
+}
+
+func (h *Harness) newObject3D() js.Value {
+	o := object()
+	for _, n := range []string{"position", "rotation", "scale"} {
+		v := object()
+		v.Set("x", 0)
+		v.Set("y", 0)
+		v.Set("z", 0)
+		o.Set(n, v)
+	}
+	o.Get("scale").Set("x", 1)
+	o.Get("scale").Set("y", 1)
+	o.Get("scale").Set("z", 1)
+	o.Set("visible", true)
+	o.Set("children", array())
+	h.method(o, TargetThree, "add", func(_this js.Value, _args []js.Value) interface{} {
+		_this.Get("children").Call("push", arg(_args, 0))
+		return _this
+	})
+	return o
+}
+
+// dispatch runs the listeners registered for the event type on the target and,
+// when the event bubbles, on each of its ancestors.
+func dispatch(_target, _evt js.Value) {
+	if _evt.Get("target").IsUndefined() {
+		_evt.Set("target", _target)
+	}
+	typ := _evt.Get("type").String()
+	for n := _target; n.Type() == js.TypeObject; n = n.Get("parentNode") {
+		_evt.Set("currentTarget", n)
+		if list := n.Get("__listeners").Get(typ); list.Type() == js.TypeObject {
+			for _, cb := range snapshot(list) {
+				if cb.Type() == js.TypeFunction {
+					cb.Invoke(_evt)
+				}
+			}
+		}
+		if !_evt.Get("bubbles").Truthy() {
+			break
+		}
+	}
+}
+
+func snapshot(_arr js.Value) []js.Value {
+	r := make([]js.Value, _arr.Length())
+	for i := range r {
+		r[i] = _arr.Index(i)
+	}
+	return r
+}
+
+func sibling(_el js.Value, _step int) js.Value {
+	p := _el.Get("parentNode")
+	if p.Type() != js.TypeObject {
+		return js.Null()
+	}
+	list := snapshot(p.Get("children"))
+	for i, c := range list {
+		if !c.Equal(_el) {
+			continue
+		}
+		for j := i + _step; j >= 0 && j < len(list); j += _step {
+			if list[j].Get("nodeType").Int() == 1 {
+				return list[j]
+			}
+		}
+		break
+	}
+	return js.Null()
+}
+
+func detach(_child js.Value) {
+	p := _child.Get("parentNode")
+	if p.Type() != js.TypeObject {
+		return
+	}
+	list := p.Get("children")
+	for i := 0; i < list.Length(); i++ {
+		if list.Index(i).Equal(_child) {
+			list.Call("splice", i, 1)
+			break
+		}
+	}
+	_child.Set("parentNode", js.Null())
+	_child.Set("parentElement", js.Null())
+}
+
+func appendChild(_parent, _child js.Value) {
+	detach(_child)
+	_parent.Get("children").Call("push", _child)
+	_child.Set("parentNode", _parent)
+	_child.Set("parentElement", _parent)
+}
+
+func insertBefore(_parent, _child, _ref js.Value) {
+	if _ref.Type() != js.TypeObject {
+		appendChild(_parent, _child)
+		return
+	}
+	detach(_child)
+	list := _parent.Get("children")
+	for i := 0; i < list.Length(); i++ {
+		if list.Index(i).Equal(_ref) {
+			list.Call("splice", i, 0, _child)
+			_child.Set("parentNode", _parent)
+			_child.Set("parentElement", _parent)
+			return
+		}
+	}
+	appendChild(_parent, _child)
+}
+
+func descendants(_root js.Value) []js.Value {
+	r := []js.Value{}
+	children := _root.Get("children")
+	if children.Type() != js.TypeObject {
+		return r
+	}
+	for _, c := range snapshot(children) {
+		if c.Get("nodeType").Int() != 1 {
+			continue
+		}
+		r = append(r, c)
+		r = append(r, descendants(c)...)
+	}
+	return r
+}
+
+func querySelector(_root js.Value, _sel string, _self bool) js.Value {
+	for _, el := range candidates(_root, _self) {
+		if matches(el, _sel) {
+			return el
+		}
+	}
+	return js.Null()
+}
+
+func querySelectorAll(_root js.Value, _sel string, _self bool) js.Value {
+	r := array()
+	for _, el := range candidates(_root, _self) {
+		if matches(el, _sel) {
+			r.Call("push", el)
+		}
+	}
+	return r
+}
+
+func candidates(_root js.Value, _self bool) []js.Value {
+	if _self {
+		return append([]js.Value{_root}, descendants(_root)...)
+	}
+	return descendants(_root)
+}
+
+// matches understands comma separated compound selectors made of a tag, #id,
+// .class and [attr] / [attr="value"] parts. Combinators are not supported.
+func matches(_el js.Value, _sel string) bool {
+	for _, s := range strings.Split(_sel, ",") {
+		if matchCompound(_el, strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchCompound(_el js.Value, _sel string) bool {
+	if _sel == "" {
+		return false
+	}
+	rest := _sel
+	tagEnd := strings.IndexAny(rest, "#.[")
+	if tagEnd < 0 {
+		tagEnd = len(rest)
+	}
+	if tag := rest[:tagEnd]; tag != "" && tag != "*" && !strings.EqualFold(tag, _el.Get("tagName").String()) {
+		return false
+	}
+	rest = rest[tagEnd:]
+
+	for rest != "" {
+		switch rest[0] {
+		case '#', '.':
+			end := strings.IndexAny(rest[1:], "#.[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if rest[0] == '#' && _el.Get("id").String() != name {
+				return false
+			}
+			if rest[0] == '.' && !hasClass(_el, name) {
+				return false
+			}
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return false
+			}
+			kv := strings.SplitN(rest[1:end], "=", 2)
+			attr := attribute(_el, kv[0])
+			if attr.Type() != js.TypeString && attr.Type() != js.TypeNumber && attr.Type() != js.TypeBoolean {
+				return false
+			}
+			if len(kv) == 2 && js.Global().Call("String", attr).String() != strings.Trim(kv[1], `"'`) {
+				return false
+			}
+			rest = rest[end+1:]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func attribute(_el js.Value, _name string) js.Value {
+	switch _name {
+	case "id":
+		return _el.Get("id")
+	case "class":
+		return _el.Get("className")
+	}
+	return _el.Get("__attrs").Get(_name)
+}
+
+func hasClass(_el js.Value, _name string) bool {
+	for _, c := range strings.Fields(_el.Get("className").String()) {
+		if c == _name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+//+build tinygo wasm,js
+
+package webtest
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+)
+
+func (h *Harness) newConsole() js.Value {
+	c := object()
+	for _, m := range consoleMethods {
+		h.method(c, TargetConsole, m, nil)
+	}
+	return c
+}
+
+func (h *Harness) newAframe() js.Value {
+	af := object()
+	af.Set("version", DefaultTitle)
+	af.Set("components", object())
+	af.Set("systems", object())
+	h.method(af, TargetAframe, "registerComponent", func(_this js.Value, _args []js.Value) interface{} {
+		_this.Get("components").Set(arg(_args, 0).String(), arg(_args, 1))
+		return nil
+	})
+	h.method(af, TargetAframe, "registerSystem", func(_this js.Value, _args []js.Value) interface{} {
+		_this.Get("systems").Set(arg(_args, 0).String(), arg(_args, 1))
+		return nil
+	})
+	return af
+}
+
+// newThree fakes the handful of THREE constructors the aframe package uses.
+// Each constructor records its arguments and returns a plain object.
+func (h *Harness) newThree() js.Value {
+	t := object()
+	t.Set("BackSide", 1)
+	t.Set("FrontSide", 0)
+	t.Set("DoubleSide", 2)
+
+	t.Set("Vector3", h.fn(TargetThree, "Vector3", func(_this js.Value, _args []js.Value) interface{} {
+		v := object()
+		for i, n := range []string{"x", "y", "z"} {
+			v.Set(n, 0)
+			if a := arg(_args, i); a.Type() == js.TypeNumber {
+				v.Set(n, a)
+			}
+		}
+		return v
+	}))
+	t.Set("BoxGeometry", h.fn(TargetThree, "BoxGeometry", func(_this js.Value, _args []js.Value) interface{} {
+		g := object()
+		g.Set("parameters", map[string]interface{}{"width": arg(_args, 0), "height": arg(_args, 1), "depth": arg(_args, 2)})
+		return g
+	}))
+	t.Set("TextureLoader", h.fn(TargetThree, "TextureLoader", func(_this js.Value, _args []js.Value) interface{} {
+		l := object()
+		h.method(l, TargetThree, "load", func(_this js.Value, _args []js.Value) interface{} {
+			tex := object()
+			tex.Set("image", map[string]interface{}{"src": arg(_args, 0)})
+			return tex
+		})
+		return l
+	}))
+	t.Set("Mesh", h.fn(TargetThree, "Mesh", func(_this js.Value, _args []js.Value) interface{} {
+		m := object()
+		m.Set("id", h.id())
+		m.Set("geometry", arg(_args, 0))
+		m.Set("material", arg(_args, 1))
+		return m
+	}))
+	for _, n := range []string{"MeshBasicMaterial", "MeshStandardMaterial"} {
+		t.Set(n, h.fn(TargetThree, n, func(_this js.Value, _args []js.Value) interface{} {
+			m := js.Global().Get("Object").Call("assign", object(), arg(_args, 0))
+			m.Set("needsUpdate", false)
+			return m
+		}))
+	}
+	return t
+}
+
+// newFirebase fakes the namespaced (v8) SDK: firebase.auth(), .firestore() and
+// .storage() each return a singleton.
+func (h *Harness) newFirebase() js.Value {
+	fb := object()
+
+	h.auth = h.newAuth()
+	h.firestore = h.newFirestore()
+	h.storage = h.newStorage()
+
+	h.method(fb, TargetFirebase, "auth", func(js.Value, []js.Value) interface{} { return h.auth })
+	h.method(fb, TargetFirebase, "firestore", func(js.Value, []js.Value) interface{} { return h.firestore })
+	h.method(fb, TargetFirebase, "storage", func(js.Value, []js.Value) interface{} { return h.storage })
+
+	blob := object()
+	h.method(blob, TargetFirestore, "fromUint8Array", func(_this js.Value, _args []js.Value) interface{} {
+		return h.newBlob(arg(_args, 0))
+	})
+	fb.Get("firestore").Set("Blob", blob)
+	return fb
+}
+
+func (h *Harness) newAuth() js.Value {
+	a := object()
+	h.user = js.Null()
+	a.Set("currentUser", js.Null())
+
+	signIn := func(_this js.Value, _args []js.Value) interface{} {
+		if h.AuthError != "" {
+			e := js.Global().Get("Error").New(fmt.Sprintf("webtest: %s", h.AuthError))
+			e.Set("code", h.AuthError)
+			return reject(e)
+		}
+		h.SetAuthUser(arg(_args, 0).String())
+		return resolve(map[string]interface{}{"user": h.user})
+	}
+
+	h.method(a, TargetAuth, "onAuthStateChanged", func(_this js.Value, _args []js.Value) interface{} {
+		cb := arg(_args, 0)
+		h.mu.Lock()
+		h.providers["auth:stateChanged"] = append(h.providers["auth:stateChanged"], cb)
+		h.mu.Unlock()
+		cb.Invoke(h.user)
+		return h.fn(TargetAuth, "unsubscribe", func(js.Value, []js.Value) interface{} {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			cbs := h.providers["auth:stateChanged"]
+			for i, c := range cbs {
+				if c.Equal(cb) {
+					h.providers["auth:stateChanged"] = append(cbs[:i], cbs[i+1:]...)
+					break
+				}
+			}
+			return nil
+		})
+	})
+	h.method(a, TargetAuth, "createUserWithEmailAndPassword", signIn)
+	h.method(a, TargetAuth, "signInWithEmailAndPassword", signIn)
+	h.method(a, TargetAuth, "signOut", func(js.Value, []js.Value) interface{} {
+		if h.AuthError != "" {
+			e := js.Global().Get("Error").New(fmt.Sprintf("webtest: %s", h.AuthError))
+			e.Set("code", h.AuthError)
+			return reject(e)
+		}
+		h.SetAuthUser("")
+		return resolve(nil)
+	})
+	return a
+}
+
+func (h *Harness) newUser(_uid string) js.Value {
+	u := object()
+	u.Set("uid", _uid)
+	u.Set("email", _uid)
+	u.Set("displayName", _uid)
+	h.method(u, TargetAuth, "getIdToken", func(js.Value, []js.Value) interface{} {
+		return resolve(h.IdToken)
+	})
+	return u
+}
+
+func (h *Harness) newFirestore() js.Value {
+	fs := object()
+	h.docs = object()
+	h.method(fs, TargetFirestore, "doc", func(_this js.Value, _args []js.Value) interface{} {
+		return h.newDocRef(arg(_args, 0).String())
+	})
+	return fs
+}
+
+func (h *Harness) newDocRef(_path string) js.Value {
+	ref := object()
+	ref.Set("path", _path)
+	ref.Set("id", _path)
+	h.method(ref, TargetFirestore, "get", func(js.Value, []js.Value) interface{} {
+		data := h.docs.Get(_path)
+		snap := object()
+		snap.Set("id", _path)
+		snap.Set("exists", !data.IsUndefined())
+		h.method(snap, TargetFirestore, "data", func(js.Value, []js.Value) interface{} {
+			return data
+		})
+		return resolve(snap)
+	})
+	h.method(ref, TargetFirestore, "set", func(_this js.Value, _args []js.Value) interface{} {
+		data, ok := h.stored(arg(_args, 0))
+		if !ok {
+			return reject(js.Global().Get("Error").New("webtest: Unsupported field value: a custom Uint8Array object"))
+		}
+		h.docs.Set(_path, data)
+		return resolve(nil)
+	})
+	h.method(ref, TargetFirestore, "delete", func(js.Value, []js.Value) interface{} {
+		h.docs.Delete(_path)
+		return resolve(nil)
+	})
+	return ref
+}
+
+// stored copies document data the way the SDK stores it: Dates come back as
+// Timestamps, and binary data is only accepted as a Blob.
+func (h *Harness) stored(_v js.Value) (js.Value, bool) {
+	switch {
+	case _v.Type() != js.TypeObject:
+		return _v, true
+	case _v.InstanceOf(js.Global().Get("Date")):
+		return h.newTimestamp(_v.Call("getTime").Float()), true
+	case _v.InstanceOf(js.Global().Get("Uint8Array")), _v.InstanceOf(js.Global().Get("ArrayBuffer")):
+		return js.Undefined(), false
+	case _v.Get("toUint8Array").Type() == js.TypeFunction:
+		return _v, true
+	}
+	r := object()
+	if js.Global().Get("Array").Call("isArray", _v).Bool() {
+		r = array()
+	}
+	keys := js.Global().Get("Object").Call("keys", _v)
+	for i := 0; i < keys.Length(); i++ {
+		k := keys.Index(i).String()
+		v, ok := h.stored(_v.Get(k))
+		if !ok {
+			return js.Undefined(), false
+		}
+		r.Set(k, v)
+	}
+	return r, true
+}
+
+// newTimestamp fakes firebase.firestore.Timestamp.
+func (h *Harness) newTimestamp(_ms float64) js.Value {
+	ts := object()
+	ts.Set("seconds", math.Floor(_ms/1000))
+	ts.Set("nanoseconds", math.Mod(_ms, 1000)*1e6)
+	h.method(ts, TargetFirestore, "toMillis", func(js.Value, []js.Value) interface{} { return _ms })
+	h.method(ts, TargetFirestore, "toDate", func(js.Value, []js.Value) interface{} {
+		return js.Global().Get("Date").New(_ms)
+	})
+	return ts
+}
+
+// newBlob fakes firebase.firestore.Blob holding a copy of _u8.
+func (h *Harness) newBlob(_u8 js.Value) js.Value {
+	b := object()
+	data := js.Global().Get("Uint8Array").New(_u8)
+	h.method(b, TargetFirestore, "toUint8Array", func(js.Value, []js.Value) interface{} {
+		return js.Global().Get("Uint8Array").New(data)
+	})
+	return b
+}
+
+func (h *Harness) newStorage() js.Value {
+	s := object()
+	h.method(s, TargetStorage, "ref", func(_this js.Value, _args []js.Value) interface{} {
+		path := arg(_args, 0).String()
+		ref := object()
+		ref.Set("fullPath", path)
+		h.method(ref, TargetStorage, "put", func(js.Value, []js.Value) interface{} {
+			return resolve(map[string]interface{}{"ref": ref})
+		})
+		h.method(ref, TargetStorage, "getDownloadURL", func(js.Value, []js.Value) interface{} {
+			return resolve(fmt.Sprintf("https://storage.webtest/%s", path))
+		})
+		return ref
+	})
+	return s
+}
+
+// newEthereum fakes an EIP-1193 provider. Handlers registered through `on`
+// are fired with EmitEthereum.
+func (h *Harness) newEthereum() js.Value {
+	eth := object()
+	eth.Set("isMetaMask", true)
+	eth.Set("chainId", "0x1")
+
+	h.method(eth, TargetEthereum, "on", func(_this js.Value, _args []js.Value) interface{} {
+		h.mu.Lock()
+		ev := arg(_args, 0).String()
+		h.providers[ev] = append(h.providers[ev], arg(_args, 1))
+		h.mu.Unlock()
+		return _this
+	})
+	h.method(eth, TargetEthereum, "removeListener", func(_this js.Value, _args []js.Value) interface{} {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		ev := arg(_args, 0).String()
+		for i, cb := range h.providers[ev] {
+			if cb.Equal(arg(_args, 1)) {
+				h.providers[ev] = append(h.providers[ev][:i], h.providers[ev][i+1:]...)
+				break
+			}
+		}
+		return _this
+	})
+	h.method(eth, TargetEthereum, "isConnected", func(js.Value, []js.Value) interface{} {
+		return true
+	})
+	h.method(eth, TargetEthereum, "request", func(_this js.Value, _args []js.Value) interface{} {
+		if h.RequestError != 0 {
+			e := js.Global().Get("Error").New(fmt.Sprintf("webtest: %d", h.RequestError))
+			e.Set("code", h.RequestError)
+			return reject(e)
+		}
+		switch arg(_args, 0).Get("method").String() {
+		case "eth_requestAccounts", "eth_accounts":
+			accounts := make([]interface{}, len(h.Accounts))
+			for i, a := range h.Accounts {
+				accounts[i] = a
+			}
+			return resolve(accounts)
+		case "eth_chainId":
+			return resolve(eth.Get("chainId"))
+		}
+		return resolve(nil)
+	})
+	return eth
+}
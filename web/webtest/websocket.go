@@ -0,0 +1,106 @@
+//+build tinygo wasm,js
+
+package webtest
+
+import (
+	"syscall/js"
+)
+
+const (
+	wsConnecting = 0
+	wsOpen       = 1
+	wsClosed     = 3
+)
+
+// newWebSocket fakes the WebSocket constructor. Sockets never connect on
+// their own: a test plays the server with OpenSocket, SocketMessage and
+// CloseSocket, which unlike the other fakes fire their event from a fresh JS
+// task, as a browser delivers network events, and return once it has run.
+// bufferedAmount is a plain property the test may set to
+// simulate a slow network; send records its argument and leaves it alone.
+func (h *Harness) newWebSocket() js.Func {
+	return h.fn(TargetWebSocket, "constructor", func(_this js.Value, _args []js.Value) interface{} {
+		ws := object()
+		h.eventTarget(ws, TargetWebSocket)
+		ws.Set("url", arg(_args, 0))
+		ws.Set("readyState", wsConnecting)
+		ws.Set("bufferedAmount", 0)
+		ws.Set("protocol", "")
+		ws.Set("binaryType", "blob")
+		h.method(ws, TargetWebSocket, "send", nil)
+		// a client close takes effect at once; like a browser the close
+		// event would come later, and the fake never sends it.
+		h.method(ws, TargetWebSocket, "close", func(_this js.Value, _args []js.Value) interface{} {
+			_this.Set("readyState", wsClosed)
+			return nil
+		})
+
+		h.mu.Lock()
+		h.sockets = append(h.sockets, ws)
+		h.mu.Unlock()
+		return ws
+	})
+}
+
+// Sockets returns every WebSocket constructed so far, oldest first.
+func (h *Harness) Sockets() []js.Value {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]js.Value{}, h.sockets...)
+}
+
+// OpenSocket completes the handshake of _ws with the given subprotocol and
+// fires open.
+func (h *Harness) OpenSocket(_ws js.Value, _protocol string) {
+	serve(func() {
+		_ws.Set("readyState", wsOpen)
+		_ws.Set("protocol", _protocol)
+		dispatch(_ws, h.newEvent("open", false))
+	})
+}
+
+// SocketMessage fires message on _ws. A string arrives as a text frame and a
+// []byte as a binary frame (an ArrayBuffer).
+func (h *Harness) SocketMessage(_ws js.Value, _data interface{}) {
+	serve(func() {
+		evt := h.newEvent("message", false)
+		switch d := _data.(type) {
+		case []byte:
+			a := js.Global().Get("Uint8Array").New(len(d))
+			js.CopyBytesToJS(a, d)
+			evt.Set("data", a.Get("buffer"))
+		default:
+			evt.Set("data", d)
+		}
+		dispatch(_ws, evt)
+	})
+}
+
+// CloseSocket drops _ws from the server side and fires close with the given
+// code, reason and wasClean.
+func (h *Harness) CloseSocket(_ws js.Value, _code int, _reason string, _clean bool) {
+	serve(func() {
+		_ws.Set("readyState", wsClosed)
+		evt := h.newEvent("close", false)
+		evt.Set("code", _code)
+		evt.Set("reason", _reason)
+		evt.Set("wasClean", _clean)
+		dispatch(_ws, evt)
+	})
+}
+
+// serve runs _fn in a new JS task and waits for it. Go code that is in the
+// middle of a call into the fakes (say, adding its listeners) has returned
+// by then, so the listeners _fn fires never wait on it.
+func serve(_fn func()) {
+	done := make(chan struct{})
+	var f js.Func
+	f = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		defer close(done)
+		f.Release()
+		_fn()
+		return nil
+	})
+	js.Global().Call("setTimeout", f, 0)
+	<-done
+}
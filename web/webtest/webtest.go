@@ -0,0 +1,390 @@
+//+build tinygo wasm,js
+
+// Package webtest installs a minimal fake browser on js.Global() so the web,
+// aframe, firebase and metamask packages can be exercised without a browser.
+//
+// It is meant to run under Node through go_js_wasm_exec:
+//
+//	PATH="$PATH:$(go env GOROOT)/lib/wasm" GOOS=js GOARCH=wasm go test ./...
+//
+// A test installs the harness, drives the package under test and then asserts
+// on the recorded calls:
+//
+//	h := webtest.Install()
+//	defer h.Uninstall()
+//
+//	win := web.NewWindow()
+//	el := win.NewElementWithTag("a-entity")
+//	el.SetAttribute("visible", map[string]interface{}{"var": true})
+//
+//	calls := h.CallsTo(webtest.TargetElement, "setAttribute")
+//
+// Fakes call back into Go synchronously (event listeners, promise handlers), so
+// assertions that log or block (t.Log, channel sends without a reader) belong
+// after the call returns, not inside the callback: blocking I/O from a nested
+// js.Func deadlocks under Node.
+package webtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"syscall/js"
+)
+
+const (
+	TargetDocument  = "document"
+	TargetConsole   = "console"
+	TargetElement   = "element"
+	TargetAframe    = "AFRAME"
+	TargetThree     = "THREE"
+	TargetFirebase  = "firebase"
+	TargetAuth      = "auth"
+	TargetFirestore = "firestore"
+	TargetStorage   = "storage"
+	TargetEthereum  = "ethereum"
+	TargetWindow    = "window"
+	TargetLocation  = "location"
+	TargetHistory   = "history"
+	TargetWebSocket = "WebSocket"
+
+	DefaultTitle   = "webtest"
+	DefaultIdToken = "webtest-id-token"
+	DefaultOrigin  = "http://webtest.local"
+)
+
+var (
+	globals = []string{TargetDocument, TargetConsole, TargetAframe, TargetThree, TargetFirebase, TargetEthereum,
+		TargetLocation, TargetHistory, TargetWebSocket, "addEventListener", "removeEventListener", "dispatchEvent", "__listeners", "getComputedStyle"}
+
+	consoleMethods = []string{"log", "debug", "info", "warn", "error", "group", "groupCollapsed", "groupEnd", "time", "timeEnd", "table"}
+)
+
+// Call is one recorded invocation of a fake method.
+type Call struct {
+	Target string
+	Method string
+	This   js.Value
+	Args   []js.Value
+}
+
+// Arg returns the i-th argument, or undefined.
+func (c Call) Arg(_i int) js.Value {
+	if _i < 0 || _i >= len(c.Args) {
+		return js.Undefined()
+	}
+	return c.Args[_i]
+}
+
+// String ...
+func (c Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = describe(a)
+	}
+	return fmt.Sprintf("%s.%s(%s)", c.Target, c.Method, strings.Join(args, ", "))
+}
+
+// Harness owns the fake globals and the record of every call made on them.
+type Harness struct {
+	Document js.Value
+	Head     js.Value
+	Body     js.Value
+	Console  js.Value
+	Aframe   js.Value
+	Three    js.Value
+	Firebase js.Value
+	Ethereum js.Value
+	Location js.Value
+	History  js.Value
+
+	// Accounts is what `ethereum.request({method: "eth_requestAccounts"})`
+	// resolves with.
+	Accounts []string
+	// AuthError, when set, is the firebase error code that sign in, create
+	// user and sign out reject with.
+	AuthError string
+	// IdToken is what `user.getIdToken()` resolves with.
+	IdToken string
+	// RequestError, when non-zero, is the EIP-1193 error code every
+	// `ethereum.request` rejects with.
+	RequestError int
+
+	mu        sync.Mutex
+	calls     []Call
+	funcs     []js.Func
+	saved     map[string]js.Value
+	cookies   []cookie
+	providers map[string][]js.Value
+	nextID    int
+	entries   []historyEntry
+	index     int
+	sockets   []js.Value
+
+	auth      js.Value
+	user      js.Value
+	firestore js.Value
+	storage   js.Value
+	docs      js.Value
+}
+
+type cookie struct {
+	name  string
+	value string
+}
+
+// Install replaces document, console, AFRAME, THREE, firebase, ethereum,
+// WebSocket, location and history on js.Global() with fakes and makes the global object
+// an event target. Uninstall puts the originals back.
+func Install() *Harness {
+	h := &Harness{
+		Accounts:  []string{"0x0000000000000000000000000000000000000001"},
+		IdToken:   DefaultIdToken,
+		saved:     map[string]js.Value{},
+		providers: map[string][]js.Value{},
+	}
+
+	g := js.Global()
+	for _, n := range globals {
+		h.saved[n] = g.Get(n)
+	}
+
+	h.Document = h.newDocument()
+	h.Console = h.newConsole()
+	h.Aframe = h.newAframe()
+	h.Three = h.newThree()
+	h.Firebase = h.newFirebase()
+	h.Ethereum = h.newEthereum()
+
+	g.Set(TargetDocument, h.Document)
+	g.Set(TargetConsole, h.Console)
+	g.Set(TargetAframe, h.Aframe)
+	g.Set(TargetThree, h.Three)
+	g.Set(TargetFirebase, h.Firebase)
+	g.Set(TargetEthereum, h.Ethereum)
+	g.Set(TargetWebSocket, h.newWebSocket())
+	h.installWindow(g)
+
+	return h
+}
+
+// Uninstall restores the original globals and releases every fake js.Func.
+func (h *Harness) Uninstall() {
+	g := js.Global()
+	for n, v := range h.saved {
+		if v.IsUndefined() {
+			g.Delete(n)
+			continue
+		}
+		g.Set(n, v)
+	}
+
+	h.mu.Lock()
+	funcs := h.funcs
+	h.funcs = nil
+	h.mu.Unlock()
+
+	for _, f := range funcs {
+		f.Release()
+	}
+}
+
+// Calls returns every recorded call in order.
+func (h *Harness) Calls() []Call {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := make([]Call, len(h.calls))
+	copy(r, h.calls)
+	return r
+}
+
+// CallsTo returns the recorded calls for one target and method.
+func (h *Harness) CallsTo(_target, _method string) []Call {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := []Call{}
+	for _, c := range h.calls {
+		if c.Target == _target && c.Method == _method {
+			r = append(r, c)
+		}
+	}
+	return r
+}
+
+// Logged returns the first argument (as a string) of every console call made
+// with the given method, e.g. "log" or "error".
+func (h *Harness) Logged(_method string) []string {
+	r := []string{}
+	for _, c := range h.CallsTo(TargetConsole, _method) {
+		r = append(r, describe(c.Arg(0)))
+	}
+	return r
+}
+
+// Reset forgets every recorded call.
+func (h *Harness) Reset() {
+	h.mu.Lock()
+	h.calls = nil
+	h.mu.Unlock()
+}
+
+// AddElement creates an element, appends it to document.body and returns it.
+func (h *Harness) AddElement(_tag, _id string) js.Value {
+	el := h.newElement(_tag)
+	el.Set("id", _id)
+	appendChild(h.Body, el)
+	return el
+}
+
+// Element returns the element with the given id, or null.
+func (h *Harness) Element(_id string) js.Value {
+	return h.Document.Call("getElementById", _id)
+}
+
+// Cookies returns the current cookie jar as "name=value" pairs.
+func (h *Harness) Cookies() map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := map[string]string{}
+	for _, c := range h.cookies {
+		r[c.name] = c.value
+	}
+	return r
+}
+
+// EmitEthereum calls every handler registered with `ethereum.on(_event, ...)`.
+func (h *Harness) EmitEthereum(_event string, _args ...interface{}) {
+	h.mu.Lock()
+	cbs := append([]js.Value{}, h.providers[_event]...)
+	h.mu.Unlock()
+	for _, cb := range cbs {
+		cb.Invoke(_args...)
+	}
+}
+
+// SetAuthUser sets (or, with an empty uid, clears) firebase.auth().currentUser
+// and notifies any onAuthStateChanged observers.
+func (h *Harness) SetAuthUser(_uid string) {
+	u := js.Null()
+	if _uid != "" {
+		u = h.newUser(_uid)
+	}
+	h.user = u
+	h.auth.Set("currentUser", u)
+	h.mu.Lock()
+	cbs := append([]js.Value{}, h.providers["auth:stateChanged"]...)
+	h.mu.Unlock()
+	for _, cb := range cbs {
+		cb.Invoke(u)
+	}
+}
+
+// Doc returns the data stored under a firestore document path, or undefined.
+func (h *Harness) Doc(_path string) js.Value {
+	return h.docs.Get(_path)
+}
+
+// SetDoc stores data under a firestore document path.
+func (h *Harness) SetDoc(_path string, _data interface{}) {
+	h.docs.Set(_path, _data)
+}
+
+///////////////////////////////////// FAKE PLUMBING /////////////////////////////////////
+
+func (h *Harness) record(_target, _method string, _this js.Value, _args []js.Value) {
+	args := make([]js.Value, len(_args))
+	copy(args, _args)
+	h.mu.Lock()
+	h.calls = append(h.calls, Call{Target: _target, Method: _method, This: _this, Args: args})
+	h.mu.Unlock()
+}
+
+func (h *Harness) fn(_target, _method string, _f func(js.Value, []js.Value) interface{}) js.Func {
+	f := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		h.record(_target, _method, _this, _args)
+		if _f == nil {
+			return nil
+		}
+		return _f(_this, _args)
+	})
+	h.mu.Lock()
+	h.funcs = append(h.funcs, f)
+	h.mu.Unlock()
+	return f
+}
+
+func (h *Harness) method(_obj js.Value, _target, _method string, _f func(js.Value, []js.Value) interface{}) {
+	_obj.Set(_method, h.fn(_target, _method, _f))
+}
+
+func (h *Harness) accessor(_obj js.Value, _name string, _get func() interface{}, _set func(js.Value)) {
+	get := js.FuncOf(func(js.Value, []js.Value) interface{} { return _get() })
+	set := js.FuncOf(func(_this js.Value, _args []js.Value) interface{} {
+		_set(arg(_args, 0))
+		return nil
+	})
+	h.mu.Lock()
+	h.funcs = append(h.funcs, get, set)
+	h.mu.Unlock()
+
+	js.Global().Get("Object").Call("defineProperty", _obj, _name, map[string]interface{}{
+		"get":          get,
+		"set":          set,
+		"configurable": true,
+	})
+}
+
+func (h *Harness) id() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	return h.nextID
+}
+
+func object() js.Value {
+	return js.Global().Get("Object").New()
+}
+
+func array() js.Value {
+	return js.Global().Get("Array").New()
+}
+
+func resolve(_v interface{}) js.Value {
+	return js.Global().Get("Promise").Call("resolve", _v)
+}
+
+func reject(_v interface{}) js.Value {
+	return js.Global().Get("Promise").Call("reject", _v)
+}
+
+func arg(_args []js.Value, _i int) js.Value {
+	if _i >= len(_args) {
+		return js.Undefined()
+	}
+	return _args[_i]
+}
+
+func describe(_v js.Value) string {
+	switch _v.Type() {
+	case js.TypeString:
+		return _v.String()
+	case js.TypeUndefined:
+		return "undefined"
+	case js.TypeNull:
+		return "null"
+	case js.TypeObject:
+		if s, err := stringify(_v); err == nil {
+			return s
+		}
+	}
+	return js.Global().Call("String", _v).String()
+}
+
+func stringify(_v js.Value) (s string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return js.Global().Get("JSON").Call("stringify", _v).String(), nil
+}
@@ -0,0 +1,159 @@
+//+build tinygo wasm,js
+
+package webtest
+
+import (
+	"net/url"
+	"syscall/js"
+)
+
+type historyEntry struct {
+	url   *url.URL
+	state js.Value
+}
+
+// installWindow turns js.Global() into a window-like event target and adds
+// fake location and history objects. History changes only touch the fake
+// location; back, forward and SetURL fire popstate (and hashchange) on the
+// window synchronously.
+func (h *Harness) installWindow(_g js.Value) {
+	h.eventTarget(_g, TargetWindow)
+
+	start, _ := url.Parse(DefaultOrigin + "/")
+	h.entries = []historyEntry{{url: start, state: js.Null()}}
+	h.index = 0
+
+	h.Location = object()
+	h.method(h.Location, TargetLocation, "replace", func(_this js.Value, _args []js.Value) interface{} {
+		h.entries[h.index] = historyEntry{url: h.resolve(arg(_args, 0).String()), state: js.Null()}
+		h.syncLocation()
+		return nil
+	})
+	h.method(h.Location, TargetLocation, "assign", func(_this js.Value, _args []js.Value) interface{} {
+		h.SetURL(arg(_args, 0).String())
+		return nil
+	})
+
+	h.History = object()
+	h.method(h.History, TargetHistory, "pushState", func(_this js.Value, _args []js.Value) interface{} {
+		h.entries = append(h.entries[:h.index+1], historyEntry{url: h.resolve(urlArg(_args)), state: arg(_args, 0)})
+		h.index++
+		h.syncLocation()
+		return nil
+	})
+	h.method(h.History, TargetHistory, "replaceState", func(_this js.Value, _args []js.Value) interface{} {
+		h.entries[h.index] = historyEntry{url: h.resolve(urlArg(_args)), state: arg(_args, 0)}
+		h.syncLocation()
+		return nil
+	})
+	h.method(h.History, TargetHistory, "back", func(js.Value, []js.Value) interface{} {
+		h.traverse(-1)
+		return nil
+	})
+	h.method(h.History, TargetHistory, "forward", func(js.Value, []js.Value) interface{} {
+		h.traverse(1)
+		return nil
+	})
+	h.method(h.History, TargetHistory, "go", func(_this js.Value, _args []js.Value) interface{} {
+		if d := arg(_args, 0); d.Type() == js.TypeNumber {
+			h.traverse(d.Int())
+		}
+		return nil
+	})
+	h.syncLocation()
+
+	// there are no stylesheets, so the computed style is the inline style
+	h.method(_g, TargetWindow, "getComputedStyle", func(_this js.Value, _args []js.Value) interface{} {
+		return arg(_args, 0).Get("style")
+	})
+
+	_g.Set(TargetLocation, h.Location)
+	_g.Set(TargetHistory, h.History)
+}
+
+// URL returns the current fake location.href.
+func (h *Harness) URL() string {
+	return h.entries[h.index].url.String()
+}
+
+// SetURL simulates the user navigating within the document (e.g. editing the
+// fragment): a new history entry is pushed, popstate fires and, when only the
+// fragment changed, hashchange fires as well.
+func (h *Harness) SetURL(_u string) {
+	prev := h.entries[h.index].url
+	next := h.resolve(_u)
+	h.entries = append(h.entries[:h.index+1], historyEntry{url: next, state: js.Null()})
+	h.index++
+	h.syncLocation()
+
+	h.EmitWindow("popstate", map[string]interface{}{"state": js.Null()})
+	if prev.Fragment != next.Fragment {
+		h.EmitWindow("hashchange", map[string]interface{}{"oldURL": prev.String(), "newURL": next.String()})
+	}
+}
+
+// EmitWindow dispatches an event of type _typ on the window with _props copied
+// onto the event object.
+func (h *Harness) EmitWindow(_typ string, _props map[string]interface{}) {
+	evt := object()
+	evt.Set("type", _typ)
+	for k, v := range _props {
+		evt.Set(k, v)
+	}
+	dispatch(js.Global(), evt)
+}
+
+func (h *Harness) traverse(_delta int) {
+	i := h.index + _delta
+	if _delta == 0 || i < 0 || i >= len(h.entries) {
+		return
+	}
+	prev := h.entries[h.index].url
+	h.index = i
+	h.syncLocation()
+
+	e := h.entries[h.index]
+	h.EmitWindow("popstate", map[string]interface{}{"state": e.state})
+	if prev.Fragment != e.url.Fragment {
+		h.EmitWindow("hashchange", map[string]interface{}{"oldURL": prev.String(), "newURL": e.url.String()})
+	}
+}
+
+func (h *Harness) resolve(_u string) *url.URL {
+	base := h.entries[h.index].url
+	ref, err := url.Parse(_u)
+	if err != nil {
+		return base
+	}
+	return base.ResolveReference(ref)
+}
+
+func (h *Harness) syncLocation() {
+	e := h.entries[h.index]
+	u := e.url
+	h.Location.Set("href", u.String())
+	h.Location.Set("origin", u.Scheme+"://"+u.Host)
+	h.Location.Set("protocol", u.Scheme+":")
+	h.Location.Set("host", u.Host)
+	h.Location.Set("hostname", u.Hostname())
+	h.Location.Set("port", u.Port())
+	h.Location.Set("pathname", u.EscapedPath())
+	h.Location.Set("search", prefixed("?", u.RawQuery))
+	h.Location.Set("hash", prefixed("#", u.EscapedFragment()))
+	h.History.Set("state", e.state)
+	h.History.Set("length", len(h.entries))
+}
+
+func urlArg(_args []js.Value) string {
+	if u := arg(_args, 2); u.Type() == js.TypeString {
+		return u.String()
+	}
+	return ""
+}
+
+func prefixed(_p, _s string) string {
+	if _s == "" {
+		return ""
+	}
+	return _p + _s
+}
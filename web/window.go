@@ -4,7 +4,6 @@ package web
 
 import (
 	"fmt"
-	"strings"
 	"syscall/js"
 )
 
@@ -13,7 +12,6 @@ const (
 	document = "document"
 	title    = "title"
 	worker   = "worker"
-	cookie   = "cookie"
 
 	WINDOW__location           = "location"
 	FUNCTION__location_replace = "replace"
@@ -21,10 +19,12 @@ const (
 	FUNCTION__form_onsubmit       = "onsubmit"
 	FUNCTION__form_preventDefault = "preventDefault"
 
-	function__createElement  = "createElement"
-	function__getElementById = "getElementById"
-	function__querySelector  = "querySelector"
-	function__remove         = "remove"
+	function__createElement    = "createElement"
+	function__createTextNode   = "createTextNode"
+	function__getElementById   = "getElementById"
+	function__querySelector    = "querySelector"
+	function__querySelectorAll = "querySelectorAll"
+	function__remove           = "remove"
 )
 
 type Window struct {
@@ -62,12 +62,18 @@ func NewWindow() *Window {
 
 // NewElementWithTag ...
 func (w *Window) NewElementWithTag(_tag string) *Element {
-	elem := NewElement(js.ValueOf(nil))
 	if err := ValidJSValue(document, w.document); err != nil {
-		return elem
+		return NewElement(js.ValueOf(nil))
 	}
-	elem.Value = w.document.Call(function__createElement, _tag)
-	return elem
+	return NewElement(w.document.Call(function__createElement, _tag))
+}
+
+// NewTextNode creates a detached text node. The returned Element has no Tag.
+func (w *Window) NewTextNode(_text string) *Element {
+	if err := ValidJSValue(document, w.document); err != nil {
+		return NewElement(js.ValueOf(nil))
+	}
+	return NewElement(w.document.Call(function__createTextNode, _text))
 }
 
 // NewElementWithValue ...
@@ -97,7 +103,7 @@ func (w *Window) GetGlobal(_name string) (js.Value, error) {
 	}
 	tv := w.value.Get(_name)
 	if err = ValidJSValue(_name, tv); err != nil {
-		return js.ValueOf(nil), fmt.Errorf("[window] [GetGlobal] [error]: %v", _name, err)
+		return js.ValueOf(nil), fmt.Errorf("[window] [GetGlobal] [%s] [error]: %v", _name, err)
 	}
 	return tv, nil
 }
@@ -120,55 +126,6 @@ func (w *Window) GetValueById(_id string) (js.Value, error) {
 	return w.document.Call(function__getElementById, _id), nil
 }
 
-// GetCookie ...
-func (w *Window) GetCookie(_name string) (string, error) {
-	err := ValidJSValue(document, w.document)
-	if err != nil {
-		return "", fmt.Errorf("[window] [GetCookie] [error]: %v", err)
-	}
-	tv := w.document.Get(cookie)
-	if err = ValidJSValue(cookie, tv); err != nil {
-		return "", fmt.Errorf("[window] [GetCookie] [error]: %v", err)
-	}
-	// wp.Logger.LogString(fmt.Sprintf("[GetCookie] cookies: %s", ck.String()))
-
-	var val string
-	cks := strings.Split(tv.String(), ";")
-	for _, c := range cks {
-		if c != "" {
-			// wp.Logger.LogString(fmt.Sprintf("[GetCookie] cookie [%s]", c))
-			v := strings.Split(c, "=")
-			key := strings.TrimSpace(v[0])
-			value := strings.TrimSpace(v[1])
-			// wp.Logger.LogString(fmt.Sprintf("[GetCookie] cookie [%s]=%s", key, value))
-			if key == _name {
-				val = value
-				break
-			}
-		}
-	}
-
-	return val, nil
-}
-
-// SetCookie ...
-func (w *Window) SetCookie(_name, _val string) error {
-	if err := ValidJSValue(document, w.document); err != nil {
-		return fmt.Errorf("[window] [SetCookie] [error]: %v", err)
-	}
-	w.document.Set(cookie, fmt.Sprintf("%s=%s", _name, _val))
-	// if wp.debug {
-	// 	c := wp.document.Get("cookie")
-	// 	if c.IsUndefined() || c.IsNull() {
-	// 		wp.Logger.LogError(errors.New("goweb_error |DEBUG| 'cookie' js.Value undefined/null"))
-	// 		return
-	// 	}
-	//
-	// 	wp.Logger.LogString("goweb |DEBUG| current cookies: " + c.String())
-	// }
-	return nil
-}
-
 // GetElementByTag returns the first element in the document with the given tag (e.g. <div>, <a-entity>, etc.)
 func (w *Window) GetElementByTag(_tag string) (*Element, error) {
 	err := ValidJSValue(document, w.document)
@@ -182,6 +139,35 @@ func (w *Window) GetElementByTag(_tag string) (*Element, error) {
 	return NewElement(tv), nil
 }
 
+// QuerySelector returns the first element in the document matching the CSS
+// selector, or nil when nothing matches.
+func (w *Window) QuerySelector(_sel string) (*Element, error) {
+	if err := ValidJSValue(document, w.document); err != nil {
+		return nil, fmt.Errorf("[window] [QuerySelector] [error]: %v", err)
+	}
+	tv, err := callJS(w.document, function__querySelector, _sel)
+	if err != nil {
+		return nil, fmt.Errorf("[window] [QuerySelector] [%s] [error]: %v", _sel, err)
+	}
+	if ValidJSValue(_sel, tv) != nil {
+		return nil, nil
+	}
+	return NewElement(tv), nil
+}
+
+// QuerySelectorAll returns every element in the document matching the CSS
+// selector, in document order.
+func (w *Window) QuerySelectorAll(_sel string) ([]*Element, error) {
+	if err := ValidJSValue(document, w.document); err != nil {
+		return nil, fmt.Errorf("[window] [QuerySelectorAll] [error]: %v", err)
+	}
+	tv, err := callJS(w.document, function__querySelectorAll, _sel)
+	if err != nil {
+		return nil, fmt.Errorf("[window] [QuerySelectorAll] [%s] [error]: %v", _sel, err)
+	}
+	return elementList(tv), nil
+}
+
 // RemoveElementById ...
 func (w *Window) RemoveElementById(_id string) {
 	err := ValidJSValue(document, w.document)
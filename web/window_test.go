@@ -0,0 +1,117 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/zeptotenshi/wasmGo/web"
+	"github.com/zeptotenshi/wasmGo/web/webtest"
+)
+
+func TestNewWindow(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	win := web.NewWindow()
+	if win.Title != webtest.DefaultTitle {
+		t.Errorf("Title = %q, want %q", win.Title, webtest.DefaultTitle)
+	}
+	if win.Logger.Prefix != webtest.DefaultTitle {
+		t.Errorf("Logger.Prefix = %q, want %q", win.Logger.Prefix, webtest.DefaultTitle)
+	}
+}
+
+func TestWindowCookies(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	win := web.NewWindow()
+	if err := win.SetCookie("session", "a=b c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := win.SetCookie("theme", "dark"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := win.GetCookie("session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "a=b c" {
+		t.Errorf("GetCookie(session) = %q, want %q", got, "a=b c")
+	}
+	if got, _ = win.GetCookie("missing"); got != "" {
+		t.Errorf("GetCookie(missing) = %q, want \"\"", got)
+	}
+
+	if err = win.DeleteCookie("theme", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := h.Cookies()["theme"]; ok {
+		t.Errorf("theme cookie still set after DeleteCookie: %v", h.Cookies())
+	}
+	cks, err := win.Cookies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cks) != 1 {
+		t.Errorf("Cookies() = %v, want only session", cks)
+	}
+}
+
+func TestWindowElements(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	h.AddElement("a-scene", "scene")
+	win := web.NewWindow()
+
+	el := win.NewElementWithTag("div")
+	if el.Tag != "DIV" {
+		t.Errorf("Tag = %q, want DIV", el.Tag)
+	}
+	if n := len(h.CallsTo(webtest.TargetDocument, "createElement")); n != 1 {
+		t.Errorf("createElement called %d times, want 1", n)
+	}
+
+	if got := win.ElementById("scene"); got == nil || got.ID != "scene" {
+		t.Errorf("ElementById(scene) = %v", got)
+	}
+	if got := win.ElementById("missing"); got != nil {
+		t.Errorf("ElementById(missing) = %v, want nil", got)
+	}
+	if errs := h.Logged("error"); len(errs) != 1 {
+		t.Errorf("logged errors = %v, want one for the missing id", errs)
+	}
+
+	if _, err := win.GetElementByTag("a-scene"); err != nil {
+		t.Errorf("GetElementByTag(a-scene): %v", err)
+	}
+	if _, err := win.GetElementByTag("a-sky"); err == nil {
+		t.Error("GetElementByTag(a-sky): want an error")
+	}
+}
+
+func TestWindowCookieShadowed(t *testing.T) {
+	h := webtest.Install()
+	defer h.Uninstall()
+
+	// a cookie set on /app and one on / list the /app one first
+	get := js.FuncOf(func(js.Value, []js.Value) interface{} { return "session=app; theme=dark; session=root" })
+	defer get.Release()
+	js.Global().Get("Object").Call("defineProperty", h.Document, "cookie", map[string]interface{}{"get": get, "configurable": true})
+
+	win := web.NewWindow()
+	if got, _ := win.GetCookie("session"); got != "app" {
+		t.Errorf("GetCookie(session) = %q, want the first, most specific one", got)
+	}
+	cks, err := win.Cookies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cks["session"] != "app" || cks["theme"] != "dark" {
+		t.Errorf("Cookies() = %v", cks)
+	}
+}
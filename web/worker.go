@@ -0,0 +1,519 @@
+//+build tinygo wasm,js
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"syscall/js"
+)
+
+const (
+	worker__constructor = "Worker"
+
+	function__postMessage = "postMessage"
+	function__terminate   = "terminate"
+	function__start       = "start"
+
+	EVENT__message      = "message"
+	EVENT__messageerror = "messageerror"
+	EVENT__error        = "error"
+
+	message__data  = "data"
+	message__ports = "ports"
+
+	// envelope fields; messages without envelope__tag are plain messages
+	// from JS code and arrive untouched.
+	envelope__tag   = "__goweb"
+	envelope__id    = "id"
+	envelope__kind  = "kind"
+	envelope__data  = "data"
+	envelope__error = "error"
+
+	envelope__request  = "req"
+	envelope__response = "res"
+	envelope__failure  = "err"
+
+	WORKER__classic = "classic"
+	WORKER__module  = "module"
+)
+
+var (
+	GOWEB_ERROR_PORT_CLOSED  = errors.New("message port closed")
+	GOWEB_ERROR_NO_HANDLER   = errors.New("no request handler registered")
+	GOWEB_ERROR_NOT_A_WORKER = errors.New("not running inside a worker")
+)
+
+// WorkerOptions are passed to the Worker constructor.
+type WorkerOptions struct {
+	// Type is WORKER__classic (default) or WORKER__module.
+	Type string
+	Name string
+}
+
+// Transfer wraps a value posted with a transfer list: the listed
+// ArrayBuffers (or MessagePorts, ImageBitmaps, ...) are moved instead of
+// copied and become unusable on the sending side. A request handler may
+// return a Transfer to move its response.
+type Transfer struct {
+	Data interface{}
+	List []js.Value
+}
+
+// NewArrayBuffer copies _b into a new ArrayBuffer, ready to be transferred.
+func NewArrayBuffer(_b []byte) js.Value {
+	return uint8ArrayOf(_b).Get(property__buffer)
+}
+
+// Message is one message received on a Port.
+type Message struct {
+	// Data is the posted value, after structured clone.
+	Data js.Value
+	// Ports are the MessagePorts transferred with the message, untouched:
+	// post them on, or wrap one with Port to talk over it.
+	Ports []js.Value
+
+	id int
+}
+
+// Decode unmarshals Data into _v.
+func (m Message) Decode(_v interface{}) error {
+	if err := Unmarshal(m.Data, _v); err != nil {
+		return fmt.Errorf("[message] [Decode] [error]: %v", err)
+	}
+	return nil
+}
+
+// Port wraps the _i-th transferred MessagePort, starting it and listening for
+// messages. Each call makes a new Port; the caller owns it and must Close it.
+// It returns nil when there is no such port.
+func (m Message) Port(_i int) *Port {
+	if _i < 0 || _i >= len(m.Ports) {
+		return nil
+	}
+	return NewPort(m.Ports[_i])
+}
+
+// Bytes copies Data out when it is an ArrayBuffer or typed array.
+func (m Message) Bytes() []byte {
+	if m.Data.Type() != js.TypeObject || m.Data.Get(property__byteLength).Type() != js.TypeNumber {
+		return nil
+	}
+	return bytesOf(m.Data)
+}
+
+// RemoteError is an error returned by the handler on the other side of a
+// Request.
+type RemoteError struct {
+	Message string
+}
+
+// Error ...
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("[port] [remote] [error]: %s", e.Message)
+}
+
+// Port exchanges Go values with anything that has postMessage and message
+// events: a Worker, the worker global scope, a MessagePort or a
+// BroadcastChannel. Values are converted with Marshal (js.Values pass through
+// as is) and then structured-cloned by the browser.
+//
+// Plain messages arrive on Messages; Request/Handle add correlation IDs on top
+// for request/response calls:
+//
+//	// main thread
+//	wk, _ := web.NewWorker("worker.js")
+//	var out Mesh
+//	msg, err := wk.Request(ctx, Job{Seed: 42})
+//	err = msg.Decode(&out)
+//
+//	// worker (a Go program started from worker.js)
+//	self, _ := web.NewWindow().WorkerScope()
+//	self.Handle(func(_m web.Message) (interface{}, error) {
+//		var j Job
+//		if err := _m.Decode(&j); err != nil {
+//			return nil, err
+//		}
+//		return build(j), nil
+//	})
+//	select {}
+type Port struct {
+	name  string
+	value js.Value
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan Message
+	handler func(Message) (interface{}, error)
+	queue   []Message
+	notify  chan struct{}
+	done    chan struct{}
+	out     chan Message
+	closed  bool
+	offs    []func()
+}
+
+// NewPort wraps a MessagePort (or any postMessage target) and starts
+// listening for messages. The Port holds a listener and a goroutine until
+// Close.
+func NewPort(_v js.Value) *Port {
+	return newPort("port", _v)
+}
+
+func newPort(_name string, _v js.Value) *Port {
+	p := &Port{
+		name:    _name,
+		value:   _v,
+		pending: map[int]chan Message{},
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		out:     make(chan Message),
+	}
+	if ValidJSValue(_name, _v) != nil {
+		p.closed = true
+		close(p.done)
+		close(p.out)
+		return p
+	}
+	go p.pump()
+	p.offs = append(p.offs, listen(_v, EVENT__message, p.receive, ListenerOptions{}))
+	// MessagePorts only deliver to addEventListener after start.
+	if _v.Get(function__start).Type() == js.TypeFunction {
+		_v.Call(function__start)
+	}
+	return p
+}
+
+// NewMessageChannel returns both ends of a new MessageChannel. Post one end to
+// a worker (in a Transfer) to give it a private line; the receiver wraps it
+// with Message.Port.
+func NewMessageChannel() (*Port, *Port) {
+	mc := js.Global().Get("MessageChannel").New()
+	return NewPort(mc.Get("port1")), NewPort(mc.Get("port2"))
+}
+
+// Value returns the wrapped JS object, e.g. to put a MessagePort in a
+// Transfer list.
+func (p *Port) Value() js.Value {
+	return p.value
+}
+
+// String ...
+func (p *Port) String() string {
+	return fmt.Sprintf("[%s]port", p.name)
+}
+
+// Post sends _v. A Transfer moves its List instead of copying it; _transfer
+// is appended to that list.
+func (p *Port) Post(_v interface{}, _transfer ...js.Value) error {
+	data, list := transferArgs(_v, _transfer)
+	if err := p.post(data, list); err != nil {
+		return fmt.Errorf("%s [Post] [error]: %w", p, err)
+	}
+	return nil
+}
+
+// Request posts _v with a fresh correlation ID and waits for the reply from
+// the other side's Handle. Like Await it must not be called directly inside a
+// js.Func callback.
+func (p *Port) Request(_ctx context.Context, _v interface{}, _transfer ...js.Value) (Message, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return Message{}, fmt.Errorf("%s [Request] [error]: %w", p, GOWEB_ERROR_PORT_CLOSED)
+	}
+	p.nextID++
+	id := p.nextID
+	ch := make(chan Message, 1)
+	p.pending[id] = ch
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+	}()
+
+	data, list := transferArgs(_v, _transfer)
+	if err := p.post(envelope(id, envelope__request, data), list); err != nil {
+		return Message{}, fmt.Errorf("%s [Request] [error]: %v", p, err)
+	}
+
+	select {
+	case m, ok := <-ch:
+		if !ok {
+			return Message{}, fmt.Errorf("%s [Request] [error]: %w", p, GOWEB_ERROR_PORT_CLOSED)
+		}
+		if m.id < 0 {
+			return Message{}, &RemoteError{Message: m.Data.String()}
+		}
+		return m, nil
+	case <-_ctx.Done():
+		return Message{}, fmt.Errorf("%s [Request] [error]: %v", p, _ctx.Err())
+	}
+}
+
+// Handle answers Requests from the other side. _fn runs in its own goroutine
+// per request, so it may Await; its result is posted back (a Transfer moves
+// its List) and an error is returned to the caller as a *RemoteError.
+func (p *Port) Handle(_fn func(Message) (interface{}, error)) {
+	p.mu.Lock()
+	p.handler = _fn
+	p.mu.Unlock()
+}
+
+// Messages returns the channel of plain (non request/response) messages. It is
+// closed by Close, dropping anything still queued. Messages are queued from
+// the moment the Port is created and while nobody reads, so the JS event loop
+// never blocks on a slow reader.
+func (p *Port) Messages() <-chan Message {
+	return p.out
+}
+
+// OnError listens for messageerror (a message that could not be
+// deserialized) and, on a Worker, for uncaught errors in the worker script.
+func (p *Port) OnError(_cb func(error)) func() {
+	if ValidJSValue(p.name, p.value) != nil {
+		return func() {}
+	}
+	cb := func(_e Event) {
+		msg := _e.Type()
+		if m := _e.Value.Get("message"); m.Type() == js.TypeString {
+			msg = m.String()
+		}
+		_cb(fmt.Errorf("%s [%s] [error]: %s", p, _e.Type(), msg))
+	}
+	offs := []func(){listen(p.value, EVENT__messageerror, cb, ListenerOptions{})}
+	if p.name == worker {
+		offs = append(offs, listen(p.value, EVENT__error, cb, ListenerOptions{}))
+	}
+	return func() {
+		for _, off := range offs {
+			off()
+		}
+	}
+}
+
+// Close stops listening, fails pending Requests and closes Messages. It does
+// not terminate a worker; see Worker.Terminate.
+func (p *Port) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	offs := p.offs
+	p.offs = nil
+	for id, ch := range p.pending {
+		close(ch)
+		delete(p.pending, id)
+	}
+	p.queue = nil
+	close(p.done)
+	p.mu.Unlock()
+
+	for _, off := range offs {
+		off()
+	}
+}
+
+func (p *Port) post(_data js.Value, _list []js.Value) error {
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return GOWEB_ERROR_PORT_CLOSED
+	}
+	var err error
+	if len(_list) > 0 {
+		l := make([]interface{}, len(_list))
+		for i, v := range _list {
+			l[i] = v
+		}
+		_, err = callJS(p.value, function__postMessage, _data, l)
+	} else {
+		_, err = callJS(p.value, function__postMessage, _data)
+	}
+	return err
+}
+
+// receive runs in the message listener and must not block.
+func (p *Port) receive(_e Event) {
+	data := _e.Value.Get(message__data)
+	m := Message{Data: data, Ports: messagePorts(_e.Value.Get(message__ports))}
+
+	if data.Type() != js.TypeObject || !data.Get(envelope__tag).Truthy() {
+		p.enqueue(m)
+		return
+	}
+
+	id := data.Get(envelope__id).Int()
+	m.Data = data.Get(envelope__data)
+	switch data.Get(envelope__kind).String() {
+	case envelope__request:
+		p.mu.Lock()
+		h := p.handler
+		p.mu.Unlock()
+		go p.respond(id, h, m)
+	case envelope__response, envelope__failure:
+		if data.Get(envelope__kind).String() == envelope__failure {
+			m = Message{Data: data.Get(envelope__error), id: -1}
+		}
+		p.mu.Lock()
+		ch, ok := p.pending[id]
+		delete(p.pending, id)
+		p.mu.Unlock()
+		if ok {
+			ch <- m
+		}
+	}
+}
+
+func (p *Port) respond(_id int, _h func(Message) (interface{}, error), _m Message) {
+	if _h == nil {
+		p.fail(_id, GOWEB_ERROR_NO_HANDLER)
+		return
+	}
+	r, err := _h(_m)
+	if err != nil {
+		p.fail(_id, err)
+		return
+	}
+	data, list := transferArgs(r, nil)
+	if err = p.post(envelope(_id, envelope__response, data), list); err != nil {
+		p.fail(_id, err)
+	}
+}
+
+func (p *Port) fail(_id int, _err error) {
+	env := envelope(_id, envelope__failure, js.Null())
+	env.Set(envelope__error, _err.Error())
+	p.post(env, nil)
+}
+
+func (p *Port) enqueue(_m Message) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.queue = append(p.queue, _m)
+	p.mu.Unlock()
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pump moves queued messages to the Messages channel until Close, which
+// also unblocks a send nobody is reading.
+func (p *Port) pump() {
+	defer close(p.out)
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.mu.Unlock()
+			select {
+			case <-p.notify:
+				continue
+			case <-p.done:
+				return
+			}
+		}
+		m := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+		select {
+		case p.out <- m:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+func envelope(_id int, _kind string, _data js.Value) js.Value {
+	env := js.Global().Get(object__constructor).New()
+	env.Set(envelope__tag, true)
+	env.Set(envelope__id, _id)
+	env.Set(envelope__kind, _kind)
+	env.Set(envelope__data, _data)
+	return env
+}
+
+func transferArgs(_v interface{}, _transfer []js.Value) (js.Value, []js.Value) {
+	switch t := _v.(type) {
+	case Transfer:
+		return Marshal(t.Data), append(append([]js.Value{}, t.List...), _transfer...)
+	case *Transfer:
+		return Marshal(t.Data), append(append([]js.Value{}, t.List...), _transfer...)
+	}
+	return Marshal(_v), _transfer
+}
+
+func messagePorts(_v js.Value) []js.Value {
+	if ValidJSValue(message__ports, _v) != nil || _v.Length() == 0 {
+		return nil
+	}
+	r := make([]js.Value, _v.Length())
+	for i := range r {
+		r[i] = _v.Index(i)
+	}
+	return r
+}
+
+// Worker is a dedicated Web Worker. It embeds the Port used to talk to it.
+type Worker struct {
+	URL string
+
+	*Port
+}
+
+// NewWorker starts a worker running _url. For a Go worker the script loads
+// wasm_exec.js and instantiates the worker's wasm module, whose main then
+// calls Window.WorkerScope.
+func NewWorker(_url string, _opts ...WorkerOptions) (*Worker, error) {
+	ctor := js.Global().Get(worker__constructor)
+	if err := ValidJSValue(worker__constructor, ctor); err != nil {
+		return nil, fmt.Errorf("[worker] [NewWorker] [error]: %v", err)
+	}
+	opts := map[string]interface{}{}
+	if len(_opts) > 0 {
+		if _opts[0].Type != "" {
+			opts["type"] = _opts[0].Type
+		}
+		if _opts[0].Name != "" {
+			opts["name"] = _opts[0].Name
+		}
+	}
+
+	v, err := newJS(ctor, _url, opts)
+	if err != nil {
+		return nil, fmt.Errorf("[worker] [NewWorker] [%s] [error]: %v", _url, err)
+	}
+	return &Worker{URL: _url, Port: newPort(worker, v)}, nil
+}
+
+// Terminate stops the worker immediately and closes its Port.
+func (wk *Worker) Terminate() {
+	if ValidJSValue(worker, wk.value) == nil {
+		wk.value.Call(function__terminate)
+	}
+	wk.Close()
+}
+
+// IsWorker reports whether the program runs in a worker (no document).
+func (w *Window) IsWorker() bool {
+	return ValidJSValue(document, w.document) != nil
+}
+
+// WorkerScope returns a Port on the worker global scope for talking to the
+// page that created the worker.
+func (w *Window) WorkerScope() (*Port, error) {
+	if !w.IsWorker() || w.value.Get(function__postMessage).Type() != js.TypeFunction {
+		return nil, fmt.Errorf("[window] [WorkerScope] [error]: %v", GOWEB_ERROR_NOT_A_WORKER)
+	}
+	return newPort(worker, w.value), nil
+}
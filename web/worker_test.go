@@ -0,0 +1,166 @@
+//+build tinygo wasm,js
+
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/zeptotenshi/wasmGo/web"
+)
+
+// next reads one plain message from _p.
+func next(t *testing.T, _p *web.Port) web.Message {
+	t.Helper()
+	select {
+	case m, ok := <-_p.Messages():
+		if !ok {
+			t.Fatal("Messages closed")
+		}
+		return m
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a message")
+	}
+	return web.Message{}
+}
+
+func TestPortMessages(t *testing.T) {
+	a, b := web.NewMessageChannel()
+	defer a.Close()
+	defer b.Close()
+
+	type point struct {
+		X int `js:"x"`
+		Y int `js:"y"`
+	}
+	for i := 0; i < 3; i++ {
+		if err := a.Post(point{X: i, Y: -i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		var p point
+		if err := next(t, b).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		if p.X != i || p.Y != -i {
+			t.Errorf("message %d = %+v", i, p)
+		}
+	}
+
+	b.Close()
+	if _, ok := <-b.Messages(); ok {
+		t.Error("Messages still open after Close")
+	}
+	if err := b.Post(1); !errors.Is(err, web.GOWEB_ERROR_PORT_CLOSED) {
+		t.Errorf("Post after Close = %v", err)
+	}
+}
+
+func TestPortRequest(t *testing.T) {
+	a, b := web.NewMessageChannel()
+	defer a.Close()
+	defer b.Close()
+	ctx := context.Background()
+
+	b.Handle(func(_m web.Message) (interface{}, error) {
+		var n int
+		if err := _m.Decode(&n); err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errors.New("negative")
+		}
+		return n * 2, nil
+	})
+
+	// requests do not show up as plain messages, and replies are matched by ID
+	done := make(chan error, 2)
+	for _, n := range []int{21, 50} {
+		n := n
+		go func() {
+			m, err := a.Request(ctx, n)
+			if err != nil {
+				done <- err
+				return
+			}
+			var got int
+			if err = m.Decode(&got); err == nil && got != n*2 {
+				err = errors.New("wrong reply")
+			}
+			done <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var rerr *web.RemoteError
+	if _, err := a.Request(ctx, -1); !errors.As(err, &rerr) || rerr.Message != "negative" {
+		t.Errorf("Request(-1) = %v, want a RemoteError", err)
+	}
+	if _, err := b.Request(ctx, 1); !errors.As(err, &rerr) || rerr.Message != web.GOWEB_ERROR_NO_HANDLER.Error() {
+		t.Errorf("Request without a handler = %v", err)
+	}
+
+	b.Handle(func(web.Message) (interface{}, error) {
+		time.Sleep(200 * time.Millisecond)
+		return nil, nil
+	})
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.Request(tctx, 1); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Request past its deadline = %v", err)
+	}
+
+	select {
+	case m := <-b.Messages():
+		t.Errorf("request leaked into Messages: %v", m.Data)
+	default:
+	}
+}
+
+func TestPortTransfer(t *testing.T) {
+	a, b := web.NewMessageChannel()
+	defer a.Close()
+	defer b.Close()
+
+	buf := web.NewArrayBuffer([]byte{1, 2, 3})
+	if err := a.Post(web.Transfer{Data: buf, List: []js.Value{buf}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(t, b).Bytes(); !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Errorf("transferred bytes = %v", got)
+	}
+	if n := buf.Get("byteLength").Int(); n != 0 {
+		t.Errorf("sender buffer still holds %d bytes after the transfer", n)
+	}
+
+	// a transferred port arrives raw; the receiver decides to wrap it
+	mc := js.Global().Get("MessageChannel").New()
+	if err := a.Post("line", mc.Get("port2")); err != nil {
+		t.Fatal(err)
+	}
+	m := next(t, b)
+	if len(m.Ports) != 1 || m.Port(1) != nil {
+		t.Fatalf("message carried %d ports", len(m.Ports))
+	}
+	line := m.Port(0)
+	defer line.Close()
+	mine := web.NewPort(mc.Get("port1"))
+	defer mine.Close()
+
+	if err := mine.Post("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(t, line).Data.String(); got != "hello" {
+		t.Errorf("over the transferred port got %q", got)
+	}
+}